@@ -38,6 +38,10 @@ func main() {
 			zwooc.CreateCompoundCommand(),
 			zwooc.CreateGraphCommand(),
 			zwooc.CreateInitCommand(),
+			zwooc.CreateCacheCommand(),
+			zwooc.CreateConfigCommand(),
+			zwooc.CreateValidateCommand(),
+			zwooc.CreateSchemaCommand(),
 			{
 				// TODO: when cliv3 comes out this is no longer needed
 				Name:  "complete-bash",
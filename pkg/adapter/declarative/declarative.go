@@ -0,0 +1,75 @@
+package declarative
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/zwoo-hq/zwooc/pkg/adapter/shared"
+	"github.com/zwoo-hq/zwooc/pkg/model"
+	"github.com/zwoo-hq/zwooc/pkg/tasks"
+	"golang.org/x/exp/maps"
+)
+
+// declarativeAdapter creates the commands of an adapter defined in the config.
+type declarativeAdapter struct {
+	definition model.AdapterDefinition
+}
+
+var _ model.Adapter = (*declarativeAdapter)(nil)
+
+func NewAdapter(definition model.AdapterDefinition) model.Adapter {
+	return &declarativeAdapter{definition}
+}
+
+func (a *declarativeAdapter) CreateTask(c model.ProfileWrapper, extraArgs []string) tasks.Task {
+	joined := a.definition.ArgStyle == model.ArgStyleEquals
+	command := strings.Fields(a.definition.Command)
+
+	cmd := exec.Command(command[0], command[1:]...)
+	cmd.Dir = c.GetDirectory()
+	// the env of the profile takes precedence over the default env of the adapter
+	cmd.Env = append(os.Environ(), a.definition.Env...)
+	cmd.Env = append(cmd.Env, c.GetEnv()...)
+
+	cmd.Args = append(cmd.Args, strings.Fields(a.definition.Modes[c.GetMode()])...)
+	cmd.Args = append(cmd.Args, a.optionFlags(c.GetOptions(), joined)...)
+	cmd.Args = append(cmd.Args, shared.FormatArgs(c.GetProfileOptions().Args, joined)...)
+	cmd.Args = append(cmd.Args, extraArgs...)
+	return tasks.NewCommandTask(c.GetName(), cmd)
+}
+
+// optionFlags converts the options of the profile, which are mapped to a flag by the adapter, into flags.
+func (a *declarativeAdapter) optionFlags(options map[string]interface{}, joined bool) []string {
+	flags := []string{}
+	// sort the options in order to create the same command every time
+	keys := maps.Keys(a.definition.Options)
+	slices.Sort(keys)
+	for _, key := range keys {
+		flag := a.definition.Options[key]
+		switch value := options[key].(type) {
+		case bool:
+			if value {
+				flags = append(flags, shared.Flag(flag))
+			}
+		case []interface{}:
+			for _, item := range value {
+				flags = append(flags, shared.FormatFlag(flag, formatValue(item), joined)...)
+			}
+		case nil:
+		default:
+			flags = append(flags, shared.FormatFlag(flag, formatValue(value), joined)...)
+		}
+	}
+	return flags
+}
+
+func formatValue(value interface{}) string {
+	if number, ok := value.(float64); ok {
+		return strconv.FormatFloat(number, 'f', -1, 64)
+	}
+	return fmt.Sprint(value)
+}
@@ -0,0 +1,146 @@
+package plugin
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/zwoo-hq/zwooc/pkg/model"
+	"github.com/zwoo-hq/zwooc/pkg/tasks"
+)
+
+// ProtocolVersion is the version of the json protocol between zwooc and adapter plugins, it's increased
+// with every incompatible change.
+const ProtocolVersion = 1
+
+// timeout is the maximum time a plugin may take to create the command of a task.
+const timeout = 30 * time.Second
+
+// A Request is written as json to the stdin of an adapter plugin.
+type Request struct {
+	Version int `json:"version"`
+	// Adapter is the name of the adapter (without the zwooc-adapter- prefix)
+	Adapter   string                 `json:"adapter"`
+	Name      string                 `json:"name"`
+	Mode      string                 `json:"mode"`
+	Directory string                 `json:"directory"`
+	Options   map[string]interface{} `json:"options"`
+	// Env contains the environment variables of the profile (NAME=value)
+	Env       []string `json:"env"`
+	ExtraArgs []string `json:"extraArgs"`
+}
+
+// A Response is read as json from the stdout of an adapter plugin.
+type Response struct {
+	// Args contains the executable followed by its arguments
+	Args []string `json:"args"`
+	// Env contains additional environment variables (NAME=value), the env of the profile takes precedence
+	Env []string `json:"env"`
+	// Dir is the directory the command is executed in, relative paths are resolved from the directory of the profile
+	Dir string `json:"dir"`
+	Hints
+}
+
+// Hints are options of the task suggested by the plugin, the options of the profile take precedence.
+type Hints struct {
+	ReadyWhen model.ReadyOptions `json:"readyWhen"`
+}
+
+// Find returns the absolute path of the plugin executable of an adapter. The plugin directory of the config
+// is searched before the PATH, it returns an empty string if there is no plugin for the adapter.
+func Find(adapter, configDir string) string {
+	executable := model.AdapterPluginPrefix + adapter
+	if path, err := exec.LookPath(filepath.Join(configDir, filepath.FromSlash(model.AdapterPluginDirectory), executable)); err == nil {
+		if absolute, err := filepath.Abs(path); err == nil {
+			return absolute
+		}
+	}
+	if path, err := exec.LookPath(executable); err == nil {
+		return path
+	}
+	return ""
+}
+
+// Adapter creates tasks by running an adapter plugin.
+type Adapter struct {
+	name string
+	path string
+}
+
+func NewAdapter(name, path string) *Adapter {
+	return &Adapter{
+		name: name,
+		path: path,
+	}
+}
+
+// Resolve runs the plugin in the directory of the profile in order to create the command of a task.
+func (a *Adapter) Resolve(c model.ProfileWrapper, extraArgs []string) (Response, error) {
+	request, err := json.Marshal(Request{
+		Version:   ProtocolVersion,
+		Adapter:   a.name,
+		Name:      c.GetName(),
+		Mode:      c.GetMode(),
+		Directory: c.GetDirectory(),
+		Options:   c.GetOptions(),
+		Env:       c.GetEnv(),
+		ExtraArgs: extraArgs,
+	})
+	if err != nil {
+		return Response{}, err
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+	stdout, stderr := bytes.Buffer{}, bytes.Buffer{}
+	cmd := exec.CommandContext(ctx, a.path)
+	cmd.Dir = c.GetDirectory()
+	cmd.Stdin = bytes.NewReader(request)
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+			return Response{}, fmt.Errorf("adapter plugin '%s' timed out after %s", a.path, timeout)
+		}
+		if message := strings.TrimSpace(stderr.String()); message != "" {
+			return Response{}, fmt.Errorf("adapter plugin '%s' failed: %w: %s", a.path, err, message)
+		}
+		return Response{}, fmt.Errorf("adapter plugin '%s' failed: %w", a.path, err)
+	}
+
+	response := Response{}
+	if err := json.Unmarshal(stdout.Bytes(), &response); err != nil {
+		return Response{}, fmt.Errorf("invalid response of adapter plugin '%s': %w", a.path, err)
+	}
+	if len(response.Args) == 0 || response.Args[0] == "" {
+		return Response{}, fmt.Errorf("invalid response of adapter plugin '%s': missing args", a.path)
+	}
+	return response, nil
+}
+
+// CreateTask creates the task of a profile from the response of the plugin, it returns the hints of the plugin as well.
+func (a *Adapter) CreateTask(c model.ProfileWrapper, extraArgs []string) (tasks.Task, Hints, error) {
+	response, err := a.Resolve(c, extraArgs)
+	if err != nil {
+		return nil, Hints{}, err
+	}
+
+	cmd := exec.Command(response.Args[0], response.Args[1:]...)
+	cmd.Dir = c.GetDirectory()
+	if response.Dir != "" {
+		cmd.Dir = response.Dir
+		if !filepath.IsAbs(response.Dir) {
+			cmd.Dir = filepath.Join(c.GetDirectory(), response.Dir)
+		}
+	}
+	cmd.Env = append(os.Environ(), response.Env...)
+	cmd.Env = append(cmd.Env, c.GetEnv()...)
+	return tasks.NewCommandTask(c.GetName(), cmd), response.Hints, nil
+}
@@ -3,9 +3,11 @@ package shared
 import (
 	"os"
 	"os/exec"
+	"slices"
 	"strings"
 
 	"github.com/zwoo-hq/zwooc/pkg/model"
+	"golang.org/x/exp/maps"
 )
 
 func CreateBaseCommand(executable string, c model.ProfileWrapper, extraArgs []string) (*exec.Cmd, []string) {
@@ -14,16 +16,37 @@ func CreateBaseCommand(executable string, c model.ProfileWrapper, extraArgs []st
 	cmd.Dir = c.GetDirectory()
 
 	profileOptions := c.GetProfileOptions()
-	cmd.Env = append(cmd.Env, profileOptions.Env...)
-
-	additionalArgs := []string{}
-	for k, v := range profileOptions.Args {
-		if strings.HasPrefix(k, "-") {
-			additionalArgs = append(additionalArgs, k, v)
-		} else {
-			additionalArgs = append(additionalArgs, "--"+k, v)
-		}
-	}
+	cmd.Env = append(cmd.Env, c.GetEnv()...)
 
+	additionalArgs := FormatArgs(profileOptions.Args, false)
 	return cmd, append(additionalArgs, extraArgs...)
 }
+
+// FormatArgs converts args into flags, keys are prefixed with -- unless they start with a hyphen. The value
+// is passed as a separate argument or joined with = (--key=value). The flags are sorted by their key in order
+// to create the same command every time.
+func FormatArgs(args map[string]string, joined bool) []string {
+	formatted := []string{}
+	keys := maps.Keys(args)
+	slices.Sort(keys)
+	for _, key := range keys {
+		formatted = append(formatted, FormatFlag(key, args[key], joined)...)
+	}
+	return formatted
+}
+
+// FormatFlag formats a flag with its value as one or two arguments.
+func FormatFlag(name, value string, joined bool) []string {
+	if joined {
+		return []string{Flag(name) + "=" + value}
+	}
+	return []string{Flag(name), value}
+}
+
+// Flag prefixes the name of a flag with -- unless it already starts with a hyphen.
+func Flag(name string) string {
+	if strings.HasPrefix(name, "-") {
+		return name
+	}
+	return "--" + name
+}
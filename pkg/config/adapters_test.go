@@ -0,0 +1,78 @@
+package config
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/zwoo-hq/zwooc/pkg/model"
+	"github.com/zwoo-hq/zwooc/pkg/tasks"
+)
+
+func TestLoad_DeclarativeAdapters(t *testing.T) {
+	dir := writeConfigFiles(t, map[string]string{
+		model.ConfigFile: `{
+			"$adapters": {
+				"go": {
+					"command": "go",
+					"modes": {"build": "build ./...", "run": "run ."},
+					"options": {"race": "-race", "tags": "-tags", "ldflags": "ldflags"},
+					"env": ["CGO_ENABLED=0"]
+				},
+				"pytest": {
+					"command": "python -m pytest",
+					"modes": {"run": ""},
+					"options": {"markers": "-m", "workers": "numprocesses"},
+					"argStyle": "equals"
+				}
+			},
+			"api": {
+				"$adapter": "go",
+				"api": {"race": true, "tags": ["integration", "e2e"], "build": {"race": false, "env": ["CGO_ENABLED=1"]}, "run": {"args": {"addr": ":8080"}}},
+				"api-release": {"base": "api", "ldflags": "-s -w", "build": {}}
+			},
+			"tests": {
+				"$adapter": "pytest",
+				"tests": {"run": {"markers": "slow", "workers": 4, "args": {"-k": "api"}}}
+			}
+		}`,
+	})
+	conf, err := Load(filepath.Join(dir, model.ConfigFile))
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+
+	tests := []struct {
+		profile string
+		mode    string
+		want    string
+	}{
+		{"api", model.ModeBuild, "CGO_ENABLED=0 CGO_ENABLED=1 go build ./... -tags integration -tags e2e"},
+		{"api", model.ModeRun, "CGO_ENABLED=0 go run . -race -tags integration -tags e2e --addr :8080"},
+		{"api-release", model.ModeBuild, "CGO_ENABLED=0 CGO_ENABLED=1 go build ./... --ldflags -s -w -tags integration -tags e2e"},
+		{"tests", model.ModeRun, "python -m pytest -m=slow --numprocesses=4 -k=api"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.profile+":"+tt.mode, func(t *testing.T) {
+			profile, err := conf.resolveInterpolatedProfile(tt.profile, tt.mode)
+			if err != nil {
+				t.Fatalf("resolveInterpolatedProfile() error = %v", err)
+			}
+			task, err := profile.GetTask([]string{})
+			if err != nil {
+				t.Fatalf("GetTask() error = %v", err)
+			}
+			if got := tasks.CommandLine(task); !strings.HasSuffix(got, tt.want) {
+				t.Errorf("GetTask() = %s, want %s", got, tt.want)
+			}
+		})
+	}
+
+	profile, err := conf.resolveInterpolatedProfile("api", model.ModeWatch)
+	if err != nil {
+		t.Fatalf("resolveInterpolatedProfile() error = %v", err)
+	}
+	if _, err := profile.GetTask([]string{}); err == nil || err.Error() != "adapter 'go' does not support the watch mode" {
+		t.Errorf("GetTask() error = %v", err)
+	}
+}
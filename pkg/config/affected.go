@@ -0,0 +1,88 @@
+package config
+
+import (
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/zwoo-hq/zwooc/pkg/helper"
+	"github.com/zwoo-hq/zwooc/pkg/tasks"
+)
+
+// ChangedFiles returns the files changed in the repository of the config since the merge base with the base ref.
+func (c Config) ChangedFiles(base string) ([]string, error) {
+	return helper.ChangedFiles(c.baseDir, base)
+}
+
+// AffectedDirectories maps the changed files to the directories ($dir) of the projects containing them.
+// Files outside of all projects affect the directory of the config, which global fragments are executed in.
+func (c Config) AffectedDirectories(files []string) map[string]bool {
+	directories := []string{realPath(c.baseDir)}
+	for _, profile := range c.profiles {
+		directories = append(directories, realPath(profile.directory))
+	}
+
+	affected := map[string]bool{}
+	for _, file := range files {
+		file = realPath(file)
+		owner := ""
+		for _, directory := range directories {
+			// the innermost project owns the file, since projects may be nested
+			if isInside(file, directory) && len(directory) > len(owner) {
+				owner = directory
+			}
+		}
+		if owner != "" {
+			affected[owner] = true
+		}
+	}
+	return affected
+}
+
+// FilterAffected removes all nodes from the collection that neither belong to a project affected by the
+// changed files nor depend on a node that does. Unaffected dependencies ($pre subtrees) of the remaining
+// nodes are removed as well, while $post, $onError and $finally nodes of remaining nodes are kept.
+func (c Config) FilterAffected(collection tasks.Collection, files []string) tasks.Collection {
+	affected := c.AffectedDirectories(files)
+	isAffected := func(node *tasks.TaskTreeNode) bool {
+		return node.Directory != "" && affected[realPath(node.Directory)]
+	}
+
+	filtered := tasks.NewCollection()
+	for _, node := range collection {
+		if node.DependsOn(isAffected) {
+			pruneUnaffected(node, isAffected)
+			filtered = append(filtered, node)
+		}
+	}
+	return filtered
+}
+
+// pruneUnaffected removes all $pre subtrees of the tree whose nodes are neither affected nor depend on an affected node.
+func pruneUnaffected(node *tasks.TaskTreeNode, isAffected func(node *tasks.TaskTreeNode) bool) {
+	node.Pre = slices.DeleteFunc(node.Pre, func(pre *tasks.TaskTreeNode) bool {
+		return !pre.DependsOn(isAffected)
+	})
+	for _, child := range node.Children() {
+		pruneUnaffected(child, isAffected)
+	}
+}
+
+// realPath resolves symlinks of an absolute path, so that it can be compared with the paths reported by git.
+// Deleted files are resolved via their closest existing parent directory.
+func realPath(path string) string {
+	path, _ = filepath.Abs(path)
+	if resolved, err := filepath.EvalSymlinks(path); err == nil {
+		return resolved
+	}
+	parent := filepath.Dir(path)
+	if parent == path {
+		return path
+	}
+	return filepath.Join(realPath(parent), filepath.Base(path))
+}
+
+func isInside(file, directory string) bool {
+	relative, err := filepath.Rel(directory, file)
+	return err == nil && relative != ".." && !strings.HasPrefix(relative, ".."+string(filepath.Separator))
+}
@@ -0,0 +1,79 @@
+package config
+
+import (
+	"path/filepath"
+	"slices"
+	"testing"
+
+	"github.com/zwoo-hq/zwooc/pkg/model"
+)
+
+func TestConfig_FilterAffected(t *testing.T) {
+	dir := t.TempDir()
+	conf, err := New(dir, map[string]interface{}{
+		"shared": map[string]interface{}{
+			model.KeyAdapter: model.AdapterCustom,
+			"lib":            map[string]interface{}{"build": "echo lib"},
+		},
+		"backend": map[string]interface{}{
+			model.KeyAdapter:   model.AdapterCustom,
+			model.KeyDirectory: "src/backend",
+			"api":              map[string]interface{}{"build": "echo api"},
+		},
+		"frontend": map[string]interface{}{
+			model.KeyAdapter:   model.AdapterCustom,
+			model.KeyDirectory: "src/frontend",
+			"web": map[string]interface{}{
+				"build": map[string]interface{}{
+					"command":    "echo web",
+					model.KeyPre: map[string]interface{}{"profiles": map[string]interface{}{"lib": "build"}},
+				},
+			},
+		},
+	})
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+
+	tests := []struct {
+		name     string
+		files    []string
+		expected []string
+	}{
+		{"no changes", []string{}, []string{}},
+		{"backend changed", []string{"src/backend/main.go"}, []string{"api/build"}},
+		{"frontend changed", []string{"src/frontend/index.ts"}, []string{"web/build"}},
+		{"dependency changed", []string{"shared/index.ts"}, []string{"lib/build", "web/build"}},
+		{"files outside of projects", []string{"README.md"}, []string{}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			files := []string{}
+			for _, file := range tt.files {
+				files = append(files, filepath.Join(dir, file))
+			}
+
+			got := []string{}
+			for _, profile := range []string{"lib", "api", "web"} {
+				nodes, _ := conf.LoadProfile(profile, model.ModeBuild, NewContext(LoadOptions{}))
+				for _, node := range conf.FilterAffected(nodes, files) {
+					got = append(got, node.Name)
+				}
+			}
+			if !slices.Equal(got, tt.expected) {
+				t.Errorf("FilterAffected() = %v, want %v", got, tt.expected)
+			}
+		})
+	}
+
+	t.Run("prunes unaffected dependencies", func(t *testing.T) {
+		for file, expected := range map[string]int{"src/frontend/index.ts": 0, "shared/index.ts": 1} {
+			nodes, _ := conf.LoadProfile("web", model.ModeBuild, NewContext(LoadOptions{}))
+			filtered := conf.FilterAffected(nodes, []string{filepath.Join(dir, file)})
+			if len(filtered) != 1 || len(filtered[0].Pre) != expected {
+				t.Errorf("expected %d $pre nodes of web/build when %s changed, got %v", expected, file, filtered)
+			}
+		}
+	})
+}
@@ -0,0 +1,69 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/zwoo-hq/zwooc/pkg/helper"
+	"github.com/zwoo-hq/zwooc/pkg/model"
+	"github.com/zwoo-hq/zwooc/pkg/tasks"
+)
+
+// CacheDir returns the directory zwooc stores its cache in.
+func (c Config) CacheDir() string {
+	return filepath.Join(c.baseDir, model.CacheDirectory)
+}
+
+// RecordsDir returns the directory the fingerprints of the last successful runs are stored in.
+// Records describe the state of the local checkout, thus they are never shared like the artifact cache.
+func (c Config) RecordsDir() string {
+	return filepath.Join(c.CacheDir(), "records")
+}
+
+// ArtifactCache returns the cache storing the outputs of successful runs, it's nil if the cache is disabled.
+func (c Config) ArtifactCache() *tasks.ArtifactCache {
+	return c.artifacts
+}
+
+// loadArtifactCache creates the artifact cache from the $cache options. The directory defaults to the cache
+// directory of the config and can be overridden by ZWOOC_CACHE_DIR, relative directories are resolved from the config.
+func (c Config) loadArtifactCache() (*tasks.ArtifactCache, error) {
+	options := model.CacheOptions{}
+	if raw, ok := c.raw[model.KeyCache]; ok {
+		rawOptions, ok := raw.(map[string]interface{})
+		if !ok {
+			return nil, fmt.Errorf("invalid %s: expected an object", model.KeyCache)
+		}
+		options = helper.MapToStruct(rawOptions, options)
+	}
+	if options.Disabled {
+		return nil, nil
+	}
+
+	cache := &tasks.ArtifactCache{
+		Dir:     filepath.Join(c.CacheDir(), "artifacts"),
+		MaxSize: tasks.DefaultArtifactCacheSize,
+	}
+	if options.Dir != "" {
+		cache.Dir = c.resolvePath(options.Dir)
+	}
+	if dir := os.Getenv(model.EnvCacheDir); dir != "" {
+		cache.Dir = c.resolvePath(dir)
+	}
+	if options.MaxSize != "" {
+		size, err := helper.ParseSize(options.MaxSize)
+		if err != nil {
+			return nil, fmt.Errorf("invalid %s.maxSize: %w", model.KeyCache, err)
+		}
+		cache.MaxSize = size
+	}
+	return cache, nil
+}
+
+func (c Config) resolvePath(path string) string {
+	if filepath.IsAbs(path) {
+		return path
+	}
+	return filepath.Join(c.baseDir, path)
+}
@@ -8,7 +8,9 @@ import (
 type Compound struct {
 	name      string
 	directory string
-	raw       map[string]interface{}
+	// source is the config file the compound is defined in
+	source string
+	raw    map[string]interface{}
 }
 
 func (c Compound) Name() string {
@@ -20,6 +22,7 @@ func (c Compound) ResolveConfig() ResolvedCompound {
 	return ResolvedCompound{
 		Name:             c.name,
 		Directory:        c.directory,
+		Source:           c.source,
 		Profiles:         options.Profiles,
 		IncludeFragments: options.IncludeFragments,
 		Options:          c.raw,
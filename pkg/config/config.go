@@ -3,17 +3,30 @@ package config
 import (
 	"github.com/zwoo-hq/zwooc/pkg/adapter/custom"
 	"github.com/zwoo-hq/zwooc/pkg/adapter/dotnet"
+	"github.com/zwoo-hq/zwooc/pkg/adapter/plugin"
 	"github.com/zwoo-hq/zwooc/pkg/adapter/tauri"
 	"github.com/zwoo-hq/zwooc/pkg/adapter/vite"
+	"github.com/zwoo-hq/zwooc/pkg/helper"
 	"github.com/zwoo-hq/zwooc/pkg/model"
+	"github.com/zwoo-hq/zwooc/pkg/tasks"
 )
 
 type Config struct {
 	baseDir   string
 	raw       map[string]interface{}
+	order     helper.KeyOrder
 	profiles  []Profile
 	fragments []Fragment
 	compounds []Compound
+	artifacts *tasks.ArtifactCache
+	revision  func() (string, error)
+	// environment is the name of the selected environment
+	environment string
+	// sources contains the file (relative to the config) each project, global fragment, compound and
+	// environment entry is defined in, indexed by its json pointer
+	sources map[string]string
+	// locations contains the location each value of the config is defined at, indexed by its json pointer
+	locations map[string]location
 }
 
 func New(dir string, content map[string]interface{}) (Config, error) {
@@ -37,6 +50,8 @@ type (
 	Hookable interface {
 		ResolvePreHook() ResolvedHook
 		ResolvePostHook() ResolvedHook
+		ResolveOnErrorHook() ResolvedHook
+		ResolveFinallyHook() ResolvedHook
 	}
 )
 
@@ -56,6 +71,22 @@ func IsReservedKey(key string) bool {
 		return true
 	case model.KeyPost:
 		return true
+	case model.KeyOnError:
+		return true
+	case model.KeyFinally:
+		return true
+	case model.KeyCache:
+		return true
+	case model.KeyEnvFile:
+		return true
+	case model.KeyEnvironments:
+		return true
+	case model.KeyInclude:
+		return true
+	case model.KeyOptions:
+		return true
+	case model.KeyAdapters:
+		return true
 	case "$schema":
 		return true
 	}
@@ -95,3 +126,36 @@ func GetAdapter(adapter string) model.Adapter {
 	}
 	return nil
 }
+
+// adapterDefinition returns the definition of an adapter defined in the config via $adapters, it's nil for
+// built in and unknown adapters.
+func (c Config) adapterDefinition(adapter string) *model.AdapterDefinition {
+	if GetAdapter(adapter) != nil {
+		return nil
+	}
+	definitions, _ := c.raw[model.KeyAdapters].(map[string]interface{})
+	definition, ok := definitions[adapter].(map[string]interface{})
+	if !ok {
+		return nil
+	}
+	result := helper.MapToStruct(definition, model.AdapterDefinition{})
+	return &result
+}
+
+// adapterPlugin returns the executable of the plugin providing an adapter, it's empty for built in adapters,
+// adapters defined in the config and adapters without a plugin.
+func (c Config) adapterPlugin(adapter string) string {
+	if adapter == "" || GetAdapter(adapter) != nil {
+		return ""
+	}
+	if definitions, ok := c.raw[model.KeyAdapters].(map[string]interface{}); ok && definitions[adapter] != nil {
+		return ""
+	}
+	return plugin.Find(adapter, c.baseDir)
+}
+
+// GetOptions returns the $options of the config, which are the defaults for options of the cli.
+func (c Config) GetOptions() model.WorkspaceOptions {
+	options, _ := c.raw[model.KeyOptions].(map[string]interface{})
+	return helper.MapToStruct(options, model.WorkspaceOptions{})
+}
@@ -19,11 +19,19 @@ func TestIsReserved(t *testing.T) {
 		{"$fragment should be true", model.KeyFragment, true},
 		{"$post should be true", model.KeyPost, true},
 		{"$pre should be true", model.KeyPre, true},
+		{"$onError should be true", model.KeyOnError, true},
+		{"$finally should be true", model.KeyFinally, true},
 		{"default should be false", "default", false},
 		{"foo should be false", "foo", false},
 		{"x$default should be false", "x$default", false},
 		{"$schema should be true", "$schema", true},
 		{"$dir should be true", "$dir", true},
+		{"$cache should be true", model.KeyCache, true},
+		{"$envFile should be true", model.KeyEnvFile, true},
+		{"$environments should be true", model.KeyEnvironments, true},
+		{"$include should be true", model.KeyInclude, true},
+		{"$options should be true", model.KeyOptions, true},
+		{"$adapters should be true", model.KeyAdapters, true},
 	}
 	for _, tt := range tests {
 		t.Run("", func(t *testing.T) {
@@ -83,3 +91,12 @@ func TestGetAdapter(t *testing.T) {
 		})
 	}
 }
+
+func TestRecordName(t *testing.T) {
+	if recordName("web/build") == recordName("web_build") {
+		t.Errorf("Expected distinct record names for 'web/build' and 'web_build', got %q", recordName("web/build"))
+	}
+	if recordName("web/build") != recordName("web/build") {
+		t.Errorf("Expected a stable record name for 'web/build'")
+	}
+}
@@ -1,7 +1,13 @@
 package config
 
 import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
 	"slices"
+
+	"github.com/zwoo-hq/zwooc/pkg/tasks"
 )
 
 type (
@@ -9,11 +15,19 @@ type (
 		SkipHooks bool
 		Exclude   []string
 		ExtraArgs []string
+		// Tty runs all tasks attached to a pseudo-terminal
+		Tty bool
+		// Isolated loads a node of its own for each dependent instead of sharing identical dependencies,
+		// the legacy runner can't execute shared nodes
+		Isolated bool
 	}
 
 	RunnerOptions struct {
 		MaxConcurrency  int
 		UseLegacyRunner bool
+		Loose           bool
+		// Force ignores up to date checks
+		Force bool
 	}
 
 	loadingContext struct {
@@ -21,6 +35,23 @@ type (
 		excludedKeys []string
 		extraArgs    []string
 		callStack    []string
+		tty          bool
+		// depth is the amount of nested hooks of the entity being loaded
+		depth int
+		// loaded contains the nodes of all entities loaded with this context by their identity,
+		// it's shared between all derived contexts
+		loaded map[string]tasks.Collection
+	}
+
+	// A dependency describes how a loaded entity is used by the node depending on it.
+	dependency struct {
+		// reaction indicates that the entity is executed after the dependent ($post, $onError or $finally),
+		// reactions are always executed and thus never shared
+		reaction bool
+		// allowFailure tolerates failures of the entity
+		allowFailure bool
+		// longRunning marks the entity as long running
+		longRunning bool
 	}
 )
 
@@ -30,6 +61,11 @@ func NewContext(opts LoadOptions) loadingContext {
 		excludedKeys: opts.Exclude,
 		extraArgs:    opts.ExtraArgs,
 		callStack:    []string{},
+		tty:          opts.Tty,
+	}
+
+	if !opts.Isolated {
+		ctx.loaded = map[string]tasks.Collection{}
 	}
 
 	if ctx.excludedKeys == nil {
@@ -68,3 +104,37 @@ func (c loadingContext) excludes(target string) bool {
 		return s == target
 	})
 }
+
+// share returns the nodes of an identical entity if one was already loaded, so that identical entities are
+// created once and all dependents hold edges to the same nodes. Entities are identical if they have the same
+// kind and name and their nodes execute the same commands in the same directories the same way.
+// Reactions and entities loaded with an isolated context are not shared.
+func (c loadingContext) share(kind, name string, nodes tasks.Collection, use dependency) tasks.Collection {
+	if use.reaction || c.loaded == nil {
+		return nodes
+	}
+
+	hash := sha256.New()
+	for _, node := range nodes {
+		writeNodeIdentity(hash, node)
+	}
+	key := fmt.Sprintf("%s:%s@%s", kind, name, hex.EncodeToString(hash.Sum(nil))[:12])
+
+	if loaded, ok := c.loaded[key]; ok {
+		return loaded
+	}
+	nodes[0].Key = key
+	c.loaded[key] = nodes
+	return nodes
+}
+
+func writeNodeIdentity(w io.Writer, node *tasks.TaskTreeNode) {
+	fmt.Fprintf(w, "%s\x00%s\x00%s\x00%t\x00%t\n", node.Name, node.Directory, tasks.CommandLine(node.Main), node.IsLongRunning, node.AllowFailure)
+	for i, section := range [][]*tasks.TaskTreeNode{node.Pre, node.Post, node.OnError, node.Finally} {
+		for _, child := range section {
+			fmt.Fprintf(w, "%d{", i)
+			writeNodeIdentity(w, child)
+			fmt.Fprint(w, "}")
+		}
+	}
+}
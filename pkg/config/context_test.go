@@ -3,6 +3,8 @@ package config
 import (
 	"reflect"
 	"testing"
+
+	"github.com/zwoo-hq/zwooc/pkg/tasks"
 )
 
 func TestNewContext(t *testing.T) {
@@ -19,6 +21,7 @@ func TestNewContext(t *testing.T) {
 				excludedKeys: []string{},
 				extraArgs:    []string{},
 				callStack:    []string{},
+				loaded:       map[string]tasks.Collection{},
 			},
 		},
 		{
@@ -27,12 +30,15 @@ func TestNewContext(t *testing.T) {
 				SkipHooks: true,
 				Exclude:   []string{"key1", "key2"},
 				ExtraArgs: []string{"arg1", "arg2"},
+				Tty:       true,
 			},
 			want: loadingContext{
 				skipHooks:    true,
 				excludedKeys: []string{"key1", "key2"},
 				extraArgs:    []string{"arg1", "arg2"},
 				callStack:    []string{},
+				tty:          true,
+				loaded:       map[string]tasks.Collection{},
 			},
 		},
 	}
@@ -0,0 +1,70 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/zwoo-hq/zwooc/pkg/helper"
+	"github.com/zwoo-hq/zwooc/pkg/model"
+	"github.com/zwoo-hq/zwooc/pkg/tasks"
+)
+
+// loadEnv loads the environment variables of a task from its env files (relative to directory) and its inline env.
+// Variables of later env files override the ones of earlier files, inline variables override all env files.
+func loadEnv(directory string, files []string, inline []string) ([]tasks.EnvVariable, error) {
+	variables := []tasks.EnvVariable{}
+	set := func(variable tasks.EnvVariable) {
+		index := slices.IndexFunc(variables, func(v tasks.EnvVariable) bool {
+			return v.Name == variable.Name
+		})
+		if index >= 0 {
+			variables[index] = variable
+		} else {
+			variables = append(variables, variable)
+		}
+	}
+
+	// env files may reference variables of previous files or the environment of zwooc
+	defined := map[string]string{}
+	lookup := func(name string) (string, bool) {
+		if value, ok := defined[name]; ok {
+			return value, true
+		}
+		return os.LookupEnv(name)
+	}
+
+	for _, file := range files {
+		path := file
+		if !filepath.IsAbs(path) {
+			path = filepath.Join(directory, file)
+		}
+		content, err := os.ReadFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("cannot read env file '%s': %w", file, err)
+		}
+		parsed, err := helper.ParseDotenv(string(content), lookup)
+		if err != nil {
+			return nil, fmt.Errorf("invalid env file '%s': %w", file, err)
+		}
+		for _, variable := range parsed {
+			defined[variable.Name] = variable.Value
+			set(tasks.EnvVariable{Name: variable.Name, Value: variable.Value, Source: file})
+		}
+	}
+
+	for _, variable := range inline {
+		name, value, _ := strings.Cut(variable, "=")
+		set(tasks.EnvVariable{Name: name, Value: value, Source: tasks.SourceInlineEnv})
+	}
+	return variables, nil
+}
+
+// projectEnvFiles returns the env files configured for all profiles and fragments of a project.
+func projectEnvFiles(project map[string]interface{}) []string {
+	files := model.StringList{}
+	files.DecodeValue(project[model.KeyEnvFile])
+	return files
+}
@@ -0,0 +1,118 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/zwoo-hq/zwooc/pkg/model"
+	"github.com/zwoo-hq/zwooc/pkg/tasks"
+)
+
+func TestLoadEnv(t *testing.T) {
+	t.Setenv("ZWOOC_TEST_HOST", "localhost")
+	dir := t.TempDir()
+	os.WriteFile(filepath.Join(dir, ".env"), []byte("A=env\nB=env\nURL=http://${ZWOOC_TEST_HOST}\n"), 0644)
+	os.WriteFile(filepath.Join(dir, ".env.local"), []byte("export B=local\nC=$A-local\n"), 0644)
+	os.WriteFile(filepath.Join(dir, ".env.broken"), []byte("A\n"), 0644)
+
+	tests := []struct {
+		name    string
+		files   []string
+		inline  []string
+		want    []tasks.EnvVariable
+		wantErr string
+	}{
+		{"no env", nil, nil, []tasks.EnvVariable{}, ""},
+		{"inline env", nil, []string{"A=1", "B=x=y"}, []tasks.EnvVariable{
+			{Name: "A", Value: "1", Source: "env"},
+			{Name: "B", Value: "x=y", Source: "env"},
+		}, ""},
+		{"later files and inline env take precedence", []string{".env", filepath.Join(dir, ".env.local")}, []string{"C=inline"}, []tasks.EnvVariable{
+			{Name: "A", Value: "env", Source: ".env"},
+			{Name: "B", Value: "local", Source: filepath.Join(dir, ".env.local")},
+			{Name: "URL", Value: "http://localhost", Source: ".env"},
+			{Name: "C", Value: "inline", Source: "env"},
+		}, ""},
+		{"missing file", []string{".env.missing"}, nil, nil, "cannot read env file '.env.missing'"},
+		{"invalid file", []string{".env.broken"}, nil, nil, "invalid env file '.env.broken': line 1"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := loadEnv(dir, tt.files, tt.inline)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("loadEnv() error = %v, want %s", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("loadEnv() error = %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("loadEnv() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestConfig_LoadEnvFiles(t *testing.T) {
+	dir := t.TempDir()
+	os.MkdirAll(filepath.Join(dir, "web"), 0755)
+	os.WriteFile(filepath.Join(dir, "web", ".env.build"), []byte("MODE=project\nPROJECT=web\n"), 0644)
+	os.WriteFile(filepath.Join(dir, "web", ".env.dev"), []byte("MODE=profile\n"), 0644)
+
+	conf, err := New(dir, map[string]interface{}{
+		"web": map[string]interface{}{
+			model.KeyAdapter: model.AdapterCustom,
+			model.KeyEnvFile: ".env.${mode}",
+			"dev": map[string]interface{}{
+				"envFile": []interface{}{".env.dev"},
+				"build": map[string]interface{}{
+					"command":    "build",
+					model.KeyPre: map[string]interface{}{"command": "prepare", "env": []interface{}{"HOOK=1"}},
+				},
+			},
+			model.KeyFragment: map[string]interface{}{
+				"lint": map[string]interface{}{"$default": "lint", "env": []interface{}{"MODE=fragment"}},
+			},
+		},
+	})
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+
+	nodes, err := conf.LoadProfile("dev", "build", NewContext(LoadOptions{}))
+	if err != nil {
+		t.Fatalf("LoadProfile() error = %v", err)
+	}
+	wantProfile := []tasks.EnvVariable{
+		{Name: "MODE", Value: "profile", Source: ".env.dev"},
+		{Name: "PROJECT", Value: "web", Source: ".env.build"},
+	}
+	if !reflect.DeepEqual(nodes[0].Env, wantProfile) {
+		t.Errorf("expected the profile env %v, got %v", wantProfile, nodes[0].Env)
+	}
+	wantHook := []tasks.EnvVariable{
+		{Name: "MODE", Value: "project", Source: ".env.build"},
+		{Name: "PROJECT", Value: "web", Source: ".env.build"},
+		{Name: "HOOK", Value: "1", Source: "env"},
+	}
+	if !reflect.DeepEqual(nodes[0].Pre[0].Env, wantHook) {
+		t.Errorf("expected the hook env %v, got %v", wantHook, nodes[0].Pre[0].Env)
+	}
+	if command := tasks.CommandLine(nodes[0].Pre[0].Main); !strings.HasPrefix(command, "MODE=project PROJECT=web HOOK=1 sh -c") {
+		t.Errorf("expected the hook to be executed with its env, got '%s'", command)
+	}
+
+	fragment, err := conf.LoadFragment("lint:build:dev", NewContext(LoadOptions{}))
+	if err != nil {
+		t.Fatalf("LoadFragment() error = %v", err)
+	}
+	if len(fragment.Env) != 2 || fragment.Env[0].Value != "fragment" || fragment.Env[0].Source != "env" {
+		t.Errorf("expected the inline env of the fragment to take precedence, got %v", fragment.Env)
+	}
+}
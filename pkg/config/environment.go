@@ -0,0 +1,67 @@
+package config
+
+import (
+	"fmt"
+
+	"github.com/zwoo-hq/zwooc/pkg/helper"
+	"github.com/zwoo-hq/zwooc/pkg/model"
+)
+
+// GetEnvironments returns the names of all environments defined in the config.
+func (c Config) GetEnvironments() []string {
+	environments, _ := c.raw[model.KeyEnvironments].(map[string]interface{})
+	return c.order.Keys(helper.JsonPointer("", model.KeyEnvironments), environments)
+}
+
+// Environment returns the name of the selected environment or an empty string if none is selected.
+func (c Config) Environment() string {
+	return c.environment
+}
+
+// UseEnvironment selects an environment of the config. Its options are merged into the options of
+// the profiles it targets, before profiles are merged with their base profiles.
+func (c *Config) UseEnvironment(name string) error {
+	environments, _ := c.raw[model.KeyEnvironments].(map[string]interface{})
+	environment, ok := environments[name].(map[string]interface{})
+	if !ok {
+		return fmt.Errorf("environment '%s' not found", name)
+	}
+
+	for projectKey, value := range environment {
+		if _, ok := c.raw[projectKey].(map[string]interface{}); !ok || IsReservedKey(projectKey) {
+			return fmt.Errorf("environment '%s' references unknown project '%s'", name, projectKey)
+		}
+		if _, ok := value.(map[string]interface{}); !ok {
+			return fmt.Errorf("environment '%s' has invalid options for project '%s'", name, projectKey)
+		}
+	}
+
+	c.environment = name
+	for i, profile := range c.profiles {
+		definition := c.raw[profile.project].(map[string]interface{})
+		c.profiles[i].overlays = environmentOverlays(environment, definition, profile)
+	}
+	return nil
+}
+
+// environmentOverlays returns the options of an environment applying to a profile. Keys of a project
+// naming one of its profiles define the options of this profile, all other keys apply to every profile
+// of the project. Options of the profile are applied after the options of the project.
+func environmentOverlays(environment, definition map[string]interface{}, profile Profile) []map[string]interface{} {
+	project, ok := environment[profile.project].(map[string]interface{})
+	if !ok {
+		return nil
+	}
+
+	projectOptions := map[string]interface{}{}
+	for key, value := range project {
+		if _, isProfile := definition[key]; !isProfile || IsReservedKey(key) {
+			projectOptions[key] = value
+		}
+	}
+	overlays := []map[string]interface{}{projectOptions}
+	if profileOptions, ok := project[profile.name].(map[string]interface{}); ok {
+		overlays = append(overlays, profileOptions)
+	}
+	return overlays
+}
@@ -0,0 +1,127 @@
+package config
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/zwoo-hq/zwooc/pkg/model"
+)
+
+func newEnvironmentConfig(t *testing.T) Config {
+	conf, err := New("/root", map[string]interface{}{
+		"web": map[string]interface{}{
+			model.KeyAdapter: model.AdapterCustom,
+			"dev": map[string]interface{}{
+				"env":   []interface{}{"API_URL=http://localhost", "DEBUG=1"},
+				"args":  map[string]interface{}{"port": "8080"},
+				"build": map[string]interface{}{"command": "build"},
+				"run":   map[string]interface{}{"command": "serve"},
+			},
+			"preview": map[string]interface{}{
+				"base": "dev",
+				"run":  map[string]interface{}{"command": "preview"},
+			},
+		},
+		"api": map[string]interface{}{
+			model.KeyAdapter: model.AdapterCustom,
+			"api": map[string]interface{}{
+				"build": map[string]interface{}{"command": "build api"},
+			},
+		},
+		model.KeyEnvironments: map[string]interface{}{
+			"staging": map[string]interface{}{
+				"web": map[string]interface{}{
+					"env": []interface{}{"API_URL=https://staging.example.com"},
+					"dev": map[string]interface{}{
+						"args":  map[string]interface{}{"mode": "staging"},
+						"build": map[string]interface{}{"command": "build --staging"},
+					},
+				},
+			},
+			"broken": map[string]interface{}{
+				"docs": map[string]interface{}{},
+			},
+		},
+	})
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	return conf
+}
+
+func TestConfig_UseEnvironment(t *testing.T) {
+	conf := newEnvironmentConfig(t)
+	if err := conf.UseEnvironment("staging"); err != nil {
+		t.Fatalf("UseEnvironment() error = %v", err)
+	}
+	if conf.Environment() != "staging" {
+		t.Errorf("Environment() = %s, want staging", conf.Environment())
+	}
+
+	profile, err := conf.resolveInterpolatedProfile("dev", model.ModeBuild)
+	if err != nil {
+		t.Fatalf("resolveInterpolatedProfile() error = %v", err)
+	}
+	options := profile.GetProfileOptions()
+	if want := map[string]string{"port": "8080", "mode": "staging"}; !reflect.DeepEqual(options.Args, want) {
+		t.Errorf("expected args %v, got %v", want, options.Args)
+	}
+	if profile.Options["command"] != "build --staging" {
+		t.Errorf("expected the command of the environment, got %v", profile.Options["command"])
+	}
+	env := map[string]string{}
+	for _, variable := range profile.Env {
+		env[variable.Name] = variable.Value
+	}
+	if env["API_URL"] != "https://staging.example.com" || env["DEBUG"] != "1" {
+		t.Errorf("expected the env to be overridden, got %v", profile.Env)
+	}
+
+	// options of a mode only apply to this mode
+	profile, _ = conf.resolveInterpolatedProfile("dev", model.ModeRun)
+	if profile.Options["command"] != "serve" {
+		t.Errorf("expected the command of the profile, got %v", profile.Options["command"])
+	}
+
+	// profiles inherit the environment options of their base profile
+	profile, _ = conf.resolveInterpolatedProfile("preview", model.ModeRun)
+	if got := profile.GetProfileOptions().Args["mode"]; got != "staging" {
+		t.Errorf("expected the args of the base profile, got %v", profile.Options)
+	}
+
+	// other projects are not affected
+	for _, other := range conf.GetProfiles() {
+		if other.project == "api" && len(other.overlays) != 0 {
+			t.Errorf("expected no overlays for project api, got %v", other.overlays)
+		}
+	}
+}
+
+func TestConfig_UseEnvironmentKeepsConfig(t *testing.T) {
+	conf := newEnvironmentConfig(t)
+	if err := conf.UseEnvironment("staging"); err != nil {
+		t.Fatalf("UseEnvironment() error = %v", err)
+	}
+	for i := 0; i < 2; i++ {
+		profile, err := conf.resolveProfile("dev", model.ModeBuild)
+		if err != nil {
+			t.Fatalf("resolveProfile() error = %v", err)
+		}
+		if got := profile.GetProfileOptions().Env; len(got) != 3 {
+			t.Errorf("expected 3 env entries, got %v", got)
+		}
+	}
+}
+
+func TestConfig_UseEnvironmentErrors(t *testing.T) {
+	conf := newEnvironmentConfig(t)
+	if err := conf.UseEnvironment("prod"); err == nil || err.Error() != "environment 'prod' not found" {
+		t.Errorf("expected a not found error, got %v", err)
+	}
+	if err := conf.UseEnvironment("broken"); err == nil || err.Error() != "environment 'broken' references unknown project 'docs'" {
+		t.Errorf("expected an unknown project error, got %v", err)
+	}
+	if conf.Environment() != "" {
+		t.Errorf("expected no environment to be selected, got %s", conf.Environment())
+	}
+}
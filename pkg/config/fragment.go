@@ -8,8 +8,13 @@ import (
 
 type Fragment struct {
 	name      string
+	project   string
 	directory string
-	raw       interface{}
+	// source is the config file the fragment is defined in
+	source string
+	// envFiles are the env files of the project, global fragments have none
+	envFiles []string
+	raw      interface{}
 }
 
 func (f Fragment) Name() string {
@@ -25,7 +30,10 @@ func (f Fragment) ResolveConfig(mode string, callingProfile string) (ResolvedFra
 	if defaultCmd, ok := f.raw.(string); ok {
 		return ResolvedFragment{
 			Name:       f.name,
+			Project:    f.project,
 			Directory:  f.directory,
+			Source:     f.source,
+			EnvFiles:   f.envFiles,
 			Command:    defaultCmd,
 			Options:    map[string]interface{}{},
 			Mode:       mode,
@@ -45,7 +53,10 @@ func (f Fragment) ResolveConfig(mode string, callingProfile string) (ResolvedFra
 			if fragmentCommand, ok := options[index]; ok {
 				return ResolvedFragment{
 					Name:       f.name,
+					Project:    f.project,
 					Directory:  f.directory,
+					Source:     f.source,
+					EnvFiles:   f.envFiles,
 					Command:    fragmentCommand.(string),
 					Options:    options,
 					Mode:       mode,
@@ -55,5 +66,5 @@ func (f Fragment) ResolveConfig(mode string, callingProfile string) (ResolvedFra
 		}
 	}
 
-	return ResolvedFragment{}, fmt.Errorf("fragment '%s' does not contain a definition for mode '%s'", f.name, mode)
+	return ResolvedFragment{}, fmt.Errorf("fragment '%s'%s does not contain a definition for mode '%s'", f.name, inFile(f.source), mode)
 }
@@ -1,6 +1,8 @@
 package config
 
 import (
+	"slices"
+
 	"github.com/zwoo-hq/zwooc/pkg/helper"
 	"github.com/zwoo-hq/zwooc/pkg/model"
 )
@@ -17,12 +19,15 @@ func (h Hook) ResolveWithProfile(callingProfile ResolvedProfile, kind string) Re
 	options := h.getOptions()
 
 	return ResolvedHook{
-		Kind:      kind,
-		Command:   options.Command,
-		Fragments: options.Fragments,
-		Profiles:  options.Profiles,
-		Base:      helper.BuildName(callingProfile.Name, callingProfile.Mode),
-		Directory: callingProfile.Directory,
+		Kind:         kind,
+		Command:      options.Command,
+		Fragments:    options.Fragments,
+		Profiles:     options.Profiles,
+		AllowFailure: options.AllowFailure,
+		Env:          options.Env,
+		EnvFiles:     append(slices.Clone(callingProfile.EnvFiles), options.EnvFile...),
+		Base:         helper.BuildName(callingProfile.Name, callingProfile.Mode),
+		Directory:    callingProfile.Directory,
 	}
 }
 
@@ -30,12 +35,15 @@ func (h Hook) ResolveWithFragment(callingFragment ResolvedFragment, kind string)
 	options := h.getOptions()
 
 	return ResolvedHook{
-		Kind:      kind,
-		Command:   options.Command,
-		Fragments: options.Fragments,
-		Profiles:  options.Profiles,
-		Base:      callingFragment.Name,
-		Directory: callingFragment.Directory,
+		Kind:         kind,
+		Command:      options.Command,
+		Fragments:    options.Fragments,
+		Profiles:     options.Profiles,
+		AllowFailure: options.AllowFailure,
+		Env:          options.Env,
+		EnvFiles:     append(slices.Clone(callingFragment.EnvFiles), options.EnvFile...),
+		Base:         callingFragment.Name,
+		Directory:    callingFragment.Directory,
 	}
 }
 
@@ -43,11 +51,14 @@ func (h Hook) ResolveWithCompound(callingCompound ResolvedCompound, kind string)
 	options := h.getOptions()
 
 	return ResolvedHook{
-		Kind:      kind,
-		Command:   options.Command,
-		Fragments: options.Fragments,
-		Profiles:  options.Profiles,
-		Base:      callingCompound.Name,
-		Directory: callingCompound.Directory,
+		Kind:         kind,
+		Command:      options.Command,
+		Fragments:    options.Fragments,
+		Profiles:     options.Profiles,
+		AllowFailure: options.AllowFailure,
+		Env:          options.Env,
+		EnvFiles:     options.EnvFile,
+		Base:         callingCompound.Name,
+		Directory:    callingCompound.Directory,
 	}
 }
@@ -0,0 +1,195 @@
+package config
+
+import (
+	"fmt"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/zwoo-hq/zwooc/pkg/helper"
+	"github.com/zwoo-hq/zwooc/pkg/model"
+)
+
+type configFileKind int
+
+const (
+	rootFile configFileKind = iota
+	includedFile
+	projectFile
+)
+
+// discoveryExcludes are the directories which are not searched for project configs.
+var discoveryExcludes = []string{"!**/.?*/**", "!**/node_modules/**", "!**/bin/**", "!**/obj/**", "!**/target/**"}
+
+// loadFiles loads the root config together with all files it includes and all project configs below its directory.
+func (c *Config) loadFiles(path string) error {
+	c.raw = map[string]interface{}{}
+	c.order = helper.KeyOrder{}
+	c.sources = map[string]string{}
+	c.locations = map[string]location{}
+
+	visited := map[string]bool{}
+	if err := c.loadFile(path, rootFile, visited); err != nil {
+		return err
+	}
+
+	patterns := helper.MapTo(FileNames(model.ProjectConfigFile), func(name string) string { return "**/" + name })
+	discovered, err := helper.Glob(c.baseDir, append(patterns, discoveryExcludes...))
+	if err != nil {
+		return err
+	}
+	for _, file := range discovered {
+		if err := c.loadFile(filepath.Join(c.baseDir, filepath.FromSlash(file)), projectFile, visited); err != nil {
+			return err
+		}
+	}
+	return c.loadLocalFile()
+}
+
+// loadFile merges a config file and the files included by it into the config, every file is loaded once.
+func (c *Config) loadFile(path string, kind configFileKind, visited map[string]bool) error {
+	if visited[path] {
+		return nil
+	}
+	visited[path] = true
+
+	file, data, order, err := c.readConfigFile(path)
+	if err != nil {
+		return err
+	}
+	source := file.name
+	if kind == rootFile {
+		c.locations[""] = location{file: file}
+	}
+	if err := c.merge(data, order, file, filepath.Dir(path), kind); err != nil {
+		return err
+	}
+
+	patterns := model.StringList{}
+	patterns.DecodeValue(data[model.KeyInclude])
+	files, err := helper.Glob(filepath.Dir(path), patterns)
+	if err != nil {
+		return fmt.Errorf("invalid %s in '%s': %w", model.KeyInclude, source, err)
+	}
+	for _, pattern := range patterns {
+		if !strings.ContainsAny(pattern, "*?[!") && !slices.Contains(files, filepath.ToSlash(filepath.Clean(pattern))) {
+			return fmt.Errorf("file '%s' included by '%s' not found", pattern, source)
+		}
+	}
+	for _, file := range files {
+		if err := c.loadFile(filepath.Join(filepath.Dir(path), filepath.FromSlash(file)), includedFile, visited); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// merge adds the definitions of a config file to the config. Projects, global fragments, compounds, adapters
+// and the projects of environments may only be defined once across all files.
+func (c *Config) merge(data map[string]interface{}, order helper.KeyOrder, file *sourceFile, dir string, kind configFileKind) error {
+	source := file.name
+	for _, key := range order.Keys("", data) {
+		value := data[key]
+		switch key {
+		case "$schema", model.KeyInclude:
+			continue
+		case model.KeyCache, model.KeyOptions:
+			if kind != rootFile {
+				return fmt.Errorf("%s is only allowed in the root config, but found in '%s'", key, source)
+			}
+			c.raw[key] = value
+			c.recordLocations(helper.JsonPointer("", key), helper.JsonPointer("", key), value, file)
+		case model.KeyFragment, model.KeyCompound, model.KeyAdapters:
+			if err := c.mergeEntries(key, value, 1, file); err != nil {
+				return err
+			}
+		case model.KeyEnvironments:
+			if err := c.mergeEntries(key, value, 2, file); err != nil {
+				return err
+			}
+		default:
+			if IsReservedKey(key) {
+				c.raw[key] = value
+				c.recordLocations(helper.JsonPointer("", key), helper.JsonPointer("", key), value, file)
+				continue
+			}
+			project, ok := value.(map[string]interface{})
+			if !ok {
+				return fmt.Errorf("project '%s' in '%s' must be an object", key, source)
+			}
+			pointer := helper.JsonPointer("", key)
+			if defined, ok := c.sources[pointer]; ok {
+				return fmt.Errorf("project '%s' is defined in both '%s' and '%s'", key, defined, source)
+			}
+			if kind != rootFile {
+				// directories are relative to the file defining the project
+				if directory, ok := project[model.KeyDirectory].(string); ok {
+					project[model.KeyDirectory] = filepath.ToSlash(filepath.Join(c.relativePath(dir), directory))
+				} else if kind == projectFile {
+					project[model.KeyDirectory] = c.relativePath(dir)
+				}
+			}
+			c.raw[key] = project
+			c.sources[pointer] = source
+			c.recordLocations(pointer, pointer, project, file)
+		}
+	}
+
+	for pointer, keys := range order {
+		for _, key := range keys {
+			if !slices.Contains(c.order[pointer], key) {
+				c.order[pointer] = append(c.order[pointer], key)
+			}
+		}
+	}
+	return nil
+}
+
+// mergeEntries merges the entries of a top level section (like $fragments) into the config, entries
+// at the given depth must not be defined by multiple files.
+func (c *Config) mergeEntries(section string, value interface{}, depth int, file *sourceFile) error {
+	source := file.name
+	entries, ok := value.(map[string]interface{})
+	if !ok {
+		return fmt.Errorf("%s in '%s' must be an object", section, source)
+	}
+	target, ok := c.raw[section].(map[string]interface{})
+	if !ok {
+		target = map[string]interface{}{}
+		c.raw[section] = target
+		c.locations[helper.JsonPointer("", section)] = location{file: file, pointer: helper.JsonPointer("", section)}
+	}
+	return c.mergeEntriesAt(target, entries, helper.JsonPointer("", section), depth, file)
+}
+
+func (c *Config) mergeEntriesAt(target, entries map[string]interface{}, pointer string, depth int, file *sourceFile) error {
+	source := file.name
+	for key, entry := range entries {
+		entryPointer := helper.JsonPointer(pointer, key)
+		existing, exists := target[key]
+		if depth > 1 {
+			nested, isObject := entry.(map[string]interface{})
+			existingNested, existingIsObject := existing.(map[string]interface{})
+			if !isObject {
+				return fmt.Errorf("%s in '%s' must be an object", strings.TrimPrefix(entryPointer, "/"), source)
+			}
+			if !exists || !existingIsObject {
+				existingNested = map[string]interface{}{}
+				target[key] = existingNested
+				c.locations[entryPointer] = location{file: file, pointer: entryPointer}
+			}
+			if err := c.mergeEntriesAt(existingNested, nested, entryPointer, depth-1, file); err != nil {
+				return err
+			}
+			continue
+		}
+
+		if exists {
+			return fmt.Errorf("%s is defined in both '%s' and '%s'", strings.TrimPrefix(entryPointer, "/"), c.sources[entryPointer], source)
+		}
+		target[key] = entry
+		c.sources[entryPointer] = source
+		c.recordLocations(entryPointer, entryPointer, entry, file)
+	}
+	return nil
+}
@@ -0,0 +1,161 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/zwoo-hq/zwooc/pkg/helper"
+	"github.com/zwoo-hq/zwooc/pkg/model"
+)
+
+func writeConfigFiles(t *testing.T, files map[string]string) string {
+	dir := t.TempDir()
+	for name, content := range files {
+		path := filepath.Join(dir, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return dir
+}
+
+func TestLoad_IncludesFiles(t *testing.T) {
+	dir := writeConfigFiles(t, map[string]string{
+		model.ConfigFile: `{
+			"$include": ["configs/*.json", "shared.json"],
+			"web": {"$adapter": "custom", "dev": {"build": "echo web"}}
+		}`,
+		"shared.json": `{
+			"$fragments": {"lint": "echo lint"},
+			"$compounds": {"all": {"profiles": {"dev": "build", "api": "build"}}}
+		}`,
+		"configs/api.json": `{
+			"api": {"$adapter": "custom", "$dir": "../services/api", "api": {"build": "echo api"}},
+			"$environments": {"staging": {"api": {"env": ["URL=staging"]}}}
+		}`,
+		"configs/docs.json": `{
+			"$include": "nested/*.json",
+			"docs": {"$adapter": "custom", "docs": {"build": "echo docs"}}
+		}`,
+		"configs/nested/tools.json": `{
+			"tools": {"$adapter": "custom", "tools": {"build": "echo tools"}},
+			"$environments": {"staging": {"tools": {"env": ["URL=staging"]}}}
+		}`,
+		"apps/admin/" + model.ProjectConfigFile: `{
+			"admin": {"$adapter": "custom", "admin": {"build": "echo admin"}, "$fragments": {"check": "echo check"}}
+		}`,
+		"node_modules/pkg/" + model.ProjectConfigFile: `{
+			"ignored": {"$adapter": "custom", "ignored": {"build": "echo ignored"}}
+		}`,
+	})
+
+	conf, err := Load(filepath.Join(dir, model.ConfigFile))
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+
+	profiles := helper.MapTo(conf.GetProfiles(), Profile.Name)
+	if want := []string{"dev", "api", "docs", "tools", "admin"}; !reflect.DeepEqual(profiles, want) {
+		t.Errorf("GetProfiles() = %v, want %v", profiles, want)
+	}
+
+	directories := map[string]string{}
+	sources := map[string]string{}
+	for _, profile := range conf.GetProfiles() {
+		directories[profile.Name()], _ = filepath.Rel(dir, profile.directory)
+		sources[profile.Name()] = profile.source
+	}
+	wantDirectories := map[string]string{"dev": "web", "api": "services/api", "docs": "docs", "tools": "tools", "admin": "apps/admin"}
+	if !reflect.DeepEqual(directories, wantDirectories) {
+		t.Errorf("expected directories %v, got %v", wantDirectories, directories)
+	}
+	wantSources := map[string]string{"dev": model.ConfigFile, "api": "configs/api.json", "docs": "configs/docs.json", "tools": "configs/nested/tools.json", "admin": "apps/admin/" + model.ProjectConfigFile}
+	if !reflect.DeepEqual(sources, wantSources) {
+		t.Errorf("expected sources %v, got %v", wantSources, sources)
+	}
+
+	fragments := helper.MapTo(conf.GetFragments(), Fragment.Name)
+	if want := []string{"check", "lint"}; !reflect.DeepEqual(fragments, want) {
+		t.Errorf("GetFragments() = %v, want %v", fragments, want)
+	}
+	if _, err := conf.LoadCompound("all", NewContext(LoadOptions{})); err != nil {
+		t.Errorf("LoadCompound() error = %v", err)
+	}
+	if err := conf.UseEnvironment("staging"); err != nil {
+		t.Errorf("UseEnvironment() error = %v", err)
+	}
+}
+
+func TestLoad_IncludeErrors(t *testing.T) {
+	tests := []struct {
+		name  string
+		files map[string]string
+		err   string
+	}{
+		{"missing file", map[string]string{
+			model.ConfigFile: `{"$include": "missing.json"}`,
+		}, "file 'missing.json' included by 'zwooc.config.json' not found"},
+		{"duplicate project", map[string]string{
+			model.ConfigFile: `{"$include": "web.json", "web": {"$adapter": "custom"}}`,
+			"web.json":       `{"web": {"$adapter": "custom"}}`,
+		}, "project 'web' is defined in both 'zwooc.config.json' and 'web.json'"},
+		{"duplicate fragment", map[string]string{
+			model.ConfigFile: `{"$include": "*.json", "$fragments": {"lint": "echo root"}}`,
+			"lint.json":      `{"$fragments": {"lint": "echo included"}}`,
+		}, "$fragments/lint is defined in both 'zwooc.config.json' and 'lint.json'"},
+		{"duplicate environment", map[string]string{
+			model.ConfigFile: `{"$include": "env.json", "$environments": {"staging": {"web": {}}}}`,
+			"env.json":       `{"$environments": {"staging": {"web": {}}}}`,
+		}, "$environments/staging/web is defined in both 'zwooc.config.json' and 'env.json'"},
+		{"cache outside of the root", map[string]string{
+			model.ConfigFile: `{"$include": "cache.json"}`,
+			"cache.json":     `{"$cache": {"disabled": true}}`,
+		}, "$cache is only allowed in the root config, but found in 'cache.json'"},
+		{"invalid json", map[string]string{
+			model.ConfigFile:                 `{}`,
+			"web/" + model.ProjectConfigFile: `{"web": `,
+		}, "invalid config file 'web/zwooc.project.json' (line 1, column 9): unexpected end of JSON input"},
+		{"missing adapter", map[string]string{
+			model.ConfigFile:                 `{}`,
+			"web/" + model.ProjectConfigFile: `{"web": {"dev": {}}}`,
+		}, "web/zwooc.project.json:1:2: error: /web: missing $adapter"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := writeConfigFiles(t, tt.files)
+			_, err := Load(filepath.Join(dir, model.ConfigFile))
+			if err == nil || !strings.Contains(err.Error(), tt.err) {
+				t.Errorf("Load() error = %v, want %s", err, tt.err)
+			}
+		})
+	}
+}
+
+func TestLoad_ReportsSourceOfEntities(t *testing.T) {
+	dir := writeConfigFiles(t, map[string]string{
+		model.ConfigFile: `{}`,
+		"web/" + model.ProjectConfigFile: `{
+			"web": {"$adapter": "custom", "dev": {"build": "echo ${env:ZWOOC_TEST_MISSING}", "run": false}}
+		}`,
+	})
+	conf, err := Load(filepath.Join(dir, model.ConfigFile))
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+
+	_, err = conf.LoadProfile("dev", model.ModeBuild, NewContext(LoadOptions{}))
+	if want := "profile 'dev' (build) in 'web/zwooc.project.json': cannot resolve"; err == nil || !strings.Contains(err.Error(), want) {
+		t.Errorf("LoadProfile() error = %v, want %s", err, want)
+	}
+	_, err = conf.LoadProfile("dev", model.ModeRun, NewContext(LoadOptions{}))
+	if want := "profile 'dev' in 'web/zwooc.project.json' disabled mode 'run'"; err == nil || err.Error() != want {
+		t.Errorf("LoadProfile() error = %v, want %s", err, want)
+	}
+}
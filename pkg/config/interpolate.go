@@ -0,0 +1,377 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"reflect"
+	"slices"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/zwoo-hq/zwooc/pkg/helper"
+	"github.com/zwoo-hq/zwooc/pkg/model"
+)
+
+const (
+	VarMode    = "mode"
+	VarProfile = "profile"
+	VarProject = "project"
+	VarDir     = "dir"
+	VarRoot    = "root"
+	VarGitSha  = "git.sha"
+
+	varEnvPrefix     = "env:"
+	varProfilePrefix = "profile:"
+)
+
+// An interpolation replaces variables of the form ${name} in strings of the config. Unknown variables of the
+// shell form (like ${HOME} or ${NAME:-default}) are kept as they are, since they may be expanded by the shell
+// executing the command later on. A variable can be escaped via $${name}.
+type interpolation struct {
+	config Config
+	// values contains the values of the builtin variables available to the entity
+	values map[string]string
+	// references contains the profile options currently being resolved, in order to detect cycles
+	references []string
+}
+
+func (c Config) newInterpolation(values map[string]string, references []string) interpolation {
+	values[VarRoot] = c.baseDir
+	return interpolation{
+		config:     c,
+		values:     values,
+		references: references,
+	}
+}
+
+// value interpolates all strings of a value decoded from the config, maps and slices are copied.
+func (i interpolation) value(value interface{}) (interface{}, error) {
+	switch value := value.(type) {
+	case string:
+		return i.string(value)
+	case map[string]interface{}:
+		result := make(map[string]interface{}, len(value))
+		for key, item := range value {
+			interpolated, err := i.value(item)
+			if err != nil {
+				return nil, err
+			}
+			result[key] = interpolated
+		}
+		return result, nil
+	case []interface{}:
+		result := make([]interface{}, len(value))
+		for index, item := range value {
+			interpolated, err := i.value(item)
+			if err != nil {
+				return nil, err
+			}
+			result[index] = interpolated
+		}
+		return result, nil
+	}
+	return value, nil
+}
+
+func (i interpolation) options(options map[string]interface{}) (map[string]interface{}, error) {
+	interpolated, err := i.value(options)
+	if err != nil {
+		return nil, err
+	}
+	return interpolated.(map[string]interface{}), nil
+}
+
+func (i interpolation) strings(values []string) ([]string, error) {
+	result := make([]string, len(values))
+	for index, value := range values {
+		interpolated, err := i.string(value)
+		if err != nil {
+			return nil, err
+		}
+		result[index] = interpolated
+	}
+	return result, nil
+}
+
+func (i interpolation) string(value string) (string, error) {
+	result := strings.Builder{}
+	for {
+		start := strings.Index(value, "${")
+		if start < 0 {
+			break
+		}
+		end := strings.Index(value[start:], "}")
+		if end < 0 {
+			break
+		}
+		end += start
+
+		if start > 0 && value[start-1] == '$' {
+			// escaped variable
+			result.WriteString(value[:start-1])
+			result.WriteString(value[start : end+1])
+			value = value[end+1:]
+			continue
+		}
+
+		result.WriteString(value[:start])
+		variable := value[start : end+1]
+		resolved, known, err := i.resolve(value[start+2 : end])
+		if err != nil {
+			return "", fmt.Errorf("cannot resolve '%s': %w", variable, err)
+		}
+		if known {
+			result.WriteString(resolved)
+		} else {
+			result.WriteString(variable)
+		}
+		value = value[end+1:]
+	}
+	result.WriteString(value)
+	return result.String(), nil
+}
+
+// resolve returns the value of a variable and whether it's known to zwooc.
+func (i interpolation) resolve(name string) (string, bool, error) {
+	if env, ok := strings.CutPrefix(name, varEnvPrefix); ok {
+		env, fallback, hasFallback := strings.Cut(env, ":-")
+		if value := os.Getenv(env); value != "" || (!hasFallback && isEnvSet(env)) {
+			return value, true, nil
+		}
+		if hasFallback {
+			return fallback, true, nil
+		}
+		return "", true, fmt.Errorf("environment variable '%s' is not set", env)
+	}
+
+	if reference, ok := strings.CutPrefix(name, varProfilePrefix); ok {
+		value, err := i.profileOption(reference)
+		return value, true, err
+	}
+
+	switch name {
+	case VarGitSha:
+		sha, err := i.config.gitRevision()
+		return sha, true, err
+	case VarMode, VarProfile, VarProject, VarDir, VarRoot:
+		if value, ok := i.values[name]; ok {
+			return value, true, nil
+		}
+		return "", true, fmt.Errorf("variable '%s' is not available here", name)
+	}
+	return "", false, checkUnknownVariable(name)
+}
+
+var (
+	builtinVariables = []string{VarMode, VarProfile, VarProject, VarDir, VarRoot, VarGitSha}
+	variablePrefixes = []string{varEnvPrefix, varProfilePrefix}
+)
+
+// checkUnknownVariable returns an error if an unknown variable is most likely a typo of a variable of zwooc.
+// This is the case for names using the syntax of zwooc (`a.b` or `a:b`, which are invalid in the shell) and
+// for plain names close to a builtin variable. Other names are kept for the shell.
+func checkUnknownVariable(name string) error {
+	identifier := name[:len(name)-len(strings.TrimLeftFunc(name, isIdentifierRune))]
+	rest := name[len(identifier):]
+	if identifier == "" {
+		// e.g. ${#NAME} or ${!NAME}
+		return nil
+	}
+
+	if rest == "" {
+		if suggestion := helper.Suggest(name, builtinVariables); suggestion != "" && suggestion[0] == name[0] {
+			return unknownVariableError(name, suggestion)
+		}
+		return nil
+	}
+
+	if strings.HasPrefix(rest, ".") {
+		return unknownVariableError(name, helper.Suggest(name, builtinVariables))
+	}
+	if operand, ok := strings.CutPrefix(rest, ":"); ok && !isShellModifier(operand) {
+		suggestion := helper.Suggest(identifier+":", variablePrefixes)
+		if suggestion != "" {
+			suggestion += operand
+		}
+		return unknownVariableError(name, suggestion)
+	}
+	return nil
+}
+
+func unknownVariableError(name, suggestion string) error {
+	if suggestion != "" {
+		return fmt.Errorf("unknown variable '%s', did you mean '%s'? (use $${%s} to pass it to the shell)", name, suggestion, name)
+	}
+	return fmt.Errorf("unknown variable '%s' (use $${%s} to pass it to the shell)", name, name)
+}
+
+func isIdentifierRune(r rune) bool {
+	return r == '_' || unicode.IsLetter(r) || unicode.IsDigit(r)
+}
+
+// isShellModifier reports whether the operand following `${NAME:` is a parameter expansion of the shell,
+// like ${NAME:-default} or ${NAME:1:3}.
+func isShellModifier(operand string) bool {
+	if operand == "" {
+		return false
+	}
+	return strings.ContainsRune("-=?+ ", rune(operand[0])) || unicode.IsDigit(rune(operand[0]))
+}
+
+func isEnvSet(name string) bool {
+	_, ok := os.LookupEnv(name)
+	return ok
+}
+
+// profileOption resolves a reference to an option of another profile of the form `name.option.path` or
+// `name:mode.option.path`. Without a mode, the referenced profile is resolved in the current mode.
+func (i interpolation) profileOption(reference string) (string, error) {
+	target, path, ok := strings.Cut(reference, ".")
+	if !ok || path == "" {
+		return "", fmt.Errorf("expected a reference of the form 'profile:name.option'")
+	}
+	key, mode, hasMode := strings.Cut(target, ":")
+	if !hasMode {
+		mode = i.values[VarMode]
+	}
+	if mode == "" {
+		return "", fmt.Errorf("a run mode is required to reference profile '%s' (e.g. 'profile:%s:build.%s')", key, key, path)
+	}
+
+	id := helper.BuildName(key, mode) + "." + path
+	if slices.Contains(i.references, id) {
+		return "", fmt.Errorf("circular reference: %s -> %s", strings.Join(i.references, " -> "), id)
+	}
+
+	profile, err := i.config.resolveProfileWithBase(key, mode)
+	if err != nil {
+		return "", err
+	}
+
+	var value interface{} = profile.Options
+	for _, segment := range strings.Split(path, ".") {
+		switch current := value.(type) {
+		case map[string]interface{}:
+			value, ok = current[segment]
+		case []interface{}:
+			index, err := strconv.Atoi(segment)
+			ok = err == nil && index >= 0 && index < len(current)
+			if ok {
+				value = current[index]
+			}
+		default:
+			ok = false
+		}
+		if !ok {
+			return "", fmt.Errorf("profile '%s' has no option '%s' in mode '%s'", key, path, mode)
+		}
+	}
+
+	switch value := value.(type) {
+	case string:
+		// options of the referenced profile are interpolated with its own variables
+		return i.config.profileInterpolation(profile, append(slices.Clone(i.references), id)).string(value)
+	case float64:
+		return strconv.FormatFloat(value, 'f', -1, 64), nil
+	case bool:
+		return strconv.FormatBool(value), nil
+	}
+	return "", fmt.Errorf("option '%s' of profile '%s' is not a string, number or boolean", path, key)
+}
+
+// resolveInterpolatedProfile resolves a profile with all of its base profiles and interpolates its options.
+func (c Config) resolveInterpolatedProfile(key, mode string) (ResolvedProfile, error) {
+	config, err := c.resolveProfileWithBase(key, mode)
+	if err != nil {
+		return ResolvedProfile{}, err
+	}
+	interpolation := c.profileInterpolation(config, nil)
+	config.Options, err = interpolation.options(config.Options)
+	if err != nil {
+		return ResolvedProfile{}, fmt.Errorf("profile '%s' (%s)%s: %w", key, mode, inFile(config.Source), err)
+	}
+	config.EnvFiles, err = interpolation.strings(config.EnvFiles)
+	if err != nil {
+		return ResolvedProfile{}, fmt.Errorf("profile '%s' (%s)%s: %w", key, mode, inFile(config.Source), err)
+	}
+
+	options := config.GetProfileOptions()
+	config.Env, err = loadEnv(config.Directory, append(slices.Clone(config.EnvFiles), options.EnvFile...), options.Env)
+	if err != nil {
+		return ResolvedProfile{}, fmt.Errorf("profile '%s' (%s)%s: %w", key, mode, inFile(config.Source), err)
+	}
+	return config, nil
+}
+
+func (c Config) profileInterpolation(config ResolvedProfile, references []string) interpolation {
+	return c.newInterpolation(map[string]string{
+		VarMode:    config.Mode,
+		VarProfile: config.Name,
+		VarProject: config.Project,
+		VarDir:     config.Directory,
+	}, references)
+}
+
+// interpolateFragment interpolates the command and the options of a fragment. Commands of other modes
+// or profiles are not interpolated, since they may depend on variables which are not available here.
+func (c Config) interpolateFragment(fragment ResolvedFragment) (ResolvedFragment, error) {
+	interpolation := c.newInterpolation(map[string]string{
+		VarMode:    fragment.Mode,
+		VarProfile: fragment.ProfileKey,
+		VarProject: fragment.Project,
+		VarDir:     fragment.Directory,
+	}, nil)
+
+	var err error
+	fragment.Command, err = interpolation.string(fragment.Command)
+	if err != nil {
+		return ResolvedFragment{}, fmt.Errorf("fragment '%s'%s: %w", fragment.Name, inFile(fragment.Source), err)
+	}
+	fragment.EnvFiles, err = interpolation.strings(fragment.EnvFiles)
+	if err != nil {
+		return ResolvedFragment{}, fmt.Errorf("fragment '%s'%s: %w", fragment.Name, inFile(fragment.Source), err)
+	}
+	options := make(map[string]interface{}, len(fragment.Options))
+	for key, value := range fragment.Options {
+		if isFragmentOption(key) {
+			value, err = interpolation.value(value)
+			if err != nil {
+				return ResolvedFragment{}, fmt.Errorf("fragment '%s'%s: %w", fragment.Name, inFile(fragment.Source), err)
+			}
+		}
+		options[key] = value
+	}
+	fragment.Options = options
+	return fragment, nil
+}
+
+// interpolateCompound interpolates the options of a compound, which is not bound to a mode or profile.
+func (c Config) interpolateCompound(compound ResolvedCompound) (ResolvedCompound, error) {
+	interpolation := c.newInterpolation(map[string]string{
+		VarDir: compound.Directory,
+	}, nil)
+
+	var err error
+	compound.Options, err = interpolation.options(compound.Options)
+	if err != nil {
+		return ResolvedCompound{}, fmt.Errorf("compound '%s'%s: %w", compound.Name, inFile(compound.Source), err)
+	}
+	return compound, nil
+}
+
+// gitRevision returns the commit checked out in the directory of the config.
+func (c Config) gitRevision() (string, error) {
+	if c.revision != nil {
+		return c.revision()
+	}
+	return helper.GitRevision(c.baseDir)
+}
+
+// isFragmentOption reports whether the key of a fragment definition is an option applying to every
+// definition of the fragment, rather than the command of a mode or profile.
+func isFragmentOption(key string) bool {
+	return isHookKey(key) ||
+		helper.FindJsonField(reflect.ValueOf(model.TaskOptions{}), key).IsValid() ||
+		helper.FindJsonField(reflect.ValueOf(model.EnvOptions{}), key).IsValid()
+}
@@ -0,0 +1,159 @@
+package config
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/zwoo-hq/zwooc/pkg/model"
+)
+
+func TestInterpolation(t *testing.T) {
+	t.Setenv("ZWOOC_TEST_VALUE", "value")
+	t.Setenv("ZWOOC_TEST_EMPTY", "")
+
+	conf, err := New("/root", map[string]interface{}{
+		"web": map[string]interface{}{
+			model.KeyAdapter: model.AdapterCustom,
+			"dev": map[string]interface{}{
+				"args":  map[string]interface{}{"port": 5173.0, "host": "${profile}.local"},
+				"build": map[string]interface{}{"command": "build ${profile:dev.args.host}"},
+			},
+			"loop": map[string]interface{}{
+				"args":  map[string]interface{}{"a": "${profile:loop.args.b}", "b": "${profile:loop.args.a}"},
+				"build": "echo",
+			},
+		},
+	})
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	conf.revision = func() (string, error) {
+		return "abc123", nil
+	}
+
+	interpolation := conf.newInterpolation(map[string]string{
+		VarMode:    "build",
+		VarProfile: "app",
+		VarProject: "frontend",
+		VarDir:     "/root/frontend",
+	}, nil)
+
+	tests := []struct {
+		value   string
+		want    string
+		wantErr string
+	}{
+		{"plain text", "plain text", ""},
+		{"${mode} ${profile} ${project}", "build app frontend", ""},
+		{"${dir}/dist", "/root/frontend/dist", ""},
+		{"${root}", "/root", ""},
+		{"v-${git.sha}", "v-abc123", ""},
+		{"${env:ZWOOC_TEST_VALUE}", "value", ""},
+		{"${env:ZWOOC_TEST_EMPTY}", "", ""},
+		{"${env:ZWOOC_TEST_EMPTY:-fallback}", "fallback", ""},
+		{"${env:ZWOOC_TEST_MISSING:-}", "", ""},
+		{"${env:ZWOOC_TEST_MISSING}", "", "environment variable 'ZWOOC_TEST_MISSING' is not set"},
+		{"--port ${profile:dev.args.port}", "--port 5173", ""},
+		{"${profile:dev:build.args.host}", "dev.local", ""},
+		{"${profile:dev.command}", "build dev.local", ""},
+		{"${profile:dev.args.missing}", "", "profile 'dev' has no option 'args.missing' in mode 'build'"},
+		{"${profile:dev.args}", "", "is not a string, number or boolean"},
+		{"${profile:unknown.args}", "", "profile 'unknown' not found"},
+		{"${profile:loop.args.a}", "", "circular reference"},
+		{"echo ${HOME} $HOME", "echo ${HOME} $HOME", ""},
+		{"echo ${HOME:-/tmp} ${NAME:1:3} ${#NAME} ${port}", "echo ${HOME:-/tmp} ${NAME:1:3} ${#NAME} ${port}", ""},
+		{"${projct}", "", "unknown variable 'projct', did you mean 'project'?"},
+		{"${git.shaa}", "", "unknown variable 'git.shaa', did you mean 'git.sha'?"},
+		{"${envv:HOME}", "", "did you mean 'env:HOME'?"},
+		{"${unknown.option}", "", "unknown variable 'unknown.option'"},
+		{"echo $${projct}", "echo ${projct}", ""},
+		{"echo $${mode}", "echo ${mode}", ""},
+		{"unclosed ${mode", "unclosed ${mode", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.value, func(t *testing.T) {
+			got, err := interpolation.string(tt.value)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("interpolation.string(%s) error = %v, want %s", tt.value, err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("interpolation.string(%s) error = %v", tt.value, err)
+			}
+			if got != tt.want {
+				t.Errorf("interpolation.string(%s) = %s, want %s", tt.value, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestInterpolationUnavailableVariables(t *testing.T) {
+	conf, _ := New("/root", map[string]interface{}{})
+	conf.revision = func() (string, error) {
+		return "", errors.New("not a git repository")
+	}
+	interpolation := conf.newInterpolation(map[string]string{VarDir: "/root"}, nil)
+
+	for _, value := range []string{"${mode}", "${git.sha}", "${profile:dev.args.port}"} {
+		t.Run(value, func(t *testing.T) {
+			if _, err := interpolation.string(value); err == nil {
+				t.Errorf("interpolation.string(%s) expected an error", value)
+			}
+		})
+	}
+}
+
+func TestConfig_LoadInterpolatesOptions(t *testing.T) {
+	t.Setenv("ZWOOC_TEST_SIGNAL", "SIGINT")
+
+	conf, err := New("/root", map[string]interface{}{
+		"web": map[string]interface{}{
+			model.KeyAdapter: model.AdapterCustom,
+			"dev": map[string]interface{}{
+				"env":   []interface{}{"TARGET=${mode}"},
+				"build": map[string]interface{}{"command": "build ${project}"},
+			},
+			"broken": map[string]interface{}{
+				"build": map[string]interface{}{"command": "build ${env:ZWOOC_TEST_MISSING}"},
+			},
+			model.KeyFragment: map[string]interface{}{
+				"lint": map[string]interface{}{
+					"build":      "lint ${profile}",
+					"run":        "lint ${env:ZWOOC_TEST_MISSING}",
+					"stopSignal": "${env:ZWOOC_TEST_SIGNAL}",
+				},
+			},
+		},
+	})
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+
+	profile, err := conf.resolveInterpolatedProfile("dev", "build")
+	if err != nil {
+		t.Fatalf("resolveInterpolatedProfile() error = %v", err)
+	}
+	if got := profile.GetProfileOptions().Env; len(got) != 1 || got[0] != "TARGET=build" {
+		t.Errorf("expected the env to be interpolated, got %v", got)
+	}
+	if got := profile.Options["command"]; got != "build web" {
+		t.Errorf("expected the command to be interpolated, got %v", got)
+	}
+
+	fragment, err := conf.resolveFragment("lint", "build", "dev")
+	if err != nil {
+		t.Fatalf("resolveFragment() error = %v", err)
+	}
+	if fragment.Command != "lint dev" || fragment.GetTaskOptions().StopSignal != "SIGINT" {
+		t.Errorf("expected the fragment to be interpolated, got '%s' and '%s'", fragment.Command, fragment.GetTaskOptions().StopSignal)
+	}
+
+	_, err = conf.LoadProfile("broken", "build", NewContext(LoadOptions{}))
+	if err == nil || !strings.Contains(err.Error(), "profile 'broken' (build): cannot resolve '${env:ZWOOC_TEST_MISSING}'") {
+		t.Errorf("expected an interpolation error, got %v", err)
+	}
+}
@@ -28,8 +28,8 @@ func (c Config) LoadCompound(key string, ctx loadingContext) (tasks.Collection,
 	}
 	nodes = append(nodes, compoundNode)
 
-	for profileKey, mode := range compound.Profiles {
-		resolved, err := c.LoadProfile(profileKey, mode, ctx.withCaller(key))
+	for _, target := range compound.Profiles {
+		resolved, err := c.LoadProfile(target.Profile, target.Mode, ctx.withCaller(key))
 		if err != nil {
 			return []*tasks.TaskTreeNode{}, err
 		}
@@ -55,5 +55,5 @@ func (c Config) resolveCompound(key string) (ResolvedCompound, error) {
 		return ResolvedCompound{}, fmt.Errorf("compound '%s' not found", key)
 	}
 
-	return target.ResolveConfig(), nil
+	return c.interpolateCompound(target.ResolveConfig())
 }
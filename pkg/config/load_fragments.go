@@ -2,6 +2,7 @@ package config
 
 import (
 	"fmt"
+	"slices"
 	"strings"
 
 	"github.com/zwoo-hq/zwooc/pkg/helper"
@@ -29,6 +30,11 @@ func combineFragmentKey(key, mode, profile string) string {
 }
 
 func (c Config) LoadFragment(rawKey string, ctx loadingContext) (*tasks.TaskTreeNode, error) {
+	return c.loadFragment(rawKey, ctx, dependency{})
+}
+
+// loadFragment loads a fragment the way it's used by the node depending on it.
+func (c Config) loadFragment(rawKey string, ctx loadingContext, use dependency) (*tasks.TaskTreeNode, error) {
 	key, mode, profile := normalizeFragmentKey(rawKey)
 	if ctx.excludes(key) || ctx.excludes(rawKey) {
 		return nil, ErrTargetExcluded
@@ -43,14 +49,36 @@ func (c Config) LoadFragment(rawKey string, ctx loadingContext) (*tasks.TaskTree
 		}
 	}
 
-	node := tasks.NewTaskTree(fragment.Name, fragment.GetTask(ctx.getArgs()), false)
+	args := ctx.getArgs()
+	mainTask := fragment.GetTask(args)
+	if !tasks.IsEmptyTask(mainTask) {
+		mainTask, err = tasks.NewControlledTask(fragment.Mode, func(mode string) (tasks.Task, error) {
+			resolved, err := c.resolveFragment(fragment.Name, mode, fragment.ProfileKey)
+			if err != nil {
+				return nil, err
+			}
+			return resolved.GetTask(args), nil
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	node := tasks.NewTaskTree(fragment.Name, mainTask, use.longRunning)
+	node.Directory = fragment.Directory
+	node.Env = fragment.Env
+	taskOptions := fragment.GetTaskOptions()
+	node.AllowFailure = taskOptions.AllowFailure || use.allowFailure
+	if err := c.applyTaskOptions(node, taskOptions, fragment.Directory, ctx); err != nil {
+		return nil, err
+	}
 	if !ctx.skipHooks {
 		err = c.loadAllHooks(fragment, node, mode, profile, ctx.withCaller(fragment.Name))
 		if err != nil {
 			return nil, err
 		}
 	}
-	return node, nil
+	return ctx.share("fragment", helper.BuildName(fragment.Name, mode), tasks.NewCollection(node), use)[0], nil
 }
 
 func (c Config) resolveFragment(key, mode, profile string) (ResolvedFragment, error) {
@@ -61,5 +89,19 @@ func (c Config) resolveFragment(key, mode, profile string) (ResolvedFragment, er
 		return ResolvedFragment{}, fmt.Errorf("fragment '%s' not found", key)
 	}
 
-	return target.ResolveConfig(mode, profile)
+	fragment, err := target.ResolveConfig(mode, profile)
+	if err != nil {
+		return ResolvedFragment{}, err
+	}
+	fragment, err = c.interpolateFragment(fragment)
+	if err != nil {
+		return ResolvedFragment{}, err
+	}
+
+	envOptions := fragment.GetEnvOptions()
+	fragment.Env, err = loadEnv(fragment.Directory, append(slices.Clone(fragment.EnvFiles), envOptions.EnvFile...), envOptions.Env)
+	if err != nil {
+		return ResolvedFragment{}, fmt.Errorf("fragment '%s'%s: %w", fragment.Name, inFile(fragment.Source), err)
+	}
+	return fragment, nil
 }
@@ -4,6 +4,7 @@ import (
 	"fmt"
 
 	"github.com/zwoo-hq/zwooc/pkg/helper"
+	"github.com/zwoo-hq/zwooc/pkg/model"
 	"github.com/zwoo-hq/zwooc/pkg/tasks"
 )
 
@@ -18,22 +19,51 @@ func (c Config) loadAllHooks(caller Hookable, node *tasks.TaskTreeNode, mode, pr
 		return err
 	}
 
+	onErrorStage, err := c.loadHook(caller.ResolveOnErrorHook(), mode, profile, ctx)
+	if err != nil {
+		return err
+	}
+
+	finallyStage, err := c.loadHook(caller.ResolveFinallyHook(), mode, profile, ctx)
+	if err != nil {
+		return err
+	}
+
 	node.AddPreChild(preStage...)
 	node.AddPostChild(postStage...)
+	node.AddOnErrorChild(onErrorStage...)
+	node.AddFinallyChild(finallyStage...)
 	return nil
 }
 
-var depth = 0
+// maxHookDepth limits the nesting of hooks as a safety check for circular dependencies.
+const maxHookDepth = 1000
 
 func (c Config) loadHook(hook ResolvedHook, mode, profile string, ctx loadingContext) ([]*tasks.TaskTreeNode, error) {
-	depth += 1
-	if depth > 1000 {
-		return []*tasks.TaskTreeNode{}, fmt.Errorf("maximum depth of 1000 hooks reached (safety check for circular dependencies)")
+	ctx.depth++
+	if ctx.depth > maxHookDepth {
+		return []*tasks.TaskTreeNode{}, fmt.Errorf("maximum depth of %d hooks reached (safety check for circular dependencies)", maxHookDepth)
 	}
 
 	ctx = ctx.withCaller(hook.Kind)
-	taskList := []*tasks.TaskTreeNode{
-		tasks.NewTaskTree(helper.BuildName(hook.Base, hook.Kind), hook.GetTask(), false),
+	use := dependency{reaction: hook.Kind != model.KeyPre, allowFailure: hook.AllowFailure}
+	taskList := []*tasks.TaskTreeNode{}
+	// hooks without a command (like undefined hooks) don't get a node of their own
+	if hook.Command != "" {
+		hookTask := hook.GetTask()
+		if ctx.tty {
+			tasks.EnableTTY(hookTask)
+		}
+		hookNode := tasks.NewTaskTree(helper.BuildName(hook.Base, hook.Kind), hookTask, false)
+		hookNode.Directory = hook.Directory
+		hookNode.AllowFailure = hook.AllowFailure
+		env, err := loadEnv(hook.Directory, hook.EnvFiles, hook.Env)
+		if err != nil {
+			return nil, fmt.Errorf("hook '%s': %w", hookNode.Name, err)
+		}
+		hookNode.Env = env
+		tasks.SetEnv(hookTask, tasks.EnvList(env))
+		taskList = append(taskList, hookNode)
 	}
 
 	for _, fragment := range hook.Fragments {
@@ -43,21 +73,22 @@ func (c Config) loadHook(hook ResolvedHook, mode, profile string, ctx loadingCon
 		if ctx.hasCaller(fragment) {
 			return []*tasks.TaskTreeNode{}, CircularDependencyError{fragment, ctx.callStack}
 		}
-		fragmentConfig, err := c.LoadFragment(combineFragmentKey(fragment, mode, profile), ctx)
+		fragmentConfig, err := c.loadFragment(combineFragmentKey(fragment, mode, profile), ctx, use)
 		if err != nil {
 			return nil, err
 		}
 		taskList = append(taskList, fragmentConfig)
 	}
 
-	for profile, mode := range hook.Profiles {
-		if ctx.excludes(profile) || ctx.excludes(helper.BuildName(profile, mode)) {
+	for _, target := range hook.Profiles {
+		name := helper.BuildName(target.Profile, target.Mode)
+		if ctx.excludes(target.Profile) || ctx.excludes(name) {
 			continue
 		}
-		if ctx.hasCaller(helper.BuildName(profile, mode)) {
-			return []*tasks.TaskTreeNode{}, CircularDependencyError{helper.BuildName(profile, mode), ctx.callStack}
+		if ctx.hasCaller(name) {
+			return []*tasks.TaskTreeNode{}, CircularDependencyError{name, ctx.callStack}
 		}
-		profileConfig, err := c.LoadProfile(profile, mode, ctx)
+		profileConfig, err := c.loadProfile(target.Profile, target.Mode, ctx, use)
 		if err != nil {
 			return nil, err
 		}
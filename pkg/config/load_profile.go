@@ -10,6 +10,11 @@ import (
 )
 
 func (c Config) LoadProfile(key, mode string, ctx loadingContext) (tasks.Collection, error) {
+	return c.loadProfile(key, mode, ctx, dependency{})
+}
+
+// loadProfile loads a profile together with its included fragments the way it's used by the node depending on it.
+func (c Config) loadProfile(key, mode string, ctx loadingContext, use dependency) (tasks.Collection, error) {
 	if ctx.excludes(key) || ctx.excludes(helper.BuildName(key, mode)) {
 		return nil, ErrTargetExcluded
 	}
@@ -18,38 +23,48 @@ func (c Config) LoadProfile(key, mode string, ctx loadingContext) (tasks.Collect
 		key = model.KeyDefault
 	}
 
-	config, err := c.resolveProfile(key, mode)
+	config, err := c.resolveInterpolatedProfile(key, mode)
 	if err != nil {
 		return nil, err
 	}
 	opts := config.GetBaseOptions()
-	for opts.Base != "" {
-		// load aliased profile
-		newProfile, err := c.resolveProfile(opts.Base, mode)
+
+	name := helper.BuildName(key, mode)
+	args := ctx.getArgs()
+	// the task of the initial mode is created up front in order to apply the hints of adapter plugins
+	initialTask, hints, err := config.createTask(args)
+	if err != nil {
+		return nil, err
+	}
+	mainTask, err := tasks.NewControlledTask(mode, func(mode string) (tasks.Task, error) {
+		if initialTask != nil {
+			task := initialTask
+			initialTask = nil
+			return task, nil
+		}
+		// re-resolve the profile in order to support switching between modes
+		config, err := c.resolveInterpolatedProfile(key, mode)
 		if err != nil {
 			return nil, err
 		}
-		// merge profiles
-		config = ResolvedProfile{
-			Name:      config.Name,
-			Mode:      config.Mode,
-			Adapter:   newProfile.Adapter,
-			Directory: newProfile.Directory,
-			Options:   helper.MergeDeep(maps.Clone(newProfile.Options), config.Options),
-		}
-		opts = config.GetBaseOptions()
-		if newProfile.GetBaseOptions().Base == "" {
-			break
-		}
-	}
-
-	name := helper.BuildName(key, mode)
-	mainTask, err := config.GetTask(ctx.getArgs())
+		return config.GetTask(args)
+	})
 	ctx = ctx.withCaller(name)
 	if err != nil {
 		return nil, err
 	}
 	treeNode := tasks.NewTaskTree(name, mainTask, mode == model.ModeWatch || mode == model.ModeRun)
+	treeNode.Directory = config.Directory
+	treeNode.Env = config.Env
+	treeNode.AllowFailure = use.allowFailure
+	taskOptions := config.GetTaskOptions()
+	if taskOptions.ReadyWhen == (model.ReadyOptions{}) {
+		// a readiness probe of the profile takes precedence over the one hinted by the adapter
+		taskOptions.ReadyWhen = hints.ReadyWhen
+	}
+	if err := c.applyTaskOptions(treeNode, taskOptions, config.Directory, ctx); err != nil {
+		return nil, err
+	}
 
 	if !ctx.skipHooks {
 		err = c.loadAllHooks(config, treeNode, mode, key, ctx)
@@ -59,18 +74,51 @@ func (c Config) LoadProfile(key, mode string, ctx loadingContext) (tasks.Collect
 	}
 
 	allTasks := tasks.NewCollection(treeNode)
+	include := use
+	include.longRunning = mode == model.ModeWatch || mode == model.ModeRun
 	for _, fragmentKey := range opts.IncludeFragments {
-		fragment, err := c.LoadFragment(combineFragmentKey(fragmentKey, mode, key), ctx.withCaller("includes"))
+		fragment, err := c.loadFragment(combineFragmentKey(fragmentKey, mode, key), ctx.withCaller("includes"), include)
 		if err != nil {
 			return nil, err
 		}
-		if mode == model.ModeWatch || mode == model.ModeRun {
-			fragment.IsLongRunning = true
-		}
 		allTasks = append(allTasks, fragment)
 	}
 
-	return allTasks, nil
+	return ctx.share("profile", name, allTasks, use), nil
+}
+
+// resolveProfileWithBase resolves a profile and merges it with all of its base profiles.
+func (c Config) resolveProfileWithBase(key, mode string) (ResolvedProfile, error) {
+	config, err := c.resolveProfile(key, mode)
+	if err != nil {
+		return ResolvedProfile{}, err
+	}
+	opts := config.GetBaseOptions()
+	for opts.Base != "" {
+		// load aliased profile
+		newProfile, err := c.resolveProfile(opts.Base, mode)
+		if err != nil {
+			return ResolvedProfile{}, err
+		}
+		// merge profiles
+		config = ResolvedProfile{
+			Name:              config.Name,
+			Mode:              config.Mode,
+			Project:           newProfile.Project,
+			Adapter:           newProfile.Adapter,
+			AdapterDefinition: newProfile.AdapterDefinition,
+			Plugin:            newProfile.Plugin,
+			Directory:         newProfile.Directory,
+			Source:            config.Source,
+			EnvFiles:          newProfile.EnvFiles,
+			Options:           helper.MergeDeep(maps.Clone(newProfile.Options), config.Options),
+		}
+		opts = config.GetBaseOptions()
+		if newProfile.GetBaseOptions().Base == "" {
+			break
+		}
+	}
+	return config, nil
 }
 
 func (c Config) resolveProfile(key, mode string) (ResolvedProfile, error) {
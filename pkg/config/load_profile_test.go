@@ -0,0 +1,216 @@
+package config
+
+import (
+	"testing"
+
+	"github.com/zwoo-hq/zwooc/pkg/model"
+	"github.com/zwoo-hq/zwooc/pkg/tasks"
+)
+
+func TestConfig_LoadCompoundSharesDependencies(t *testing.T) {
+	withI18n := func(command string) map[string]interface{} {
+		return map[string]interface{}{
+			"command":    command,
+			model.KeyPre: map[string]interface{}{"fragments": []interface{}{"i18n"}},
+		}
+	}
+	conf, err := New(t.TempDir(), map[string]interface{}{
+		"frontend": map[string]interface{}{
+			model.KeyAdapter: model.AdapterCustom,
+			"web": map[string]interface{}{"build": map[string]interface{}{
+				"command":     "echo web",
+				model.KeyPre:  map[string]interface{}{"fragments": []interface{}{"i18n"}},
+				model.KeyPost: map[string]interface{}{"fragments": []interface{}{"i18n"}},
+			}},
+			"app": map[string]interface{}{"build": withI18n("echo app")},
+		},
+		model.KeyFragment: map[string]interface{}{
+			"i18n": "echo i18n",
+		},
+		model.KeyCompound: map[string]interface{}{
+			"all": map[string]interface{}{
+				"profiles": map[string]interface{}{"web": "build", "app": "build"},
+			},
+		},
+	})
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+
+	nodes, err := conf.LoadCompound("all", NewContext(LoadOptions{}))
+	if err != nil {
+		t.Fatalf("LoadCompound() error = %v", err)
+	}
+
+	web, app := findNode(nodes, "web/build"), findNode(nodes, "app/build")
+	if web == nil || app == nil || len(web.Pre) != 1 || len(app.Pre) != 1 {
+		t.Fatalf("expected i18n to be a dependency of both profiles")
+	}
+	shared := web.Pre[0]
+	if app.Pre[0] != shared {
+		t.Errorf("expected both dependents to hold an edge to the same node")
+	}
+	if shared.Key == "" || (shared.Parent != web && shared.Parent != app) {
+		t.Errorf("expected a keyed node whose first dependent is its parent, got key '%s' and parent %v", shared.Key, shared.Parent)
+	}
+	if len(web.Post) != 1 || web.Post[0] == shared || web.Post[0].Key != "" {
+		t.Errorf("expected the $post node to be a node of its own")
+	}
+
+	nodes, err = conf.LoadCompound("all", NewContext(LoadOptions{Isolated: true}))
+	if err != nil {
+		t.Fatalf("LoadCompound() error = %v", err)
+	}
+	if findNode(nodes, "web/build").Pre[0] == findNode(nodes, "app/build").Pre[0] {
+		t.Errorf("expected an isolated context to load a node for each dependent")
+	}
+}
+
+func findNode(nodes tasks.Collection, name string) *tasks.TaskTreeNode {
+	for _, node := range nodes {
+		if found := node.FindNode(name); found != nil {
+			return found
+		}
+	}
+	return nil
+}
+
+func TestConfig_LoadProfileHooks(t *testing.T) {
+	conf, err := New(t.TempDir(), map[string]interface{}{
+		"web": map[string]interface{}{
+			model.KeyAdapter: model.AdapterCustom,
+			"web": map[string]interface{}{"build": map[string]interface{}{
+				"command":        "echo web",
+				model.KeyPost:    map[string]interface{}{"command": "echo post"},
+				model.KeyOnError: map[string]interface{}{"fragments": []interface{}{"notify"}},
+			}},
+		},
+		model.KeyFragment: map[string]interface{}{
+			"notify": "echo notify",
+		},
+	})
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+
+	nodes, err := conf.LoadProfile("web", model.ModeBuild, NewContext(LoadOptions{}))
+	if err != nil {
+		t.Fatalf("LoadProfile() error = %v", err)
+	}
+	root := nodes[0]
+	if len(root.Pre) != 0 || len(root.Finally) != 0 {
+		t.Errorf("expected undefined hooks not to create nodes, got %d $pre and %d $finally nodes", len(root.Pre), len(root.Finally))
+	}
+	if len(root.Post) != 1 || root.Post[0].Name != "web/build/$post" {
+		t.Errorf("expected the $post command node, got %v", root.Post)
+	}
+	if len(root.OnError) != 1 || root.OnError[0].Name != "notify" {
+		t.Errorf("expected only the notify fragment in $onError, got %v", root.OnError)
+	}
+
+	ids := map[string]bool{}
+	root.Iterate(func(node *tasks.TaskTreeNode) {
+		if ids[node.NodeID()] {
+			t.Errorf("duplicate node id '%s'", node.NodeID())
+		}
+		ids[node.NodeID()] = true
+	})
+}
+
+func TestConfig_LoadProfileRestart(t *testing.T) {
+	restart := map[string]interface{}{"command": "echo web", "restart": map[string]interface{}{"policy": "always"}}
+	conf, err := New(t.TempDir(), map[string]interface{}{
+		"web": map[string]interface{}{
+			model.KeyAdapter: model.AdapterCustom,
+			"web":            map[string]interface{}{"run": restart, "build": restart},
+		},
+	})
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+
+	nodes, err := conf.LoadProfile("web", model.ModeRun, NewContext(LoadOptions{}))
+	if err != nil {
+		t.Fatalf("LoadProfile() error = %v", err)
+	}
+	if nodes[0].Restart == nil || nodes[0].Restart.Mode != tasks.RestartAlways {
+		t.Errorf("expected the restart policy of the long running task, got %v", nodes[0].Restart)
+	}
+
+	nodes, err = conf.LoadProfile("web", model.ModeBuild, NewContext(LoadOptions{}))
+	if err != nil {
+		t.Fatalf("LoadProfile() error = %v", err)
+	}
+	if nodes[0].Restart != nil {
+		t.Errorf("expected the restart policy of the build task to be ignored, got %v", nodes[0].Restart)
+	}
+}
+
+func TestConfig_LoadRestartOfShortRunningTasks(t *testing.T) {
+	conf, err := New(t.TempDir(), map[string]interface{}{
+		"web": map[string]interface{}{
+			model.KeyAdapter: model.AdapterCustom,
+			"web": map[string]interface{}{
+				"restart": map[string]interface{}{"policy": "always"},
+				"build":   "echo build",
+				"run":     "echo run",
+			},
+			model.KeyFragment: map[string]interface{}{
+				"lint": map[string]interface{}{"$default": "echo lint", "restart": map[string]interface{}{"policy": "on-failure"}},
+			},
+		},
+	})
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+
+	nodes, err := conf.LoadProfile("web", model.ModeBuild, NewContext(LoadOptions{}))
+	if err != nil {
+		t.Fatalf("LoadProfile() error = %v", err)
+	}
+	if nodes[0].Restart != nil {
+		t.Errorf("expected the profile level restart policy to be ignored in build mode, got %v", nodes[0].Restart)
+	}
+
+	fragment, err := conf.LoadFragment("lint", NewContext(LoadOptions{}))
+	if err != nil {
+		t.Fatalf("LoadFragment() error = %v", err)
+	}
+	if fragment.Restart != nil {
+		t.Errorf("expected the restart policy of the executed fragment to be ignored, got %v", fragment.Restart)
+	}
+
+	nodes, err = conf.LoadProfile("web", model.ModeRun, NewContext(LoadOptions{}))
+	if err != nil {
+		t.Fatalf("LoadProfile() error = %v", err)
+	}
+	if nodes[0].Restart == nil {
+		t.Errorf("expected the profile level restart policy to apply in run mode")
+	}
+}
+
+func TestConfig_LoadProfileRepeatedly(t *testing.T) {
+	hook := map[string]interface{}{"command": "echo hook"}
+	conf, err := New(t.TempDir(), map[string]interface{}{
+		"web": map[string]interface{}{
+			model.KeyAdapter: model.AdapterCustom,
+			"web": map[string]interface{}{
+				"build":          "echo build",
+				model.KeyPre:     hook,
+				model.KeyPost:    hook,
+				model.KeyOnError: hook,
+				model.KeyFinally: hook,
+			},
+		},
+	})
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+
+	// the hook depth is tracked per load, thus reloading the profile (e.g. in long interactive sessions) never fails
+	for i := 0; i < 500; i++ {
+		if _, err := conf.LoadProfile("web", model.ModeBuild, NewContext(LoadOptions{})); err != nil {
+			t.Fatalf("LoadProfile() #%d error = %v", i, err)
+		}
+	}
+}
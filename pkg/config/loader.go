@@ -1,36 +1,44 @@
 package config
 
 import (
-	"encoding/json"
 	"fmt"
-	"os"
 	"path/filepath"
+	"sync"
 
+	"github.com/zwoo-hq/zwooc/pkg/helper"
 	"github.com/zwoo-hq/zwooc/pkg/model"
 )
 
+// Load loads the config at path together with all files included via $include and all project configs
+// in the directories below. It fails with a ValidationError if the config is invalid.
 func Load(path string) (Config, error) {
-	content, err := os.ReadFile(path)
-	if err != nil {
-		return Config{}, err
+	c := Config{
+		baseDir: filepath.Dir(path),
 	}
-
-	data := map[string]interface{}{}
-	err = json.Unmarshal(content, &data)
-	if err != nil {
+	if err := c.loadFiles(path); err != nil {
 		return Config{}, err
 	}
 
-	c := Config{
-		baseDir: filepath.Dir(path),
-		raw:     data,
+	errors := []Diagnostic{}
+	for _, diagnostic := range c.validate() {
+		if diagnostic.Severity == SeverityError {
+			errors = append(errors, diagnostic)
+		}
+	}
+	if len(errors) > 0 {
+		return Config{}, &ValidationError{Diagnostics: errors}
 	}
-	err = c.init()
+
+	err := c.init()
 	return c, err
 }
 
 func (c *Config) init() error {
 	var err error
+	c.orderTargets(c.raw, "")
+	c.revision = sync.OnceValues(func() (string, error) {
+		return helper.GitRevision(c.baseDir)
+	})
 	c.profiles, err = c.loadProfiles()
 	if err != nil {
 		return err
@@ -42,6 +50,11 @@ func (c *Config) init() error {
 	}
 
 	c.compounds, err = c.loadCompounds()
+	if err != nil {
+		return err
+	}
+
+	c.artifacts, err = c.loadArtifactCache()
 	return err
 }
 
@@ -52,27 +65,35 @@ func (c Config) GetProfiles() []Profile {
 func (c Config) loadProfiles() ([]Profile, error) {
 	profiles := []Profile{}
 
-	for projectKey, projectValue := range c.raw {
+	for _, projectKey := range c.order.Keys("", c.raw) {
 		if !IsReservedKey(projectKey) {
-			project := projectValue.(map[string]interface{})
+			project := c.raw[projectKey].(map[string]interface{})
+			source := c.sources[helper.JsonPointer("", projectKey)]
 			var projectAdapter string
 			if adapter, ok := project[model.KeyAdapter]; ok {
 				projectAdapter = adapter.(string)
 			} else {
-				return []Profile{}, fmt.Errorf("project '%s' is missing adapter", projectKey)
+				return []Profile{}, fmt.Errorf("project '%s'%s is missing adapter", projectKey, inFile(source))
 			}
 			projectDirectory := projectKey
 			if directory, ok := project[model.KeyDirectory]; ok {
 				projectDirectory = directory.(string)
 			}
+			adapterDefinition := c.adapterDefinition(projectAdapter)
+			plugin := c.adapterPlugin(projectAdapter)
 
-			for profileKey, profileValue := range project {
+			for _, profileKey := range c.order.Keys(helper.JsonPointer("", projectKey), project) {
 				if !IsReservedKey(profileKey) {
 					newProfile := Profile{
-						name:      profileKey,
-						adapter:   projectAdapter,
-						directory: filepath.Join(c.baseDir, projectDirectory),
-						raw:       profileValue.(map[string]interface{}),
+						name:              profileKey,
+						project:           projectKey,
+						adapter:           projectAdapter,
+						adapterDefinition: adapterDefinition,
+						plugin:            plugin,
+						envFiles:          projectEnvFiles(project),
+						directory:         filepath.Join(c.baseDir, projectDirectory),
+						source:            source,
+						raw:               project[profileKey].(map[string]interface{}),
 					}
 					profiles = append(profiles, newProfile)
 				}
@@ -90,11 +111,12 @@ func (c Config) GetFragments() []Fragment {
 func (c Config) loadFragments() ([]Fragment, error) {
 	fragments := []Fragment{}
 
-	for projectKey, projectValue := range c.raw {
+	for _, projectKey := range c.order.Keys("", c.raw) {
 		if !IsReservedKey(projectKey) {
-			project := projectValue.(map[string]interface{})
-			if fragmentDefinitions, ok := project[model.KeyFragment]; ok {
-				for fragmentKey, fragmentValue := range fragmentDefinitions.(map[string]interface{}) {
+			project := c.raw[projectKey].(map[string]interface{})
+			if fragmentDefinitions, ok := project[model.KeyFragment].(map[string]interface{}); ok {
+				pointer := helper.JsonPointer(helper.JsonPointer("", projectKey), model.KeyFragment)
+				for _, fragmentKey := range c.order.Keys(pointer, fragmentDefinitions) {
 					projectDirectory := projectKey
 					if directory, ok := project[model.KeyDirectory]; ok {
 						projectDirectory = directory.(string)
@@ -102,8 +124,11 @@ func (c Config) loadFragments() ([]Fragment, error) {
 
 					newFragment := Fragment{
 						name:      fragmentKey,
+						project:   projectKey,
 						directory: filepath.Join(c.baseDir, projectDirectory),
-						raw:       fragmentValue,
+						source:    c.sources[helper.JsonPointer("", projectKey)],
+						envFiles:  projectEnvFiles(project),
+						raw:       fragmentDefinitions[fragmentKey],
 					}
 					fragments = append(fragments, newFragment)
 				}
@@ -111,12 +136,13 @@ func (c Config) loadFragments() ([]Fragment, error) {
 		}
 	}
 
-	if fragmentDefinitions, ok := c.raw[model.KeyFragment]; ok {
-		for fragmentKey, fragmentValue := range fragmentDefinitions.(map[string]interface{}) {
+	if fragmentDefinitions, ok := c.raw[model.KeyFragment].(map[string]interface{}); ok {
+		for _, fragmentKey := range c.order.Keys(helper.JsonPointer("", model.KeyFragment), fragmentDefinitions) {
 			newFragment := Fragment{
 				name:      fragmentKey,
 				directory: c.baseDir,
-				raw:       fragmentValue,
+				source:    c.sources[helper.JsonPointer(helper.JsonPointer("", model.KeyFragment), fragmentKey)],
+				raw:       fragmentDefinitions[fragmentKey],
 			}
 			fragments = append(fragments, newFragment)
 		}
@@ -132,15 +158,42 @@ func (c Config) GetCompounds() []Compound {
 func (c Config) loadCompounds() ([]Compound, error) {
 	compounds := []Compound{}
 
-	if compoundDefinitions, ok := c.raw[model.KeyCompound]; ok {
-		for compoundKey, compoundValue := range compoundDefinitions.(map[string]interface{}) {
+	if compoundDefinitions, ok := c.raw[model.KeyCompound].(map[string]interface{}); ok {
+		for _, compoundKey := range c.order.Keys(helper.JsonPointer("", model.KeyCompound), compoundDefinitions) {
 			newCompound := Compound{
 				name:      compoundKey,
 				directory: c.baseDir,
-				raw:       compoundValue.(map[string]interface{}),
+				source:    c.sources[helper.JsonPointer(helper.JsonPointer("", model.KeyCompound), compoundKey)],
+				raw:       compoundDefinitions[compoundKey].(map[string]interface{}),
 			}
 			compounds = append(compounds, newCompound)
 		}
 	}
 	return compounds, nil
 }
+
+// orderTargets converts the profiles of all hooks and compounds from the map notation into the list notation,
+// so that they keep the order of the config.
+func (c Config) orderTargets(object map[string]interface{}, pointer string) {
+	for key, value := range object {
+		child, ok := value.(map[string]interface{})
+		if !ok {
+			continue
+		}
+
+		childPointer := helper.JsonPointer(pointer, key)
+		isCompound := pointer == helper.JsonPointer("", model.KeyCompound)
+		if profiles, ok := child["profiles"].(map[string]interface{}); ok && (isCompound || isHookKey(key)) {
+			targets := []interface{}{}
+			for _, profile := range c.order.Keys(helper.JsonPointer(childPointer, "profiles"), profiles) {
+				targets = append(targets, map[string]interface{}{"profile": profile, "mode": profiles[profile]})
+			}
+			child["profiles"] = targets
+		}
+		c.orderTargets(child, childPointer)
+	}
+}
+
+func isHookKey(key string) bool {
+	return key == model.KeyPre || key == model.KeyPost || key == model.KeyOnError || key == model.KeyFinally
+}
@@ -0,0 +1,86 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/zwoo-hq/zwooc/pkg/helper"
+	"github.com/zwoo-hq/zwooc/pkg/tasks"
+)
+
+func TestLoad_PreservesOrder(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "zwooc.config.json")
+	content := `{
+		"web": {
+			"$adapter": "custom",
+			"prod": {"build": "echo prod", "$pre": {"profiles": {"lib": "build", "api": "build"}}},
+			"dev": {"build": "echo dev", "run": "echo dev"}
+		},
+		"lib": {"$adapter": "custom", "lib": {"build": "echo lib"}},
+		"api": {"$adapter": "custom", "api": {"build": "echo api"}},
+		"$fragments": {"zeta": "echo zeta", "alpha": "echo alpha"},
+		"$compounds": {
+			"twice": {"profiles": [{"profile": "dev", "mode": "run"}, {"profile": "dev", "mode": "build"}]},
+			"all": {"profiles": {"prod": "build", "dev": "build"}}
+		}
+	}`
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	conf, err := Load(path)
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+
+	profiles := helper.MapTo(conf.GetProfiles(), Profile.Name)
+	if want := []string{"prod", "dev", "lib", "api"}; !reflect.DeepEqual(profiles, want) {
+		t.Errorf("GetProfiles() = %v, want %v", profiles, want)
+	}
+	fragments := helper.MapTo(conf.GetFragments(), Fragment.Name)
+	if want := []string{"zeta", "alpha"}; !reflect.DeepEqual(fragments, want) {
+		t.Errorf("GetFragments() = %v, want %v", fragments, want)
+	}
+	compounds := helper.MapTo(conf.GetCompounds(), Compound.Name)
+	if want := []string{"twice", "all"}; !reflect.DeepEqual(compounds, want) {
+		t.Errorf("GetCompounds() = %v, want %v", compounds, want)
+	}
+
+	tests := []struct {
+		name string
+		load func() ([]string, error)
+		want []string
+	}{
+		{"compound map notation", func() ([]string, error) {
+			nodes, err := conf.LoadCompound("all", NewContext(LoadOptions{SkipHooks: true}))
+			return helper.MapTo(nodes[1:], nodeName), err
+		}, []string{"prod/build", "dev/build"}},
+		{"compound list notation", func() ([]string, error) {
+			nodes, err := conf.LoadCompound("twice", NewContext(LoadOptions{SkipHooks: true}))
+			return helper.MapTo(nodes[1:], nodeName), err
+		}, []string{"dev/run", "dev/build"}},
+		{"hook map notation", func() ([]string, error) {
+			nodes, err := conf.LoadProfile("prod", "build", NewContext(LoadOptions{}))
+			return helper.MapTo(nodes[0].Pre, nodeName), err
+		}, []string{"lib/build", "api/build"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := tt.load()
+			if err != nil {
+				t.Fatalf("load error = %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("loaded %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func nodeName(node *tasks.TaskTreeNode) string {
+	return node.Name
+}
@@ -0,0 +1,105 @@
+package config
+
+import (
+	"fmt"
+	"slices"
+
+	"github.com/zwoo-hq/zwooc/pkg/helper"
+	"github.com/zwoo-hq/zwooc/pkg/model"
+)
+
+// loadLocalFile merges the optional local config next to the root config into the config. Objects are merged
+// and lists are appended like when merging profiles, all other values are replaced.
+func (c *Config) loadLocalFile() error {
+	path, err := helper.FindFileIn(c.baseDir, FileNames(model.LocalConfigFile)...)
+	if err != nil || path == "" {
+		return err
+	}
+
+	file, data, order, err := c.readConfigFile(path)
+	if err != nil {
+		return err
+	}
+	if _, ok := data[model.KeyInclude]; ok {
+		return fmt.Errorf("%s is not allowed in '%s'", model.KeyInclude, file.name)
+	}
+
+	for key := range data {
+		pointer := helper.JsonPointer("", key)
+		if _, ok := c.raw[key]; !ok && !IsReservedKey(key) {
+			c.sources[pointer] = file.name
+		}
+	}
+	c.recordMergedLocations(c.raw, data, "", "", file)
+	c.raw = helper.MergeDeep(c.raw, data)
+
+	for pointer, keys := range order {
+		for _, key := range keys {
+			if !slices.Contains(c.order[pointer], key) {
+				c.order[pointer] = append(c.order[pointer], key)
+			}
+		}
+	}
+	return nil
+}
+
+// recordMergedLocations records the locations of all values of value, which is merged into target via helper.MergeDeep.
+func (c *Config) recordMergedLocations(target, value interface{}, pointer, filePointer string, file *sourceFile) {
+	switch value := value.(type) {
+	case map[string]interface{}:
+		if existing, ok := target.(map[string]interface{}); ok {
+			for key, item := range value {
+				c.recordMergedLocations(existing[key], item, helper.JsonPointer(pointer, key), helper.JsonPointer(filePointer, key), file)
+			}
+			return
+		}
+	case []interface{}:
+		if existing, ok := target.([]interface{}); ok {
+			for index, item := range value {
+				c.recordLocations(helper.JsonPointer(pointer, fmt.Sprint(len(existing)+index)), helper.JsonPointer(filePointer, fmt.Sprint(index)), item, file)
+			}
+			return
+		}
+	}
+	c.recordLocations(pointer, filePointer, value, file)
+}
+
+// A ConfigValue is a value of the merged config together with the file it's defined in.
+type ConfigValue struct {
+	Pointer string
+	Value   interface{}
+	Origin  string
+}
+
+// Values returns all values (strings, numbers, booleans, null and empty objects or lists) of the
+// merged config in document order.
+func (c Config) Values() []ConfigValue {
+	values := []ConfigValue{}
+	var walk func(pointer string, value interface{})
+	walk = func(pointer string, value interface{}) {
+		switch current := value.(type) {
+		case map[string]interface{}:
+			if len(current) > 0 {
+				for _, key := range c.order.Keys(pointer, current) {
+					walk(helper.JsonPointer(pointer, key), current[key])
+				}
+				return
+			}
+		case []interface{}:
+			if len(current) > 0 {
+				for index, item := range current {
+					walk(helper.JsonPointer(pointer, fmt.Sprint(index)), item)
+				}
+				return
+			}
+		}
+		values = append(values, ConfigValue{Pointer: pointer, Value: value, Origin: c.location(pointer).file.name})
+	}
+	walk("", c.raw)
+	return values
+}
+
+// Encode returns the merged config as indented json in document order.
+func (c Config) Encode() ([]byte, error) {
+	return helper.EncodeOrdered(c.raw, c.order)
+}
@@ -0,0 +1,116 @@
+package config
+
+import (
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/zwoo-hq/zwooc/pkg/helper"
+	"github.com/zwoo-hq/zwooc/pkg/model"
+)
+
+func TestLoad_MergesLocalFile(t *testing.T) {
+	dir := writeConfigFiles(t, map[string]string{
+		model.ConfigFile: `{
+			"$options": {"exclude": ["docs"]},
+			"web": {"$adapter": "custom", "dev": {"env": ["PORT=8080"], "args": {"host": "localhost"}, "build": "echo web"}},
+			"$compounds": {"all": {"profiles": {"dev": "build"}}}
+		}`,
+		"api/" + model.ProjectConfigFile: `{
+			"api": {"$adapter": "custom", "api": {"build": "echo api"}}
+		}`,
+		model.LocalConfigFile: `{
+			"$options": {"maxConcurrency": 2, "exclude": ["api"]},
+			"web": {"dev": {"env": ["PORT=9000"], "args": {"port": "9000"}}},
+			"api": {"api": {"build": "echo local"}}
+		}`,
+	})
+
+	conf, err := Load(filepath.Join(dir, model.ConfigFile))
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+
+	if want := (model.WorkspaceOptions{MaxConcurrency: 2, Exclude: []string{"docs", "api"}}); !reflect.DeepEqual(conf.GetOptions(), want) {
+		t.Errorf("GetOptions() = %v, want %v", conf.GetOptions(), want)
+	}
+	profile, err := conf.resolveProfile("dev", model.ModeBuild)
+	if err != nil {
+		t.Fatalf("resolveProfile() error = %v", err)
+	}
+	options := profile.GetProfileOptions()
+	if want := []string{"PORT=8080", "PORT=9000"}; !reflect.DeepEqual(options.Env, want) {
+		t.Errorf("expected env %v, got %v", want, options.Env)
+	}
+	if want := map[string]string{"host": "localhost", "port": "9000"}; !reflect.DeepEqual(options.Args, want) {
+		t.Errorf("expected args %v, got %v", want, options.Args)
+	}
+
+	origins := map[string]string{}
+	for _, value := range conf.Values() {
+		origins[value.Pointer] = value.Origin
+	}
+	want := map[string]string{
+		"/$options/exclude/0":                model.ConfigFile,
+		"/$options/exclude/1":                model.LocalConfigFile,
+		"/$options/maxConcurrency":           model.LocalConfigFile,
+		"/web/$adapter":                      model.ConfigFile,
+		"/web/dev/env/0":                     model.ConfigFile,
+		"/web/dev/env/1":                     model.LocalConfigFile,
+		"/web/dev/args/host":                 model.ConfigFile,
+		"/web/dev/args/port":                 model.LocalConfigFile,
+		"/web/dev/build":                     model.ConfigFile,
+		"/$compounds/all/profiles/0/profile": model.ConfigFile,
+		"/$compounds/all/profiles/0/mode":    model.ConfigFile,
+		"/api/$adapter":                      "api/" + model.ProjectConfigFile,
+		"/api/$dir":                          "api/" + model.ProjectConfigFile,
+		"/api/api/build":                     model.LocalConfigFile,
+	}
+	if !reflect.DeepEqual(origins, want) {
+		t.Errorf("Values() origins = %v, want %v", origins, want)
+	}
+}
+
+func TestLoad_LocalFileErrors(t *testing.T) {
+	dir := writeConfigFiles(t, map[string]string{
+		model.ConfigFile:      `{}`,
+		model.LocalConfigFile: `{"$include": "other.json"}`,
+	})
+	_, err := Load(filepath.Join(dir, model.ConfigFile))
+	if err == nil || err.Error() != "$include is not allowed in 'zwooc.local.json'" {
+		t.Errorf("Load() error = %v", err)
+	}
+}
+
+func TestConfig_Encode(t *testing.T) {
+	dir := writeConfigFiles(t, map[string]string{
+		model.ConfigFile: `{"web": {"$adapter": "custom", "dev": {"build": "a && b"}}, "$fragments": {}}`,
+	})
+	conf, err := Load(filepath.Join(dir, model.ConfigFile))
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	content, err := conf.Encode()
+	if err != nil {
+		t.Fatalf("Encode() error = %v", err)
+	}
+	want := strings.Join([]string{
+		`{`,
+		`  "web": {`,
+		`    "$adapter": "custom",`,
+		`    "dev": {`,
+		`      "build": "a && b"`,
+		`    }`,
+		`  },`,
+		`  "$fragments": {}`,
+		`}`,
+		``,
+	}, "\n")
+	if string(content) != want {
+		t.Errorf("Encode() = %s, want %s", content, want)
+	}
+	if _, _, err := helper.DecodeOrdered(content); err != nil {
+		t.Errorf("Encode() returned invalid json: %v", err)
+	}
+}
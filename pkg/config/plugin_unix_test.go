@@ -0,0 +1,120 @@
+//go:build !windows
+
+package config
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/zwoo-hq/zwooc/pkg/adapter/plugin"
+	"github.com/zwoo-hq/zwooc/pkg/model"
+	"github.com/zwoo-hq/zwooc/pkg/tasks"
+)
+
+// writePlugin writes an adapter plugin into the plugin directory of the config, which stores its request
+// in the working directory and answers with the response.
+func writePlugin(t *testing.T, dir, name, response string) {
+	t.Helper()
+	path := filepath.Join(dir, filepath.FromSlash(model.AdapterPluginDirectory), model.AdapterPluginPrefix+name)
+	script := "#!/bin/sh\ncat > request.json\ncat <<'EOF'\n" + response + "\nEOF\n"
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte(script), 0755); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestLoad_AdapterPlugins(t *testing.T) {
+	dir := writeConfigFiles(t, map[string]string{
+		model.ConfigFile: `{
+			"api": {
+				"$adapter": "cargo",
+				"api": {"features": ["tls"], "env": ["RUST_LOG=debug"]},
+				"api-probed": {"base": "api", "readyWhen": {"tcp": "localhost:9000"}}
+			},
+			"broken": {"$adapter": "broken", "broken": {}}
+		}`,
+		"api/Cargo.toml":    "",
+		"broken/Cargo.toml": "",
+	})
+	writePlugin(t, dir, "cargo", `{"args": ["cargo", "run", "--features", "tls"], "env": ["RUST_LOG=info", "CARGO_TERM_COLOR=always"], "dir": "crates", "readyWhen": {"tcp": "localhost:8000"}}`)
+	writePlugin(t, dir, "broken", "not json")
+	conf, err := Load(filepath.Join(dir, model.ConfigFile))
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+
+	nodes, err := conf.LoadProfile("api", model.ModeRun, NewContext(LoadOptions{ExtraArgs: []string{"--", "--port", "8000"}}))
+	if err != nil {
+		t.Fatalf("LoadProfile() error = %v", err)
+	}
+	want := "RUST_LOG=info CARGO_TERM_COLOR=always RUST_LOG=debug cargo run --features tls"
+	if got := tasks.CommandLine(nodes[0].Main); !strings.HasSuffix(got, want) {
+		t.Errorf("expected the command of the plugin '%s', got '%s'", want, got)
+	}
+	if nodes[0].ReadyWhen == nil || nodes[0].ReadyWhen.Address != "localhost:8000" {
+		t.Errorf("expected the readiness probe hinted by the plugin, got %v", nodes[0].ReadyWhen)
+	}
+
+	content, err := os.ReadFile(filepath.Join(dir, "api", "request.json"))
+	if err != nil {
+		t.Fatalf("expected the plugin to run in the directory of the project: %v", err)
+	}
+	request := plugin.Request{}
+	if err := json.Unmarshal(content, &request); err != nil {
+		t.Fatalf("invalid request %s: %v", content, err)
+	}
+	wantRequest := plugin.Request{
+		Version:   plugin.ProtocolVersion,
+		Adapter:   "cargo",
+		Name:      "api",
+		Mode:      model.ModeRun,
+		Directory: filepath.Join(dir, "api"),
+		Options:   map[string]interface{}{"features": []interface{}{"tls"}, "env": []interface{}{"RUST_LOG=debug"}},
+		Env:       []string{"RUST_LOG=debug"},
+		ExtraArgs: []string{"--", "--port", "8000"},
+	}
+	if !reflect.DeepEqual(request, wantRequest) {
+		t.Errorf("expected the request %+v, got %+v", wantRequest, request)
+	}
+
+	nodes, err = conf.LoadProfile("api-probed", model.ModeRun, NewContext(LoadOptions{}))
+	if err != nil {
+		t.Fatalf("LoadProfile() error = %v", err)
+	}
+	if nodes[0].ReadyWhen == nil || nodes[0].ReadyWhen.Address != "localhost:9000" {
+		t.Errorf("expected the readiness probe of the profile to take precedence, got %v", nodes[0].ReadyWhen)
+	}
+
+	_, err = conf.LoadProfile("broken", model.ModeRun, NewContext(LoadOptions{}))
+	if err == nil || !strings.Contains(err.Error(), "invalid response of adapter plugin") {
+		t.Errorf("expected an invalid response error, got %v", err)
+	}
+}
+
+func TestValidate_AdapterPlugins(t *testing.T) {
+	dir := writeConfigFiles(t, map[string]string{
+		model.ConfigFile: `{"api": {"$adapter": "cargo", "api": {"features": ["tls"], "run": {"release": true}}}, "cli": {"$adapter": "crago"}}`,
+	})
+	writePlugin(t, dir, "cargo", `{}`)
+
+	diagnostics, err := Validate(filepath.Join(dir, model.ConfigFile))
+	if err != nil {
+		t.Fatalf("Validate() error = %v", err)
+	}
+	got := []string{}
+	for _, diagnostic := range diagnostics {
+		got = append(got, diagnostic.String())
+	}
+	want := []string{
+		"zwooc.config.json:1:96: error: /cli/$adapter: unknown adapter 'crago', did you mean 'cargo'?",
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Validate() =\n%v\nwant\n%v", got, want)
+	}
+}
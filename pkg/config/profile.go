@@ -9,15 +9,37 @@ import (
 
 type Profile struct {
 	name      string
+	project   string
 	adapter   string
 	directory string
-	raw       map[string]interface{}
+	// adapterDefinition is the definition of the adapter, if it's defined in the config
+	adapterDefinition *model.AdapterDefinition
+	// plugin is the executable of the adapter, if it's provided by an adapter plugin
+	plugin string
+	// source is the config file the profile is defined in
+	source string
+	// envFiles are the env files of the project
+	envFiles []string
+	// overlays are the options of the selected environment applying to the profile, in the order they are applied
+	overlays []map[string]interface{}
+	raw      map[string]interface{}
 }
 
 func (p Profile) Name() string {
 	return p.name
 }
 
+// Modes returns all run modes the profile is defined for, disabled modes are excluded.
+func (p Profile) Modes() []string {
+	modes := []string{}
+	for _, mode := range []string{model.ModeRun, model.ModeWatch, model.ModeBuild} {
+		if options, ok := p.raw[mode]; ok && options != false {
+			modes = append(modes, mode)
+		}
+	}
+	return modes
+}
+
 func (p Profile) ResolveConfig(mode string) (ResolvedProfile, error) {
 	if !IsValidRunMode(mode) {
 		return ResolvedProfile{}, fmt.Errorf("invalid run mode: '%s'", mode)
@@ -25,7 +47,7 @@ func (p Profile) ResolveConfig(mode string) (ResolvedProfile, error) {
 
 	options := p.raw[mode]
 	if options == false {
-		return ResolvedProfile{}, fmt.Errorf("profile '%s' disabled mode '%s'", p.name, mode)
+		return ResolvedProfile{}, fmt.Errorf("profile '%s'%s disabled mode '%s'", p.name, inFile(p.source), mode)
 	}
 
 	if command, ok := options.(string); ok && p.adapter == model.AdapterCustom {
@@ -34,25 +56,43 @@ func (p Profile) ResolveConfig(mode string) (ResolvedProfile, error) {
 	}
 
 	config := ResolvedProfile{
-		Name:      p.name,
-		Adapter:   p.adapter,
-		Directory: p.directory,
-		Mode:      mode,
-		Options:   map[string]interface{}{},
+		Name:              p.name,
+		Project:           p.project,
+		Adapter:           p.adapter,
+		AdapterDefinition: p.adapterDefinition,
+		Plugin:            p.plugin,
+		Directory:         p.directory,
+		Source:            p.source,
+		Mode:              mode,
+		EnvFiles:          p.envFiles,
+		Options:           map[string]interface{}{},
 	}
 
 	if optionsMap, ok := options.(map[string]interface{}); ok {
-		config.Options = optionsMap
+		config.Options = helper.CloneDeep(optionsMap)
 	}
 
 	// hoist "global" options
-	var allowedHoistedOptions = map[string]interface{}{}
-	for optionKey, optionValue := range p.raw {
-		if !IsValidRunMode(optionKey) {
-			allowedHoistedOptions[optionKey] = optionValue
+	config.Options = helper.MergeDeep(hoistedOptions(p.raw), config.Options)
+
+	// apply the selected environment
+	for _, overlay := range p.overlays {
+		config.Options = helper.MergeDeep(config.Options, hoistedOptions(overlay))
+		if modeOverlay, ok := overlay[mode].(map[string]interface{}); ok {
+			config.Options = helper.MergeDeep(config.Options, helper.CloneDeep(modeOverlay))
 		}
 	}
-	config.Options = helper.MergeDeep(allowedHoistedOptions, config.Options)
 
 	return config, nil
 }
+
+// hoistedOptions returns a copy of all options of a definition which apply to every run mode.
+func hoistedOptions(definition map[string]interface{}) map[string]interface{} {
+	options := map[string]interface{}{}
+	for optionKey, optionValue := range definition {
+		if !IsValidRunMode(optionKey) {
+			options[optionKey] = optionValue
+		}
+	}
+	return helper.CloneDeep(options)
+}
@@ -0,0 +1,27 @@
+package config
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestProfile_Modes(t *testing.T) {
+	tests := []struct {
+		name     string
+		raw      map[string]interface{}
+		expected []string
+	}{
+		{"Should return no modes", map[string]interface{}{"base": "dev"}, []string{}},
+		{"Should return defined modes", map[string]interface{}{"watch": map[string]interface{}{}, "run": true}, []string{"run", "watch"}},
+		{"Should exclude disabled modes", map[string]interface{}{"run": true, "build": false}, []string{"run"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			profile := Profile{name: "test", raw: tt.raw}
+			if got := profile.Modes(); !reflect.DeepEqual(got, tt.expected) {
+				t.Errorf("Profile.Modes() = %v, want %v", got, tt.expected)
+			}
+		})
+	}
+}
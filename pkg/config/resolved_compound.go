@@ -3,9 +3,11 @@ package config
 import "github.com/zwoo-hq/zwooc/pkg/model"
 
 type ResolvedCompound struct {
-	Name             string
-	Directory        string
-	Profiles         map[string]string
+	Name      string
+	Directory string
+	// Source is the config file the compound is defined in
+	Source           string
+	Profiles         model.ProfileTargets
 	IncludeFragments []string
 	Options          map[string]interface{}
 }
@@ -13,17 +15,25 @@ type ResolvedCompound struct {
 var _ Hookable = (*ResolvedCompound)(nil)
 
 func (c ResolvedCompound) ResolvePreHook() ResolvedHook {
-	if options, ok := c.Options[model.KeyPre]; ok {
-		hook := Hook{options.(map[string]interface{})}
-		return hook.ResolveWithCompound(c, model.KeyPre)
-	}
-	return ResolvedHook{}
+	return c.resolveHook(model.KeyPre)
 }
 
 func (c ResolvedCompound) ResolvePostHook() ResolvedHook {
-	if options, ok := c.Options[model.KeyPost]; ok {
+	return c.resolveHook(model.KeyPost)
+}
+
+func (c ResolvedCompound) ResolveOnErrorHook() ResolvedHook {
+	return c.resolveHook(model.KeyOnError)
+}
+
+func (c ResolvedCompound) ResolveFinallyHook() ResolvedHook {
+	return c.resolveHook(model.KeyFinally)
+}
+
+func (c ResolvedCompound) resolveHook(kind string) ResolvedHook {
+	if options, ok := c.Options[kind]; ok {
 		hook := Hook{options.(map[string]interface{})}
-		return hook.ResolveWithCompound(c, model.KeyPost)
+		return hook.ResolveWithCompound(c, kind)
 	}
 	return ResolvedHook{}
 }
@@ -7,42 +7,69 @@ import (
 )
 
 type ResolvedFragment struct {
-	Name       string
-	Directory  string
+	Name      string
+	Project   string
+	Directory string
+	// Source is the config file the fragment is defined in
+	Source     string
 	Command    string
 	ProfileKey string
 	Mode       string
-	Options    map[string]interface{}
+	// EnvFiles are the env files of the project, which are loaded before the env files of the fragment
+	EnvFiles []string
+	// Env contains the environment variables loaded from the env files and the env option
+	Env     []tasks.EnvVariable
+	Options map[string]interface{}
 }
 
 var _ Hookable = (*ResolvedFragment)(nil)
 
 func (r ResolvedFragment) ResolvePreHook() ResolvedHook {
-	if options, ok := r.Options[model.KeyPre]; ok {
-		hook := Hook{options.(map[string]interface{})}
-		return hook.ResolveWithFragment(r, model.KeyPre)
-	}
-	return ResolvedHook{}
+	return r.resolveHook(model.KeyPre)
 }
 
 func (r ResolvedFragment) ResolvePostHook() ResolvedHook {
-	if options, ok := r.Options[model.KeyPost]; ok {
+	return r.resolveHook(model.KeyPost)
+}
+
+func (r ResolvedFragment) ResolveOnErrorHook() ResolvedHook {
+	return r.resolveHook(model.KeyOnError)
+}
+
+func (r ResolvedFragment) ResolveFinallyHook() ResolvedHook {
+	return r.resolveHook(model.KeyFinally)
+}
+
+func (r ResolvedFragment) resolveHook(kind string) ResolvedHook {
+	if options, ok := r.Options[kind]; ok {
 		hook := Hook{options.(map[string]interface{})}
-		return hook.ResolveWithFragment(r, model.KeyPost)
+		return hook.ResolveWithFragment(r, kind)
 	}
 	return ResolvedHook{}
 }
 
+func (r ResolvedFragment) GetEnvOptions() model.EnvOptions {
+	return helper.MapToStruct(r.Options, model.EnvOptions{})
+}
+
+func (r ResolvedFragment) GetTaskOptions() model.TaskOptions {
+	return helper.MapToStruct(r.Options, model.TaskOptions{})
+}
+
 func (r ResolvedFragment) GetTask(extraArgs []string) tasks.Task {
 	if r.Command == "" {
 		return tasks.Empty()
 	}
-	return tasks.NewBasicCommandTask(r.Name, r.Command, r.Directory, extraArgs)
+	task := tasks.NewBasicCommandTask(r.Name, r.Command, r.Directory, extraArgs)
+	tasks.SetEnv(task, tasks.EnvList(r.Env))
+	return task
 }
 
 func (r ResolvedFragment) GetTaskWithBaseName(baseName string, extraArgs []string) tasks.Task {
 	if r.Command == "" {
 		return tasks.Empty()
 	}
-	return tasks.NewBasicCommandTask(helper.BuildName(baseName, r.Name), r.Command, r.Directory, extraArgs)
+	task := tasks.NewBasicCommandTask(helper.BuildName(baseName, r.Name), r.Command, r.Directory, extraArgs)
+	tasks.SetEnv(task, tasks.EnvList(r.Env))
+	return task
 }
@@ -2,16 +2,20 @@ package config
 
 import (
 	"github.com/zwoo-hq/zwooc/pkg/helper"
+	"github.com/zwoo-hq/zwooc/pkg/model"
 	"github.com/zwoo-hq/zwooc/pkg/tasks"
 )
 
 type ResolvedHook struct {
-	Kind      string
-	Command   string
-	Fragments []string
-	Profiles  map[string]string
-	Base      string
-	Directory string
+	Kind         string
+	Command      string
+	Fragments    []string
+	Profiles     model.ProfileTargets
+	AllowFailure bool
+	Env          []string
+	EnvFiles     []string // the env files of the project of the caller followed by the env files of the hook
+	Base         string
+	Directory    string
 }
 
 func (r ResolvedHook) GetTask() tasks.Task {
@@ -3,6 +3,8 @@ package config
 import (
 	"fmt"
 
+	"github.com/zwoo-hq/zwooc/pkg/adapter/declarative"
+	"github.com/zwoo-hq/zwooc/pkg/adapter/plugin"
 	"github.com/zwoo-hq/zwooc/pkg/helper"
 	"github.com/zwoo-hq/zwooc/pkg/model"
 	"github.com/zwoo-hq/zwooc/pkg/tasks"
@@ -11,9 +13,20 @@ import (
 type ResolvedProfile struct {
 	Name      string
 	Mode      string
+	Project   string
 	Adapter   string
 	Directory string
-	Options   map[string]interface{}
+	// AdapterDefinition is the definition of the adapter, if it's defined in the config
+	AdapterDefinition *model.AdapterDefinition
+	// Plugin is the executable of the adapter, if it's provided by an adapter plugin
+	Plugin string
+	// Source is the config file the profile is defined in
+	Source string
+	// EnvFiles are the env files of the project, which are loaded before the env files of the profile
+	EnvFiles []string
+	// Env contains the environment variables loaded from the env files and the env option
+	Env     []tasks.EnvVariable
+	Options map[string]interface{}
 }
 
 var _ Hookable = (*ResolvedProfile)(nil)
@@ -55,26 +68,57 @@ func (r ResolvedProfile) GetProfileOptions() model.ProfileOptions {
 	return helper.MapToStruct(r.Options, model.ProfileOptions{})
 }
 
+func (r ResolvedProfile) GetEnv() []string {
+	return tasks.EnvList(r.Env)
+}
+
+func (r ResolvedProfile) GetTaskOptions() model.TaskOptions {
+	return helper.MapToStruct(r.Options, model.TaskOptions{})
+}
+
 func (r ResolvedProfile) ResolvePreHook() ResolvedHook {
-	if options, ok := r.Options[model.KeyPre]; ok {
-		hook := Hook{options.(map[string]interface{})}
-		return hook.ResolveWithProfile(r, model.KeyPre)
-	}
-	return ResolvedHook{}
+	return r.resolveHook(model.KeyPre)
 }
 
 func (r ResolvedProfile) ResolvePostHook() ResolvedHook {
-	if options, ok := r.Options[model.KeyPost]; ok {
+	return r.resolveHook(model.KeyPost)
+}
+
+func (r ResolvedProfile) ResolveOnErrorHook() ResolvedHook {
+	return r.resolveHook(model.KeyOnError)
+}
+
+func (r ResolvedProfile) ResolveFinallyHook() ResolvedHook {
+	return r.resolveHook(model.KeyFinally)
+}
+
+func (r ResolvedProfile) resolveHook(kind string) ResolvedHook {
+	if options, ok := r.Options[kind]; ok {
 		hook := Hook{options.(map[string]interface{})}
-		return hook.ResolveWithProfile(r, model.KeyPost)
+		return hook.ResolveWithProfile(r, kind)
 	}
 	return ResolvedHook{}
 }
 
 func (r ResolvedProfile) GetTask(args []string) (tasks.Task, error) {
+	task, _, err := r.createTask(args)
+	return task, err
+}
+
+// createTask creates the task of the profile together with the hints of its adapter, only adapter plugins provide hints.
+func (r ResolvedProfile) createTask(args []string) (tasks.Task, plugin.Hints, error) {
 	adapter := GetAdapter(r.Adapter)
 	if adapter != nil {
-		return adapter.CreateTask(r, args), nil
+		return adapter.CreateTask(r, args), plugin.Hints{}, nil
+	}
+	if r.AdapterDefinition != nil {
+		if _, ok := r.AdapterDefinition.Modes[r.Mode]; !ok {
+			return tasks.Empty(), plugin.Hints{}, fmt.Errorf("adapter '%s' does not support the %s mode", r.Adapter, r.Mode)
+		}
+		return declarative.NewAdapter(*r.AdapterDefinition).CreateTask(r, args), plugin.Hints{}, nil
+	}
+	if r.Plugin != "" {
+		return plugin.NewAdapter(r.Adapter, r.Plugin).CreateTask(r, args)
 	}
-	return tasks.Empty(), fmt.Errorf("unknown adapter: '%s'", r.Adapter)
+	return tasks.Empty(), plugin.Hints{}, fmt.Errorf("unknown adapter: '%s' (no %s%s found in %s or on the PATH)", r.Adapter, model.AdapterPluginPrefix, r.Adapter, model.AdapterPluginDirectory)
 }
@@ -0,0 +1,418 @@
+package config
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+
+	"github.com/zwoo-hq/zwooc/pkg/helper"
+	"github.com/zwoo-hq/zwooc/pkg/model"
+	"github.com/zwoo-hq/zwooc/pkg/tasks"
+)
+
+//go:generate go run ../../cmd/zwooc schema --output ../../zwooc.schema.json
+
+// jsonSchema is the subset of json schema (draft 2020-12) used by the schema of the config. The fields
+// are encoded in the order they are declared in.
+type jsonSchema struct {
+	Schema               string        `json:"$schema,omitempty"`
+	ID                   string        `json:"$id,omitempty"`
+	Name                 string        `json:"name,omitempty"`
+	Description          string        `json:"description,omitempty"`
+	FileMatch            []string      `json:"fileMatch,omitempty"`
+	Url                  string        `json:"url,omitempty"`
+	Ref                  string        `json:"$ref,omitempty"`
+	Type                 string        `json:"type,omitempty"`
+	Const                interface{}   `json:"const,omitempty"`
+	Enum                 []string      `json:"enum,omitempty"`
+	Pattern              string        `json:"pattern,omitempty"`
+	Minimum              *int          `json:"minimum,omitempty"`
+	Items                *jsonSchema   `json:"items,omitempty"`
+	Properties           *schemaMap    `json:"properties,omitempty"`
+	PropertyNames        *jsonSchema   `json:"propertyNames,omitempty"`
+	AdditionalProperties interface{}   `json:"additionalProperties,omitempty"`
+	Required             []string      `json:"required,omitempty"`
+	OneOf                []*jsonSchema `json:"oneOf,omitempty"`
+	AnyOf                []*jsonSchema `json:"anyOf,omitempty"`
+	AllOf                []*jsonSchema `json:"allOf,omitempty"`
+	Not                  *jsonSchema   `json:"not,omitempty"`
+	If                   *jsonSchema   `json:"if,omitempty"`
+	Then                 *jsonSchema   `json:"then,omitempty"`
+	Defs                 *schemaMap    `json:"$defs,omitempty"`
+}
+
+// schemaMap maps keys to schemas and keeps the order the keys were added in.
+type schemaMap struct {
+	keys    []string
+	schemas map[string]*jsonSchema
+}
+
+func newSchemaMap() *schemaMap {
+	return &schemaMap{schemas: map[string]*jsonSchema{}}
+}
+
+func (m *schemaMap) set(key string, schema *jsonSchema) {
+	if _, ok := m.schemas[key]; !ok {
+		m.keys = append(m.keys, key)
+	}
+	m.schemas[key] = schema
+}
+
+func (m *schemaMap) MarshalJSON() ([]byte, error) {
+	buffer := bytes.Buffer{}
+	buffer.WriteByte('{')
+	for index, key := range m.keys {
+		if index > 0 {
+			buffer.WriteByte(',')
+		}
+		if err := helper.EncodeJsonValue(&buffer, key); err != nil {
+			return nil, err
+		}
+		buffer.WriteByte(':')
+		if err := helper.EncodeJsonValue(&buffer, m.schemas[key]); err != nil {
+			return nil, err
+		}
+	}
+	buffer.WriteByte('}')
+	return buffer.Bytes(), nil
+}
+
+// schemaEnums contains the values of the enums referenced by `enum=<name>` and `keys=<name>` in the schema
+// tag of an option.
+var schemaEnums = map[string][]string{
+	"modes":           runModes,
+	"argStyles":       {model.ArgStyleSpace, model.ArgStyleEquals},
+	"restartPolicies": helper.MapTo(tasks.RestartModes, func(mode tasks.RestartMode) string { return string(mode) }),
+	// all signals supported on any platform, SIGUSR1 and SIGUSR2 are not available on windows
+	"signals": {"SIGHUP", "SIGINT", "SIGQUIT", "SIGKILL", "SIGTERM", "SIGUSR1", "SIGUSR2"},
+}
+
+var hookDescriptions = map[string]string{
+	model.KeyPre:     "A hook executed before the task.",
+	model.KeyPost:    "A hook executed after the task succeeded.",
+	model.KeyOnError: "A hook executed if the task (or one of its hooks) failed.",
+	model.KeyFinally: "A hook executed after the task, even if it failed or the run was canceled.",
+}
+
+type schemaBuilder struct {
+	defs *schemaMap
+}
+
+// Schema generates the json schema of the config from the option types of the model.
+func Schema() ([]byte, error) {
+	b := schemaBuilder{defs: newSchemaMap()}
+	root := &jsonSchema{
+		Schema:               "https://json-schema.org/draft/2020-12/schema",
+		ID:                   "https://zwooc.igd20.de/zwooc.schema.json",
+		Name:                 "zwooc.json",
+		Description:          "zwooc configuration file",
+		FileMatch:            helper.Concat(FileNames(model.ConfigFile), FileNames(model.ProjectConfigFile), FileNames(model.LocalConfigFile)),
+		Url:                  "https://raw.githubusercontent.com/zwoo-hq/zwooc/main/zwooc.schema.json",
+		Type:                 "object",
+		Properties:           newSchemaMap(),
+		AdditionalProperties: b.ref("project", "A project definition.", b.project),
+	}
+	root.Properties.set("$schema", &jsonSchema{})
+	root.Properties.set(model.KeyInclude, b.withDescription(b.typeSchema(stringListType), "Glob patterns (relative to this file) of further config files, whose projects, fragments, compounds and environments are merged into the config."))
+	root.Properties.set(model.KeyFragment, &jsonSchema{
+		Description:          "A collection of global fragment definitions.",
+		Type:                 "object",
+		AdditionalProperties: b.ref("fragment", "A global fragment definition.", b.fragment),
+	})
+	root.Properties.set(model.KeyCompound, &jsonSchema{
+		Description:          "A collection of compound definitions.",
+		Type:                 "object",
+		AdditionalProperties: b.ref("compound", "A compound definition.", b.compound),
+	})
+	root.Properties.set(model.KeyEnvironments, &jsonSchema{
+		Description: "A collection of environments, which override options of profiles when selected via --env or ZWOOC_ENV.",
+		Type:        "object",
+		AdditionalProperties: &jsonSchema{
+			Description:          "An environment definition, which maps projects to their options.",
+			Type:                 "object",
+			AdditionalProperties: b.ref("environmentProject", "", b.environmentProject),
+		},
+	})
+	root.Properties.set(model.KeyAdapters, &jsonSchema{
+		Description:          "A collection of adapters, which projects can use like the built in adapters.",
+		Type:                 "object",
+		PropertyNames:        &jsonSchema{Not: &jsonSchema{Enum: model.Adapters}},
+		AdditionalProperties: b.withDescription(b.typeSchema(adapterType), "An adapter definition."),
+	})
+	root.Properties.set(model.KeyOptions, b.withDescription(b.typeSchema(reflect.TypeOf(model.WorkspaceOptions{})), "Defaults for options of the command line."))
+	root.Properties.set(model.KeyCache, b.withDescription(b.typeSchema(reflect.TypeOf(model.CacheOptions{})), "Options of the artifact cache storing the outputs of successful runs."))
+	root.Defs = b.defs
+
+	buffer := bytes.Buffer{}
+	encoder := json.NewEncoder(&buffer)
+	encoder.SetEscapeHTML(false)
+	encoder.SetIndent("", "  ")
+	if err := encoder.Encode(root); err != nil {
+		return nil, err
+	}
+	return buffer.Bytes(), nil
+}
+
+// ref returns a reference to a definition, which is built on first use.
+func (b *schemaBuilder) ref(name, description string, build func() *jsonSchema) *jsonSchema {
+	if _, ok := b.defs.schemas[name]; !ok {
+		// reserve the key first, so that recursive references do not build the definition again
+		b.defs.set(name, nil)
+		b.defs.set(name, build())
+	}
+	return &jsonSchema{Description: description, Ref: "#/$defs/" + name}
+}
+
+func (b *schemaBuilder) withDescription(schema *jsonSchema, description string) *jsonSchema {
+	schema.Description = description
+	return schema
+}
+
+// adapterKind returns the kind of adapter (like vite for vite-yarn), adapters of a kind share their options.
+func adapterKind(adapter string) string {
+	kind, _, _ := strings.Cut(adapter, "-")
+	return kind
+}
+
+func (b *schemaBuilder) adapterKinds() (kinds []string, adapters map[string][]string) {
+	adapters = map[string][]string{}
+	for _, adapter := range model.Adapters {
+		kind := adapterKind(adapter)
+		if _, ok := adapters[kind]; !ok {
+			kinds = append(kinds, kind)
+		}
+		adapters[kind] = append(adapters[kind], adapter)
+	}
+	return kinds, adapters
+}
+
+// project builds the definition of a project, whose profiles depend on the adapter of the project. The
+// adapter is not required, since zwooc.local.json overrides projects without repeating it.
+func (b *schemaBuilder) project() *jsonSchema {
+	project := &jsonSchema{
+		Type:       "object",
+		Properties: newSchemaMap(),
+	}
+	project.Properties.set(model.KeyAdapter, &jsonSchema{
+		Description: "The adapter used to run the profiles of the project, a built in adapter, one defined in $adapters or an adapter plugin (zwooc-adapter-<name>).",
+		AnyOf: []*jsonSchema{
+			{Enum: model.Adapters},
+			{Type: "string"},
+		},
+	})
+
+	kinds, adapters := b.adapterKinds()
+	for _, kind := range kinds {
+		condition := &jsonSchema{Properties: newSchemaMap(), Required: []string{model.KeyAdapter}}
+		condition.Properties.set(model.KeyAdapter, &jsonSchema{Enum: adapters[kind]})
+		project.AllOf = append(project.AllOf, &jsonSchema{
+			If: condition,
+			Then: b.ref(kind+"Project", "", func() *jsonSchema {
+				return b.adapterProject(kind, adapters[kind][0])
+			}),
+		})
+	}
+	return project
+}
+
+func (b *schemaBuilder) adapterProject(kind, adapter string) *jsonSchema {
+	profile := func() *jsonSchema { return b.profile(kind, adapter) }
+	project := &jsonSchema{
+		Description:          fmt.Sprintf("A %s project definition.", kind),
+		Type:                 "object",
+		Properties:           newSchemaMap(),
+		AdditionalProperties: b.ref(kind+"Profile", fmt.Sprintf("A %s profile definition.", kind), profile),
+	}
+	project.Properties.set(model.KeyAdapter, &jsonSchema{})
+	project.Properties.set(model.KeyDirectory, &jsonSchema{
+		Description: "The directory of the project, relative to the file it is defined in.",
+		Type:        "string",
+	})
+	project.Properties.set(model.KeyEnvFile, b.withDescription(b.typeSchema(stringListType), "Env files (relative to the project directory) loaded for all profiles, fragments and hooks of the project."))
+	project.Properties.set(model.KeyFragment, &jsonSchema{
+		Description:          "A collection of local fragment definitions.",
+		Type:                 "object",
+		AdditionalProperties: b.ref("fragment", "A local fragment definition.", b.fragment),
+	})
+	project.Properties.set(model.KeyDefault, b.ref(kind+"Profile", "The profile executed if no specific profile is given.", profile))
+	b.addHooks(project)
+	return project
+}
+
+// profile builds the definition of a profile, whose options apply to all run modes, which may override them.
+func (b *schemaBuilder) profile(kind, adapter string) *jsonSchema {
+	optionTypes := helper.Concat(profileOptionTypes, adapterOptionTypes(adapter))
+	profile := b.options(optionTypes...)
+	for _, mode := range runModes {
+		definition := &jsonSchema{
+			Description: fmt.Sprintf("The options of the profile in the %s mode, false disables the mode.", mode),
+			OneOf: []*jsonSchema{
+				b.ref(kind+"RunDefinition", "", func() *jsonSchema {
+					definition := b.options(optionTypes...)
+					b.addHooks(definition)
+					return definition
+				}),
+				{Const: false},
+			},
+		}
+		if adapter == model.AdapterCustom {
+			definition.Description = fmt.Sprintf("The command or the options of the profile in the %s mode, false disables the mode.", mode)
+			definition.OneOf = append(definition.OneOf, &jsonSchema{Type: "string"})
+		}
+		profile.Properties.set(mode, definition)
+	}
+	b.addHooks(profile)
+	return profile
+}
+
+// fragment builds the definition of a fragment, which is either a command or an object containing commands
+// for modes and profiles as well as options.
+func (b *schemaBuilder) fragment() *jsonSchema {
+	fragment := b.options(fragmentOptionTypes...)
+	fragment.Properties.set(model.KeyDefault, &jsonSchema{
+		Description: "The command executed if there is no command for the run mode or profile.",
+		Type:        "string",
+	})
+	b.addHooks(fragment)
+	fragment.AdditionalProperties = &jsonSchema{
+		Description: "The command for a run mode, a profile or a compound (`<mode>`, `<profile>` or `<mode>:<profile>`).",
+		Type:        "string",
+	}
+	return &jsonSchema{
+		OneOf: []*jsonSchema{
+			{Description: "The command of the fragment.", Type: "string"},
+			fragment,
+		},
+	}
+}
+
+func (b *schemaBuilder) compound() *jsonSchema {
+	compound := b.options(compoundOptionTypes...)
+	b.addHooks(compound)
+	return compound
+}
+
+// environmentProject builds the definition of the options of an environment for a project.
+func (b *schemaBuilder) environmentProject() *jsonSchema {
+	options := b.options(profileOptionTypes...)
+	options.Description = "Options of an environment for a project. Keys naming a profile of the project define options of this profile, all other options apply to every profile of the project."
+	options.AdditionalProperties = &jsonSchema{
+		Description: "The options of a profile of the project.",
+		Type:        "object",
+	}
+	return options
+}
+
+func (b *schemaBuilder) addHooks(object *jsonSchema) {
+	for _, key := range hookKeys {
+		object.Properties.set(key, b.ref("hook", hookDescriptions[key], func() *jsonSchema {
+			return b.options(hookOptionTypes...)
+		}))
+	}
+}
+
+// options builds an object containing the fields of the option types.
+func (b *schemaBuilder) options(optionTypes ...reflect.Type) *jsonSchema {
+	object := &jsonSchema{
+		Type:                 "object",
+		Properties:           newSchemaMap(),
+		AdditionalProperties: false,
+	}
+	for _, optionType := range optionTypes {
+		for i := 0; i < optionType.NumField(); i++ {
+			field := optionType.Field(i)
+			key := field.Tag.Get("json")
+			if key == "" {
+				continue
+			}
+			schema := b.withDescription(b.typeSchema(field.Type), field.Tag.Get("description"))
+			for _, constraint := range strings.Split(field.Tag.Get("schema"), ",") {
+				name, value, _ := strings.Cut(constraint, "=")
+				switch name {
+				case "":
+				case "required":
+					object.Required = append(object.Required, key)
+				case "enum", "keys":
+					values, ok := schemaEnums[value]
+					if !ok {
+						panic(fmt.Sprintf("unknown enum '%s' of %s.%s", value, optionType.Name(), field.Name))
+					}
+					if name == "keys" {
+						schema.PropertyNames = &jsonSchema{Enum: values}
+					} else {
+						schema.Enum = values
+					}
+				case "minimum":
+					minimum, err := strconv.Atoi(value)
+					if err != nil {
+						panic(fmt.Sprintf("invalid minimum '%s' of %s.%s", value, optionType.Name(), field.Name))
+					}
+					schema.Minimum = &minimum
+				case "pattern":
+					schema.Pattern = value
+				default:
+					panic(fmt.Sprintf("unknown schema constraint '%s' of %s.%s", name, optionType.Name(), field.Name))
+				}
+			}
+			object.Properties.set(key, schema)
+		}
+	}
+	return object
+}
+
+// typeSchema builds the schema of a type of the model, structs are referenced as definitions.
+func (b *schemaBuilder) typeSchema(fieldType reflect.Type) *jsonSchema {
+	switch fieldType {
+	case stringListType:
+		return b.ref("stringList", "", func() *jsonSchema {
+			return &jsonSchema{
+				OneOf: []*jsonSchema{
+					{Type: "string"},
+					{Type: "array", Items: &jsonSchema{Type: "string"}},
+				},
+			}
+		})
+	case profileTargetsType:
+		return b.ref("profileTargets", "", func() *jsonSchema {
+			return &jsonSchema{
+				OneOf: []*jsonSchema{
+					{
+						Description:          "The profiles mapped to the run mode they are executed in.",
+						Type:                 "object",
+						AdditionalProperties: &jsonSchema{Type: "string", Enum: runModes},
+					},
+					{
+						Description: "The profiles and the run modes they are executed in. A profile may be listed in multiple run modes.",
+						Type:        "array",
+						Items:       b.typeSchema(profileTargetsType.Elem()),
+					},
+				},
+			}
+		})
+	}
+
+	switch fieldType.Kind() {
+	case reflect.String:
+		return &jsonSchema{Type: "string"}
+	case reflect.Bool:
+		return &jsonSchema{Type: "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64, reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return &jsonSchema{Type: "integer"}
+	case reflect.Float32, reflect.Float64:
+		return &jsonSchema{Type: "number"}
+	case reflect.Slice:
+		return &jsonSchema{Type: "array", Items: b.typeSchema(fieldType.Elem())}
+	case reflect.Map:
+		return &jsonSchema{Type: "object", AdditionalProperties: b.typeSchema(fieldType.Elem())}
+	case reflect.Struct:
+		name := strings.TrimSuffix(fieldType.Name(), "Options")
+		name = strings.ToLower(name[:1]) + name[1:]
+		return b.ref(name, "", func() *jsonSchema {
+			return b.options(fieldType)
+		})
+	}
+	panic(fmt.Sprintf("unsupported option type %s", fieldType))
+}
@@ -0,0 +1,81 @@
+package config
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"testing"
+
+	"github.com/zwoo-hq/zwooc/pkg/model"
+)
+
+func TestSchema_IsUpToDate(t *testing.T) {
+	schema, err := Schema()
+	if err != nil {
+		t.Fatalf("Schema() error = %v", err)
+	}
+	committed, err := os.ReadFile("../../zwooc.schema.json")
+	if err != nil {
+		t.Fatalf("failed to read the committed schema: %v", err)
+	}
+	if !bytes.Equal(schema, committed) {
+		t.Errorf("zwooc.schema.json is stale, regenerate it with `go generate ./pkg/config`")
+	}
+}
+
+func TestSchema(t *testing.T) {
+	content, err := Schema()
+	if err != nil {
+		t.Fatalf("Schema() error = %v", err)
+	}
+	schema := map[string]interface{}{}
+	if err := json.Unmarshal(content, &schema); err != nil {
+		t.Fatalf("Schema() is not valid json: %v", err)
+	}
+
+	defs := schema["$defs"].(map[string]interface{})
+	adapter := defs["project"].(map[string]interface{})["properties"].(map[string]interface{})[model.KeyAdapter].(map[string]interface{})
+	adapters := adapter["anyOf"].([]interface{})[0].(map[string]interface{})["enum"].([]interface{})
+	if len(adapters) != len(model.Adapters) {
+		t.Errorf("Schema() contains the adapters %v, want %v", adapters, model.Adapters)
+	}
+
+	tests := []struct {
+		def    string
+		option string
+	}{
+		{"viteProfile", "mode"},
+		{"viteRunDefinition", "mode"},
+		{"dotnetProfile", "project"},
+		{"customRunDefinition", "command"},
+		{"tauriProfile", "includeFragments"},
+		{"tauriProfile", "readyWhen"},
+		{"tauriProfile", "watch"},
+		{"tauriProfile", "$pre"},
+		{"fragment", ""},
+		{"hook", "profiles"},
+		{"compound", "profiles"},
+		{"restart", "policy"},
+		{"environmentProject", "envFile"},
+		{"adapterDefinition", "argStyle"},
+	}
+	for _, tt := range tests {
+		def, ok := defs[tt.def].(map[string]interface{})
+		if !ok {
+			t.Errorf("Schema() is missing the definition %s", tt.def)
+			continue
+		}
+		if tt.option == "" {
+			continue
+		}
+		if _, ok := def["properties"].(map[string]interface{})[tt.option]; !ok {
+			t.Errorf("Schema() definition %s is missing the option %s", tt.def, tt.option)
+		}
+	}
+
+	for _, adapterSpecific := range []struct{ def, option string }{{"tauriProfile", "mode"}, {"viteProfile", "project"}, {"dotnetRunDefinition", "command"}} {
+		if _, ok := defs[adapterSpecific.def].(map[string]interface{})["properties"].(map[string]interface{})[adapterSpecific.option]; ok {
+			t.Errorf("Schema() definition %s should not contain the option %s", adapterSpecific.def, adapterSpecific.option)
+		}
+	}
+}
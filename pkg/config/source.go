@@ -0,0 +1,120 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/zwoo-hq/zwooc/pkg/helper"
+	"github.com/zwoo-hq/zwooc/pkg/model"
+)
+
+// A sourceFile is a config file the config is loaded from.
+type sourceFile struct {
+	// name is the path of the file relative to the root config
+	name    string
+	content []byte
+	offsets helper.Offsets
+}
+
+// A location is the place a value of the config is defined at.
+type location struct {
+	file *sourceFile
+	// pointer is the json pointer of the value in the file
+	pointer string
+}
+
+// Position returns the line and column of the location, they are 0 if the location is unknown.
+func (l location) Position() (line, column int) {
+	if l.file == nil {
+		return 0, 0
+	}
+	offset, ok := l.file.offsets[l.pointer]
+	if !ok {
+		return 0, 0
+	}
+	return helper.Position(l.file.content, offset)
+}
+
+// FileNames returns the names a config file may have, which are the json name (like zwooc.config.json)
+// and the jsonc name (like zwooc.config.jsonc).
+func FileNames(name string) []string {
+	return []string{name, strings.TrimSuffix(name, filepath.Ext(name)) + model.JsoncExtension}
+}
+
+// readConfigFile reads and decodes a config file.
+func (c Config) readConfigFile(path string) (*sourceFile, map[string]interface{}, helper.KeyOrder, error) {
+	file := &sourceFile{name: c.relativePath(path)}
+	var err error
+	file.content, err = os.ReadFile(path)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+
+	data, order, offsets, err := helper.DecodeWithOffsets(file.content)
+	if err != nil {
+		var syntaxError *helper.SyntaxError
+		if errors.As(err, &syntaxError) {
+			line, column := helper.Position(file.content, syntaxError.Offset)
+			return nil, nil, nil, fmt.Errorf("invalid config file '%s' (line %d, column %d): %w", file.name, line, column, err)
+		}
+		return nil, nil, nil, fmt.Errorf("invalid config file '%s': %w", file.name, err)
+	}
+	file.offsets = offsets
+	return file, data, order, nil
+}
+
+// recordLocations records the location of the value at pointer and all of its children, replacing the
+// locations of a previous value. filePointer is the pointer of the value in the file.
+func (c *Config) recordLocations(pointer, filePointer string, value interface{}, file *sourceFile) {
+	for key := range c.locations {
+		if strings.HasPrefix(key, pointer+"/") {
+			delete(c.locations, key)
+		}
+	}
+
+	c.locations[pointer] = location{file: file, pointer: filePointer}
+	switch value := value.(type) {
+	case map[string]interface{}:
+		for key, item := range value {
+			c.recordLocations(helper.JsonPointer(pointer, key), helper.JsonPointer(filePointer, key), item, file)
+		}
+	case []interface{}:
+		for index, item := range value {
+			c.recordLocations(helper.JsonPointer(pointer, fmt.Sprint(index)), helper.JsonPointer(filePointer, fmt.Sprint(index)), item, file)
+		}
+	}
+}
+
+// location returns the location of the value at pointer. Values which are not part of a file (like
+// normalized or default values) are attributed to the closest value with a known location.
+func (c Config) location(pointer string) location {
+	for {
+		if location, ok := c.locations[pointer]; ok {
+			return location
+		}
+		if pointer == "" {
+			return location{file: &sourceFile{}}
+		}
+		pointer = pointer[:strings.LastIndex(pointer, "/")]
+	}
+}
+
+// relativePath returns the slash separated path of a file relative to the directory of the config.
+func (c Config) relativePath(path string) string {
+	relative, err := filepath.Rel(c.baseDir, path)
+	if err != nil {
+		return filepath.ToSlash(path)
+	}
+	return filepath.ToSlash(relative)
+}
+
+// inFile names the config file an entity is defined in for error messages, it's empty if the file is unknown.
+func inFile(source string) string {
+	if source == "" {
+		return ""
+	}
+	return fmt.Sprintf(" in '%s'", source)
+}
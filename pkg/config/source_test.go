@@ -0,0 +1,80 @@
+package config
+
+import (
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/zwoo-hq/zwooc/pkg/model"
+)
+
+func TestLoad_Jsonc(t *testing.T) {
+	dir := writeConfigFiles(t, map[string]string{
+		"zwooc.config.jsonc": `{
+  // the api is built with make
+  api: {
+    $adapter: 'custom',
+    api: {build: "make", env: ['A=1',],},
+  },
+  /* shared fragments */
+  $fragments: {lint: "lint"},
+}`,
+		"web/zwooc.project.jsonc": `{
+  "web": {"$adapter": "custom", "web": {"run": "serve"}}, // discovered
+}`,
+		"zwooc.local.jsonc": `{api: {api: {env: ["B=2"]}}}`,
+	})
+
+	c, err := Load(filepath.Join(dir, "zwooc.config.jsonc"))
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	profile, err := c.resolveProfile("api", model.ModeBuild)
+	if err != nil {
+		t.Fatalf("resolveProfile() error = %v", err)
+	}
+	if env := profile.GetProfileOptions().Env; !reflect.DeepEqual(env, []string{"A=1", "B=2"}) {
+		t.Errorf("expected env [A=1 B=2], got %v", env)
+	}
+	if _, err := c.resolveProfile("web", model.ModeRun); err != nil {
+		t.Errorf("resolveProfile() error = %v", err)
+	}
+}
+
+func TestLoad_JsoncErrors(t *testing.T) {
+	tests := []struct {
+		name  string
+		files map[string]string
+		err   string
+	}{
+		{"syntax error", map[string]string{
+			model.ConfigFile: "{\n  // comment\n  web: {$adapter: 'custom'}\n  api: {}\n}",
+		}, "invalid config file 'zwooc.config.json' (line 4, column 3): invalid character 'a' after object key:value pair"},
+		{"diagnostics", map[string]string{
+			model.ConfigFile: "{\n  /* web */ web: {\n    $adapter: 'custom',\n    dev: {run: true},\n  },\n}",
+		}, "zwooc.config.json:4:11: error: /web/dev/run: expected a command, an object or false but got a boolean"},
+		{"json and jsonc", map[string]string{
+			model.ConfigFile:      `{}`,
+			model.LocalConfigFile: `{}`,
+			"zwooc.local.jsonc":   `{}`,
+		}, "found both 'zwooc.local.json' and 'zwooc.local.jsonc'"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := writeConfigFiles(t, tt.files)
+			_, err := Load(filepath.Join(dir, model.ConfigFile))
+			if err == nil || !strings.Contains(err.Error(), tt.err) {
+				t.Errorf("Load() error = %v, want %s", err, tt.err)
+			}
+		})
+	}
+}
+
+func TestFileNames(t *testing.T) {
+	want := []string{"zwooc.config.json", "zwooc.config.jsonc"}
+	if got := FileNames(model.ConfigFile); !reflect.DeepEqual(got, want) {
+		t.Errorf("FileNames() = %v, want %v", got, want)
+	}
+}
@@ -0,0 +1,158 @@
+package config
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"path"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/zwoo-hq/zwooc/pkg/model"
+	"github.com/zwoo-hq/zwooc/pkg/tasks"
+)
+
+// applyTaskOptions applies the options controlling the execution of the main task to the node.
+func (c Config) applyTaskOptions(node *tasks.TaskTreeNode, options model.TaskOptions, directory string, ctx loadingContext) (err error) {
+	node.ReadyWhen, err = resolveReadinessProbe(options.ReadyWhen, directory)
+	if err != nil {
+		return err
+	}
+	node.Restart, err = resolveRestartPolicy(options.Restart, node.IsLongRunning)
+	if err != nil {
+		return err
+	}
+	stopPolicy, err := resolveStopPolicy(options)
+	if err != nil {
+		return err
+	}
+	tasks.SetStopPolicy(node.Main, stopPolicy)
+	if options.Tty || ctx.tty {
+		tasks.EnableTTY(node.Main)
+	}
+	node.UpToDate, err = c.resolveUpToDateCheck(node.Name, options, directory)
+	return err
+}
+
+// resolveReadinessProbe creates the readiness probe of a task, it returns nil if no probe is configured.
+// Relative files are resolved from the directory of the task.
+func resolveReadinessProbe(options model.ReadyOptions, directory string) (*tasks.ReadinessProbe, error) {
+	if options == (model.ReadyOptions{}) {
+		return nil, nil
+	}
+
+	probe := &tasks.ReadinessProbe{
+		Address: options.Tcp,
+		URL:     options.Http,
+		File:    options.File,
+	}
+	if options.Output != "" {
+		pattern, err := regexp.Compile(options.Output)
+		if err != nil {
+			return nil, fmt.Errorf("invalid readyWhen output pattern '%s': %w", options.Output, err)
+		}
+		probe.Output = pattern
+	}
+	if probe.File != "" && !filepath.IsAbs(probe.File) {
+		probe.File = filepath.Join(directory, probe.File)
+	}
+	return probe, nil
+}
+
+// resolveRestartPolicy creates the restart policy of a task, it returns nil if the task should never be restarted.
+// Only long running tasks are restarted, the policy of other tasks is ignored since they are expected to exit.
+func resolveRestartPolicy(options model.RestartOptions, isLongRunning bool) (*tasks.RestartPolicy, error) {
+	mode := tasks.RestartMode(options.Policy)
+	switch mode {
+	case "", tasks.RestartNever:
+		return nil, nil
+	case tasks.RestartOnFailure, tasks.RestartAlways:
+	default:
+		return nil, fmt.Errorf("invalid restart policy '%s': expected one of '%s', '%s' or '%s'", options.Policy, tasks.RestartNever, tasks.RestartOnFailure, tasks.RestartAlways)
+	}
+	if options.MaxAttempts < 0 {
+		return nil, fmt.Errorf("invalid restart maxAttempts %d: must not be negative", options.MaxAttempts)
+	}
+
+	policy := &tasks.RestartPolicy{
+		Mode:        mode,
+		MaxAttempts: options.MaxAttempts,
+	}
+	if options.Backoff != "" {
+		backoff, err := time.ParseDuration(options.Backoff)
+		if err != nil {
+			return nil, fmt.Errorf("invalid restart backoff '%s': %w", options.Backoff, err)
+		}
+		policy.Backoff = backoff
+	}
+	if !isLongRunning {
+		// the policy may be defined for all modes of a profile or a fragment used in a hook
+		return nil, nil
+	}
+	return policy, nil
+}
+
+// resolveStopPolicy creates the policy used to terminate a canceled task, unset options fall back to the defaults.
+func resolveStopPolicy(options model.TaskOptions) (tasks.StopPolicy, error) {
+	policy := tasks.DefaultStopPolicy
+	if options.StopSignal != "" {
+		signal, err := tasks.ParseSignal(options.StopSignal)
+		if err != nil {
+			return policy, fmt.Errorf("invalid stopSignal: %w", err)
+		}
+		policy.Signal = signal
+	}
+	if options.StopTimeout != "" {
+		timeout, err := time.ParseDuration(options.StopTimeout)
+		if err != nil {
+			return policy, fmt.Errorf("invalid stopTimeout '%s': %w", options.StopTimeout, err)
+		}
+		if timeout <= 0 {
+			return policy, fmt.Errorf("invalid stopTimeout '%s': must be positive", options.StopTimeout)
+		}
+		policy.Timeout = timeout
+	}
+	return policy, nil
+}
+
+// resolveUpToDateCheck creates the up to date check of a task, it returns nil if the task declares neither inputs nor outputs.
+// The fingerprint of the last successful run is recorded in the cache directory of the config, the outputs
+// are stored in the artifact cache unless it's disabled.
+func (c Config) resolveUpToDateCheck(name string, options model.TaskOptions, directory string) (*tasks.UpToDateCheck, error) {
+	if len(options.Inputs) == 0 && len(options.Outputs) == 0 {
+		return nil, nil
+	}
+
+	for _, pattern := range append(slices.Clone(options.Inputs), options.Outputs...) {
+		if _, err := path.Match(strings.TrimPrefix(pattern, "!"), ""); err != nil {
+			return nil, fmt.Errorf("invalid glob pattern '%s': %w", pattern, err)
+		}
+	}
+
+	return &tasks.UpToDateCheck{
+		Name:      name,
+		Directory: directory,
+		Inputs:    options.Inputs,
+		Outputs:   options.Outputs,
+		Env:       options.InputEnv,
+		Record:    filepath.Join(c.RecordsDir(), recordName(name)+".json"),
+		Artifacts: c.artifacts,
+	}, nil
+}
+
+// recordName converts the name of a node into a file name. The name is kept readable, while the hash of
+// the full name prevents collisions of names that only differ in characters not allowed in file names.
+func recordName(name string) string {
+	readable := strings.Map(func(r rune) rune {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) || r == '-' || r == '.' {
+			return r
+		}
+		return '_'
+	}, name)
+	hash := sha256.Sum256([]byte(name))
+	return readable + "-" + hex.EncodeToString(hash[:])[:16]
+}
@@ -0,0 +1,764 @@
+package config
+
+import (
+	"fmt"
+	"math"
+	"path/filepath"
+	"reflect"
+	"slices"
+	"strings"
+
+	"github.com/zwoo-hq/zwooc/pkg/helper"
+	"github.com/zwoo-hq/zwooc/pkg/model"
+	"golang.org/x/exp/maps"
+)
+
+type Severity string
+
+const (
+	SeverityError   Severity = "error"
+	SeverityWarning Severity = "warning"
+)
+
+// A Diagnostic is a problem of the config found while validating it.
+type Diagnostic struct {
+	Severity Severity
+	// Path is the json pointer of the value in the merged config
+	Path string
+	// File is the config file (relative to the root config) the value is defined in
+	File string
+	// Line and Column are the position of the value in the file, they are 0 if it's unknown
+	Line    int
+	Column  int
+	Message string
+}
+
+func (d Diagnostic) String() string {
+	position := d.File
+	if d.Line > 0 {
+		position = fmt.Sprintf("%s:%d:%d", d.File, d.Line, d.Column)
+	}
+	path := d.Path
+	if path == "" {
+		path = "/"
+	}
+	return fmt.Sprintf("%s: %s: %s: %s", position, d.Severity, path, d.Message)
+}
+
+// A ValidationError is returned when loading a config with errors.
+type ValidationError struct {
+	Diagnostics []Diagnostic
+}
+
+func (e *ValidationError) Error() string {
+	lines := []string{"invalid config:"}
+	for _, diagnostic := range e.Diagnostics {
+		lines = append(lines, "  "+diagnostic.String())
+	}
+	return strings.Join(lines, "\n")
+}
+
+// Validate loads the config at path with all of its files and returns all problems of it. The error is
+// only set if the files can't be loaded at all.
+func Validate(path string) ([]Diagnostic, error) {
+	c := Config{
+		baseDir: filepath.Dir(path),
+	}
+	if err := c.loadFiles(path); err != nil {
+		return nil, err
+	}
+	return c.validate(), nil
+}
+
+var (
+	profileOptionTypes  = []reflect.Type{reflect.TypeOf(model.BaseOptions{}), reflect.TypeOf(model.ProfileOptions{}), reflect.TypeOf(model.TaskOptions{})}
+	fragmentOptionTypes = []reflect.Type{reflect.TypeOf(model.TaskOptions{}), reflect.TypeOf(model.EnvOptions{})}
+	hookOptionTypes     = []reflect.Type{reflect.TypeOf(model.HookOptions{})}
+	compoundOptionTypes = []reflect.Type{reflect.TypeOf(model.CompoundOptions{})}
+	adapterType         = reflect.TypeOf(model.AdapterDefinition{})
+	stringListType      = reflect.TypeOf(model.StringList{})
+	profileTargetsType  = reflect.TypeOf(model.ProfileTargets{})
+	runModes            = []string{model.ModeRun, model.ModeWatch, model.ModeBuild}
+	hookKeys            = []string{model.KeyPre, model.KeyPost, model.KeyOnError, model.KeyFinally}
+)
+
+// adapterOptionTypes returns the options specific to an adapter.
+func adapterOptionTypes(adapter string) []reflect.Type {
+	switch adapter {
+	case model.AdapterViteYarn, model.AdapterViteNpm, model.AdapterVitePnpm:
+		return []reflect.Type{reflect.TypeOf(model.ViteOptions{})}
+	case model.AdapterDotnet:
+		return []reflect.Type{reflect.TypeOf(model.DotNetOptions{})}
+	case model.AdapterCustom:
+		return []reflect.Type{reflect.TypeOf(model.CustomOptions{})}
+	}
+	return nil
+}
+
+type validator struct {
+	config      *Config
+	diagnostics []Diagnostic
+	// profiles contains the project of all profiles
+	profiles  map[string]string
+	fragments []string
+	compounds []string
+	// adapters contains the adapters defined in the config
+	adapters map[string]map[string]interface{}
+	// plugins contains whether an adapter plugin exists for the adapters, which are neither built in nor defined in the config
+	plugins map[string]bool
+}
+
+// A keyHandler validates the value of a key, which is not a field of the option types of an object.
+type keyHandler func(pointer string, value interface{})
+
+// validate checks the types of all values of the config, unknown keys, adapters and run modes as well as
+// references to profiles, fragments and compounds.
+func (c *Config) validate() []Diagnostic {
+	v := validator{
+		config:    c,
+		profiles:  map[string]string{},
+		fragments: []string{},
+		compounds: []string{},
+		adapters:  map[string]map[string]interface{}{},
+		plugins:   map[string]bool{},
+	}
+	v.collectNames()
+
+	for _, key := range c.order.Keys("", c.raw) {
+		pointer := helper.JsonPointer("", key)
+		value := c.raw[key]
+		switch key {
+		case "$schema":
+			v.checkType(pointer, value, reflect.TypeOf(""))
+		case model.KeyInclude:
+			v.checkType(pointer, value, stringListType)
+		case model.KeyFragment:
+			v.eachEntry(pointer, value, func(entryPointer string, _ string, fragment interface{}) {
+				v.fragment(entryPointer, fragment)
+			})
+		case model.KeyCompound:
+			v.eachEntry(pointer, value, func(entryPointer string, _ string, compound interface{}) {
+				v.compound(entryPointer, compound)
+			})
+		case model.KeyEnvironments:
+			v.eachEntry(pointer, value, func(environmentPointer string, _ string, environment interface{}) {
+				v.eachEntry(environmentPointer, environment, func(projectPointer string, project string, options interface{}) {
+					v.environmentProject(projectPointer, project, options)
+				})
+			})
+		case model.KeyCache:
+			if v.checkType(pointer, value, reflect.TypeOf(model.CacheOptions{})) {
+				if size, ok := value.(map[string]interface{})["maxSize"].(string); ok {
+					if _, err := helper.ParseSize(size); err != nil {
+						v.errorf(helper.JsonPointer(pointer, "maxSize"), "%s", err)
+					}
+				}
+			}
+		case model.KeyOptions:
+			v.checkType(pointer, value, reflect.TypeOf(model.WorkspaceOptions{}))
+		case model.KeyAdapters:
+			v.eachEntry(pointer, value, v.adapter)
+		default:
+			if strings.HasPrefix(key, "$") {
+				v.unknownKey(pointer, key, []string{"$schema", model.KeyInclude, model.KeyFragment, model.KeyCompound, model.KeyEnvironments, model.KeyCache, model.KeyOptions, model.KeyAdapters})
+				continue
+			}
+			v.project(pointer, value)
+		}
+	}
+	return v.diagnostics
+}
+
+// collectNames collects the names of all profiles, fragments and compounds, so that references can be checked.
+func (v *validator) collectNames() {
+	c := v.config
+	for _, projectKey := range c.order.Keys("", c.raw) {
+		project, ok := c.raw[projectKey].(map[string]interface{})
+		if !ok || IsReservedKey(projectKey) {
+			continue
+		}
+		for _, key := range c.order.Keys(helper.JsonPointer("", projectKey), project) {
+			if IsReservedKey(key) {
+				continue
+			}
+			if other, ok := v.profiles[key]; ok {
+				v.warnf(helper.JsonPointer(helper.JsonPointer("", projectKey), key), "profile '%s' is already defined in project '%s', references resolve to the first definition", key, other)
+				continue
+			}
+			v.profiles[key] = projectKey
+		}
+		if fragments, ok := project[model.KeyFragment].(map[string]interface{}); ok {
+			v.fragments = append(v.fragments, maps.Keys(fragments)...)
+		}
+	}
+	if fragments, ok := c.raw[model.KeyFragment].(map[string]interface{}); ok {
+		v.fragments = append(v.fragments, maps.Keys(fragments)...)
+	}
+	if compounds, ok := c.raw[model.KeyCompound].(map[string]interface{}); ok {
+		v.compounds = append(v.compounds, maps.Keys(compounds)...)
+	}
+	if adapters, ok := c.raw[model.KeyAdapters].(map[string]interface{}); ok {
+		for name, definition := range adapters {
+			if definition, ok := definition.(map[string]interface{}); ok && !slices.Contains(model.Adapters, name) {
+				v.adapters[name] = definition
+			}
+		}
+	}
+}
+
+func (v *validator) project(pointer string, value interface{}) {
+	project, ok := v.expectObject(pointer, value)
+	if !ok {
+		return
+	}
+
+	adapter, hasAdapter := project[model.KeyAdapter]
+	if !hasAdapter {
+		v.errorf(pointer, "missing %s (one of %s)", model.KeyAdapter, strings.Join(model.Adapters, ", "))
+	}
+	adapterName, _ := adapter.(string)
+
+	handlers := map[string]keyHandler{
+		model.KeyAdapter: func(pointer string, value interface{}) {
+			if !v.checkType(pointer, value, reflect.TypeOf("")) {
+				return
+			}
+			adapters := append(slices.Clone(model.Adapters), maps.Keys(v.adapters)...)
+			for plugin, exists := range v.plugins {
+				if exists {
+					adapters = append(adapters, plugin)
+				}
+			}
+			if !slices.Contains(adapters, adapterName) && !v.isPlugin(adapterName) {
+				v.errorf(pointer, "unknown adapter '%s'%s", adapterName, didYouMean(adapterName, adapters))
+			}
+		},
+		model.KeyDirectory: func(pointer string, value interface{}) {
+			v.checkType(pointer, value, reflect.TypeOf(""))
+		},
+		model.KeyEnvFile: func(pointer string, value interface{}) {
+			v.checkType(pointer, value, stringListType)
+		},
+		model.KeyFragment: func(pointer string, value interface{}) {
+			v.eachEntry(pointer, value, func(entryPointer string, _ string, fragment interface{}) {
+				v.fragment(entryPointer, fragment)
+			})
+		},
+		model.KeyDefault: func(pointer string, value interface{}) {
+			v.profile(pointer, value, adapterName)
+		},
+	}
+	v.addHookHandlers(handlers)
+
+	v.eachKey(pointer, project, func(key string) bool {
+		if handler, ok := handlers[key]; ok {
+			handler(helper.JsonPointer(pointer, key), project[key])
+			return true
+		}
+		if !strings.HasPrefix(key, "$") {
+			v.profile(helper.JsonPointer(pointer, key), project[key], adapterName)
+			return true
+		}
+		return false
+	}, maps.Keys(handlers))
+}
+
+// profile validates a profile definition or the options of an environment for a profile.
+func (v *validator) profile(pointer string, value interface{}, adapter string) {
+	profile, ok := v.expectObject(pointer, value)
+	if !ok {
+		return
+	}
+
+	handlers := v.profileHandlers(adapter)
+	for _, mode := range runModes {
+		mode := mode
+		handlers[mode] = func(pointer string, value interface{}) {
+			if value == false {
+				return
+			}
+			if definition, ok := v.adapters[adapter]; ok {
+				if modes, _ := definition["modes"].(map[string]interface{}); modes[mode] == nil {
+					v.errorf(pointer, "adapter '%s' does not support the %s mode", adapter, mode)
+				}
+			}
+			if _, ok := value.(string); ok && adapter == model.AdapterCustom {
+				return
+			}
+			if _, ok := value.(map[string]interface{}); !ok {
+				expected := "an object or false"
+				if adapter == model.AdapterCustom {
+					expected = "a command, an object or false"
+				}
+				v.errorf(pointer, "expected %s but got %s", expected, jsonTypeName(value))
+				return
+			}
+			modeHandlers := v.profileHandlers(adapter)
+			v.acceptPluginOptions(adapter, value.(map[string]interface{}), modeHandlers)
+			v.options(pointer, value.(map[string]interface{}), append(slices.Clone(profileOptionTypes), adapterOptionTypes(adapter)...), modeHandlers)
+		}
+	}
+	v.acceptPluginOptions(adapter, profile, handlers)
+	v.options(pointer, profile, append(slices.Clone(profileOptionTypes), adapterOptionTypes(adapter)...), handlers)
+}
+
+// isPlugin reports whether an adapter plugin exists for an adapter, which is neither built in nor defined in the config.
+func (v *validator) isPlugin(adapter string) bool {
+	if exists, ok := v.plugins[adapter]; ok {
+		return exists
+	}
+	v.plugins[adapter] = v.config.adapterPlugin(adapter) != ""
+	return v.plugins[adapter]
+}
+
+// acceptPluginOptions adds handlers for all options of a profile, which are unknown to zwooc, if the adapter is
+// provided by a plugin, since the options are passed to the plugin as is.
+func (v *validator) acceptPluginOptions(adapter string, object map[string]interface{}, handlers map[string]keyHandler) {
+	if GetAdapter(adapter) != nil || v.adapters[adapter] != nil || !v.isPlugin(adapter) {
+		return
+	}
+	for key := range object {
+		if _, ok := handlers[key]; !ok && !hasOption(profileOptionTypes, key) {
+			handlers[key] = func(string, interface{}) {}
+		}
+	}
+}
+
+// profileHandlers returns the handlers of the options of a profile, which include the options of adapters
+// defined in the config.
+func (v *validator) profileHandlers(adapter string) map[string]keyHandler {
+	handlers := map[string]keyHandler{
+		"base": func(pointer string, value interface{}) {
+			if v.checkType(pointer, value, reflect.TypeOf("")) {
+				v.checkProfile(pointer, value.(string))
+			}
+		},
+		"includeFragments": v.checkFragments,
+	}
+	v.addHookHandlers(handlers)
+	if options, ok := v.adapters[adapter]["options"].(map[string]interface{}); ok {
+		for option := range options {
+			handlers[option] = v.checkAdapterOption
+		}
+	}
+	return handlers
+}
+
+// fragment validates a fragment definition, which is either a command or an object containing commands
+// for modes and profiles (like `build`, `dev` or `build:dev`) as well as options.
+func (v *validator) fragment(pointer string, value interface{}) {
+	if _, ok := value.(string); ok {
+		return
+	}
+	fragment, ok := value.(map[string]interface{})
+	if !ok {
+		v.errorf(pointer, "expected a command or an object but got %s", jsonTypeName(value))
+		return
+	}
+
+	handlers := map[string]keyHandler{}
+	v.addHookHandlers(handlers)
+	commandKeys := []string{model.KeyDefault}
+	commandKeys = append(commandKeys, runModes...)
+	commandKeys = append(commandKeys, maps.Keys(v.profiles)...)
+	commandKeys = append(commandKeys, v.compounds...)
+
+	for key := range fragment {
+		mode, profile, isCombined := strings.Cut(key, ":")
+		isCommandKey := slices.Contains(commandKeys, key) ||
+			(isCombined && IsValidRunMode(mode) && slices.Contains(commandKeys, profile))
+		if isCommandKey && !isFragmentOption(key) {
+			handlers[key] = func(pointer string, value interface{}) {
+				if _, ok := value.(string); !ok {
+					v.errorf(pointer, "expected a command but got %s", jsonTypeName(value))
+				}
+			}
+		}
+	}
+	v.options(pointer, fragment, fragmentOptionTypes, handlers, commandKeys...)
+}
+
+func (v *validator) hook(pointer string, value interface{}) {
+	hook, ok := v.expectObject(pointer, value)
+	if !ok {
+		return
+	}
+	v.options(pointer, hook, hookOptionTypes, map[string]keyHandler{
+		"fragments": v.checkFragments,
+		"profiles":  v.checkProfileTargets,
+	})
+}
+
+func (v *validator) compound(pointer string, value interface{}) {
+	compound, ok := v.expectObject(pointer, value)
+	if !ok {
+		return
+	}
+	handlers := map[string]keyHandler{
+		"profiles":         v.checkProfileTargets,
+		"includeFragments": v.checkFragments,
+	}
+	v.addHookHandlers(handlers)
+	v.options(pointer, compound, compoundOptionTypes, handlers)
+}
+
+// environmentProject validates the options of an environment for a project. Keys naming a profile of
+// the project contain options for this profile, all other keys are options for every profile.
+func (v *validator) environmentProject(pointer, projectKey string, value interface{}) {
+	options, ok := v.expectObject(pointer, value)
+	if !ok {
+		return
+	}
+	project, ok := v.config.raw[projectKey].(map[string]interface{})
+	if !ok || IsReservedKey(projectKey) {
+		v.errorf(pointer, "unknown project '%s'%s", projectKey, didYouMean(projectKey, v.projects()))
+		return
+	}
+	adapter, _ := project[model.KeyAdapter].(string)
+
+	// options for all profiles are validated like the options of a profile
+	projectOptions := map[string]interface{}{}
+	for _, key := range v.config.order.Keys(pointer, options) {
+		value := options[key]
+		if _, isProfile := project[key]; isProfile && !IsReservedKey(key) {
+			v.profile(helper.JsonPointer(pointer, key), value, adapter)
+		} else {
+			projectOptions[key] = value
+		}
+	}
+	v.profile(pointer, projectOptions, adapter)
+}
+
+// adapter validates the definition of an adapter in $adapters.
+func (v *validator) adapter(pointer, name string, value interface{}) {
+	definition, ok := v.expectObject(pointer, value)
+	if !ok {
+		return
+	}
+	if slices.Contains(model.Adapters, name) {
+		v.errorf(pointer, "adapter '%s' is built in and can't be redefined", name)
+	}
+	if _, ok := definition["command"]; !ok {
+		v.errorf(pointer, "missing command")
+	}
+
+	argStyles := []string{model.ArgStyleSpace, model.ArgStyleEquals}
+	v.options(pointer, definition, []reflect.Type{adapterType}, map[string]keyHandler{
+		"command": func(pointer string, value interface{}) {
+			if v.checkType(pointer, value, reflect.TypeOf("")) && strings.TrimSpace(value.(string)) == "" {
+				v.errorf(pointer, "the command must not be empty")
+			}
+		},
+		"modes": func(pointer string, value interface{}) {
+			if !v.checkType(pointer, value, reflect.TypeOf(map[string]string{})) {
+				return
+			}
+			for _, mode := range v.config.order.Keys(pointer, value.(map[string]interface{})) {
+				v.checkMode(helper.JsonPointer(pointer, mode), mode)
+			}
+		},
+		"argStyle": func(pointer string, value interface{}) {
+			if v.checkType(pointer, value, reflect.TypeOf("")) && !slices.Contains(argStyles, value.(string)) {
+				v.errorf(pointer, "invalid arg style '%s', expected one of %s", value, strings.Join(argStyles, ", "))
+			}
+		},
+	})
+}
+
+// checkAdapterOption validates the value of an option of a profile, which is passed as flag by an adapter defined in the config.
+func (v *validator) checkAdapterOption(pointer string, value interface{}) {
+	switch value := value.(type) {
+	case string, float64, bool:
+		return
+	case []interface{}:
+		for index, item := range value {
+			switch item.(type) {
+			case string, float64:
+			default:
+				v.errorf(helper.JsonPointer(pointer, fmt.Sprint(index)), "expected a string or a number but got %s", jsonTypeName(item))
+			}
+		}
+		return
+	}
+	v.errorf(pointer, "expected a string, a number, a boolean or a list but got %s", jsonTypeName(value))
+}
+
+func (v *validator) addHookHandlers(handlers map[string]keyHandler) {
+	for _, key := range hookKeys {
+		handlers[key] = v.hook
+	}
+}
+
+// options validates the keys of an object, which are either fields of one of the types or handled by a handler.
+func (v *validator) options(pointer string, object map[string]interface{}, types []reflect.Type, handlers map[string]keyHandler, suggestions ...string) {
+	known := maps.Keys(handlers)
+	for _, optionType := range types {
+		for i := 0; i < optionType.NumField(); i++ {
+			if tag := optionType.Field(i).Tag.Get("json"); tag != "" {
+				known = append(known, tag)
+			}
+		}
+	}
+
+	v.eachKey(pointer, object, func(key string) bool {
+		keyPointer := helper.JsonPointer(pointer, key)
+		if handler, ok := handlers[key]; ok {
+			handler(keyPointer, object[key])
+			return true
+		}
+		for _, optionType := range types {
+			for i := 0; i < optionType.NumField(); i++ {
+				if field := optionType.Field(i); field.Tag.Get("json") == key {
+					v.checkType(keyPointer, object[key], field.Type)
+					return true
+				}
+			}
+		}
+		return false
+	}, append(known, suggestions...))
+}
+
+// hasOption reports whether one of the option types has a field with the json key.
+func hasOption(types []reflect.Type, key string) bool {
+	for _, optionType := range types {
+		for i := 0; i < optionType.NumField(); i++ {
+			if optionType.Field(i).Tag.Get("json") == key {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// eachKey calls check for every key of the object in document order, keys check returns false for are unknown.
+func (v *validator) eachKey(pointer string, object map[string]interface{}, check func(key string) bool, known []string) {
+	for _, key := range v.config.order.Keys(pointer, object) {
+		if !check(key) {
+			v.unknownKey(helper.JsonPointer(pointer, key), key, known)
+		}
+	}
+}
+
+// eachEntry calls validate for every entry of an object.
+func (v *validator) eachEntry(pointer string, value interface{}, validate func(pointer string, key string, value interface{})) {
+	object, ok := v.expectObject(pointer, value)
+	if !ok {
+		return
+	}
+	for _, key := range v.config.order.Keys(pointer, object) {
+		validate(helper.JsonPointer(pointer, key), key, object[key])
+	}
+}
+
+// checkType reports whether the value matches the type of a field of the model, it reports all mismatches.
+func (v *validator) checkType(pointer string, value interface{}, fieldType reflect.Type) bool {
+	switch fieldType {
+	case stringListType:
+		if _, ok := value.(string); ok {
+			return true
+		}
+		return v.checkType(pointer, value, reflect.TypeOf([]string{}))
+	case profileTargetsType:
+		return v.checkProfileTargetTypes(pointer, value)
+	}
+
+	switch fieldType.Kind() {
+	case reflect.String:
+		if _, ok := value.(string); ok {
+			return true
+		}
+	case reflect.Bool:
+		if _, ok := value.(bool); ok {
+			return true
+		}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64, reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		if number, ok := value.(float64); ok && number == math.Trunc(number) {
+			return true
+		}
+	case reflect.Float32, reflect.Float64:
+		if _, ok := value.(float64); ok {
+			return true
+		}
+	case reflect.Slice:
+		if items, ok := value.([]interface{}); ok {
+			valid := true
+			for index, item := range items {
+				valid = v.checkType(helper.JsonPointer(pointer, fmt.Sprint(index)), item, fieldType.Elem()) && valid
+			}
+			return valid
+		}
+	case reflect.Map:
+		if object, ok := value.(map[string]interface{}); ok {
+			valid := true
+			for _, key := range v.config.order.Keys(pointer, object) {
+				valid = v.checkType(helper.JsonPointer(pointer, key), object[key], fieldType.Elem()) && valid
+			}
+			return valid
+		}
+	case reflect.Struct:
+		if object, ok := value.(map[string]interface{}); ok {
+			count := len(v.diagnostics)
+			v.options(pointer, object, []reflect.Type{fieldType}, nil)
+			return !slices.ContainsFunc(v.diagnostics[count:], func(d Diagnostic) bool { return d.Severity == SeverityError })
+		}
+	}
+	v.errorf(pointer, "expected %s but got %s", typeName(fieldType), jsonTypeName(value))
+	return false
+}
+
+// checkProfileTargetTypes validates profile targets, either in the map notation or the list notation.
+func (v *validator) checkProfileTargetTypes(pointer string, value interface{}) bool {
+	switch value.(type) {
+	case map[string]interface{}:
+		return v.checkType(pointer, value, reflect.TypeOf(map[string]string{}))
+	case []interface{}:
+		return v.checkType(pointer, value, reflect.TypeOf([]model.ProfileTarget{}))
+	}
+	v.errorf(pointer, "expected an object or a list of profile targets but got %s", jsonTypeName(value))
+	return false
+}
+
+func (v *validator) checkProfileTargets(pointer string, value interface{}) {
+	if !v.checkProfileTargetTypes(pointer, value) {
+		return
+	}
+	switch value := value.(type) {
+	case map[string]interface{}:
+		for _, profile := range v.config.order.Keys(pointer, value) {
+			targetPointer := helper.JsonPointer(pointer, profile)
+			v.checkProfile(targetPointer, profile)
+			v.checkMode(targetPointer, value[profile].(string))
+		}
+	case []interface{}:
+		for index, item := range value {
+			target := item.(map[string]interface{})
+			targetPointer := helper.JsonPointer(pointer, fmt.Sprint(index))
+			profile, hasProfile := target["profile"].(string)
+			mode, hasMode := target["mode"].(string)
+			if !hasProfile || !hasMode {
+				v.errorf(targetPointer, "a profile target requires a 'profile' and a 'mode'")
+			}
+			if hasProfile {
+				v.checkProfile(helper.JsonPointer(targetPointer, "profile"), profile)
+			}
+			if hasMode {
+				v.checkMode(helper.JsonPointer(targetPointer, "mode"), mode)
+			}
+		}
+	}
+}
+
+func (v *validator) checkFragments(pointer string, value interface{}) {
+	if !v.checkType(pointer, value, reflect.TypeOf([]string{})) {
+		return
+	}
+	for index, item := range value.([]interface{}) {
+		reference := item.(string)
+		key, _, _ := normalizeFragmentKey(reference)
+		if !slices.Contains(v.fragments, key) && !slices.Contains(v.fragments, reference) {
+			v.errorf(helper.JsonPointer(pointer, fmt.Sprint(index)), "unknown fragment '%s'%s", reference, didYouMean(key, v.fragments))
+		}
+	}
+}
+
+func (v *validator) checkProfile(pointer, profile string) {
+	if _, ok := v.profiles[profile]; !ok {
+		v.errorf(pointer, "unknown profile '%s'%s", profile, didYouMean(profile, maps.Keys(v.profiles)))
+	}
+}
+
+func (v *validator) checkMode(pointer, mode string) {
+	if !IsValidRunMode(mode) {
+		v.errorf(pointer, "invalid run mode '%s'%s", mode, didYouMean(mode, runModes))
+	}
+}
+
+func (v *validator) expectObject(pointer string, value interface{}) (map[string]interface{}, bool) {
+	object, ok := value.(map[string]interface{})
+	if !ok {
+		v.errorf(pointer, "expected an object but got %s", jsonTypeName(value))
+	}
+	return object, ok
+}
+
+func (v *validator) unknownKey(pointer, key string, known []string) {
+	v.warnf(pointer, "unknown key '%s'%s", key, didYouMean(key, known))
+}
+
+func (v *validator) projects() []string {
+	projects := []string{}
+	for key, value := range v.config.raw {
+		if _, ok := value.(map[string]interface{}); ok && !IsReservedKey(key) {
+			projects = append(projects, key)
+		}
+	}
+	return projects
+}
+
+func (v *validator) errorf(pointer, format string, args ...interface{}) {
+	v.report(SeverityError, pointer, fmt.Sprintf(format, args...))
+}
+
+func (v *validator) warnf(pointer, format string, args ...interface{}) {
+	v.report(SeverityWarning, pointer, fmt.Sprintf(format, args...))
+}
+
+func (v *validator) report(severity Severity, pointer, message string) {
+	location := v.config.location(pointer)
+	line, column := location.Position()
+	v.diagnostics = append(v.diagnostics, Diagnostic{
+		Severity: severity,
+		Path:     pointer,
+		File:     location.file.name,
+		Line:     line,
+		Column:   column,
+		Message:  message,
+	})
+}
+
+func didYouMean(value string, candidates []string) string {
+	// sort the candidates to get the same suggestion for equally similar candidates
+	candidates = slices.Clone(candidates)
+	slices.Sort(candidates)
+	if suggestion := helper.Suggest(value, candidates); suggestion != "" {
+		return fmt.Sprintf(", did you mean '%s'?", suggestion)
+	}
+	return ""
+}
+
+// typeName describes the json representation of a type of the model.
+func typeName(fieldType reflect.Type) string {
+	switch fieldType.Kind() {
+	case reflect.String:
+		return "a string"
+	case reflect.Bool:
+		return "a boolean"
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64, reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return "an integer"
+	case reflect.Float32, reflect.Float64:
+		return "a number"
+	case reflect.Slice:
+		return "a list"
+	}
+	return "an object"
+}
+
+// jsonTypeName describes the type of a value decoded from json.
+func jsonTypeName(value interface{}) string {
+	switch value := value.(type) {
+	case string:
+		return "a string"
+	case bool:
+		return "a boolean"
+	case float64:
+		if value == math.Trunc(value) {
+			return "an integer"
+		}
+		return "a number"
+	case []interface{}:
+		return "a list"
+	case map[string]interface{}:
+		return "an object"
+	}
+	return "null"
+}
@@ -0,0 +1,134 @@
+package config
+
+import (
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/zwoo-hq/zwooc/pkg/model"
+)
+
+func TestValidate(t *testing.T) {
+	tests := []struct {
+		name   string
+		config string
+		want   []string
+	}{
+		{"valid config", `{
+			"$schema": "./zwooc.schema.json",
+			"$options": {"maxConcurrency": 2},
+			"web": {
+				"$adapter": "vite-yarn",
+				"$fragments": {"lint": {"$default": "lint", "build:dev": "lint --fix", "stopSignal": "SIGINT"}},
+				"dev": {"mode": "dev", "build": {"args": {"outDir": "dist"}, "$pre": {"fragments": ["lint:build"]}}, "watch": false},
+				"prod": {"base": "dev", "includeFragments": ["lint"], "readyWhen": {"tcp": "localhost:80"}}
+			},
+			"api": {"$adapter": "custom", "$envFile": ".env", "api": {"build": "make", "env": ["A=1"]}},
+			"$compounds": {"all": {"profiles": {"dev": "build"}, "$post": {"profiles": [{"profile": "api", "mode": "build"}]}}},
+			"$environments": {"staging": {"web": {"env": ["A=2"], "dev": {"build": {"args": {"mode": "staging"}}}}}}
+		}`, []string{}},
+		{"missing adapter", `{"web": {"dev": {}}}`, []string{
+			"zwooc.config.json:1:2: error: /web: missing $adapter (one of vite-yarn, vite-npm, vite-pnpm, tauri-yarn, tauri-npm, tauri-pnpm, dotnet, custom)",
+		}},
+		{"unknown adapter", `{"web": {"$adapter": "vite-yran"}}`, []string{
+			"zwooc.config.json:1:10: error: /web/$adapter: unknown adapter 'vite-yran', did you mean 'vite-yarn'?",
+		}},
+		{"wrong types", "{\n\"web\": {\n\"$adapter\": \"custom\",\n\"$dir\": 1,\n\"dev\": {\"env\": \"A=1\", \"args\": {\"port\": 80}, \"restart\": {\"maxAttempts\": 1.5}}\n}\n}", []string{
+			"zwooc.config.json:4:1: error: /web/$dir: expected a string but got an integer",
+			"zwooc.config.json:5:9: error: /web/dev/env: expected a list but got a string",
+			"zwooc.config.json:5:32: error: /web/dev/args/port: expected a string but got an integer",
+			"zwooc.config.json:5:57: error: /web/dev/restart/maxAttempts: expected an integer but got a number",
+		}},
+		{"definitions which are not objects", `{"web": {"$adapter": "custom", "dev": "make"}, "$fragments": {"lint": 1}, "$compounds": {"all": true}}`, []string{
+			"zwooc.config.json:1:32: error: /web/dev: expected an object but got a string",
+			"zwooc.config.json:1:63: error: /$fragments/lint: expected a command or an object but got an integer",
+			"zwooc.config.json:1:90: error: /$compounds/all: expected an object but got a boolean",
+		}},
+		{"modes", `{"web": {"$adapter": "vite-npm", "dev": {"biuld": {}, "run": true, "watch": "vite"}}, "api": {"$adapter": "custom", "api": {"run": "make"}}}`, []string{
+			"zwooc.config.json:1:42: warning: /web/dev/biuld: unknown key 'biuld', did you mean 'build'?",
+			"zwooc.config.json:1:55: error: /web/dev/run: expected an object or false but got a boolean",
+			"zwooc.config.json:1:68: error: /web/dev/watch: expected an object or false but got a string",
+		}},
+		{"unknown keys", `{"$fragment": {}, "web": {"$adapter": "custom", "$dirr": "x", "dev": {"envFile": ".env", "stopSignl": "SIGINT"}}}`, []string{
+			"zwooc.config.json:1:2: warning: /$fragment: unknown key '$fragment', did you mean '$fragments'?",
+			"zwooc.config.json:1:49: warning: /web/$dirr: unknown key '$dirr', did you mean '$dir'?",
+			"zwooc.config.json:1:90: warning: /web/dev/stopSignl: unknown key 'stopSignl', did you mean 'stopSignal'?",
+		}},
+		{"references", `{
+			"web": {"$adapter": "custom", "dev": {"base": "prod", "includeFragments": ["lnt"], "$pre": {"profiles": {"dev": "buidl", "api": "build"}}}},
+			"$fragments": {"lint": "lint"},
+			"$compounds": {"all": {"profiles": [{"profile": "dev"}], "includeFragments": ["lint:build"]}},
+			"$environments": {"staging": {"wbe": {}}}
+		}`, []string{
+			"zwooc.config.json:2:42: error: /web/dev/base: unknown profile 'prod'",
+			"zwooc.config.json:2:79: error: /web/dev/includeFragments/0: unknown fragment 'lnt', did you mean 'lint'?",
+			"zwooc.config.json:2:109: error: /web/dev/$pre/profiles/dev: invalid run mode 'buidl', did you mean 'build'?",
+			"zwooc.config.json:2:125: error: /web/dev/$pre/profiles/api: unknown profile 'api'",
+			"zwooc.config.json:4:40: error: /$compounds/all/profiles/0: a profile target requires a 'profile' and a 'mode'",
+			"zwooc.config.json:5:34: error: /$environments/staging/wbe: unknown project 'wbe', did you mean 'web'?",
+		}},
+		{"fragment commands", `{"web": {"$adapter": "custom", "dev": {}}, "$fragments": {"lint": {"build": 1, "dev": "x", "build:dev": "y", "watch:prod": "z"}}}`, []string{
+			"zwooc.config.json:1:68: error: /$fragments/lint/build: expected a command but got an integer",
+			"zwooc.config.json:1:110: warning: /$fragments/lint/watch:prod: unknown key 'watch:prod'",
+		}},
+		{"declarative adapters", `{
+			"$adapters": {"go": {"command": "go", "modes": {"build": "build", "biuld": "x"}, "options": {"race": "-race"}, "argStyle": "colon"}, "dotnet": {"command": "dotnet"}, "make": {}},
+			"api": {"$adapter": "go", "api": {"race": {}, "watch": {}}},
+			"cli": {"$adapter": "og"}
+		}`, []string{
+			"zwooc.config.json:2:70: error: /$adapters/go/modes/biuld: invalid run mode 'biuld', did you mean 'build'?",
+			"zwooc.config.json:2:115: error: /$adapters/go/argStyle: invalid arg style 'colon', expected one of space, equals",
+			"zwooc.config.json:2:137: error: /$adapters/dotnet: adapter 'dotnet' is built in and can't be redefined",
+			"zwooc.config.json:2:170: error: /$adapters/make: missing command",
+			"zwooc.config.json:3:38: error: /api/api/race: expected a string, a number, a boolean or a list but got an object",
+			"zwooc.config.json:3:50: error: /api/api/watch: adapter 'go' does not support the watch mode",
+			"zwooc.config.json:4:12: error: /cli/$adapter: unknown adapter 'og', did you mean 'go'?",
+		}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := writeConfigFiles(t, map[string]string{model.ConfigFile: tt.config})
+			diagnostics, err := Validate(filepath.Join(dir, model.ConfigFile))
+			if err != nil {
+				t.Fatalf("Validate() error = %v", err)
+			}
+			got := []string{}
+			for _, diagnostic := range diagnostics {
+				got = append(got, diagnostic.String())
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Validate() =\n%v\nwant\n%v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestValidate_ReportsIncludedFiles(t *testing.T) {
+	dir := writeConfigFiles(t, map[string]string{
+		model.ConfigFile:      `{"$include": "web.json"}`,
+		"web.json":            "{\n  \"web\": {\"$adapter\": \"custom\", \"dev\": {\"tty\": \"yes\"}}\n}",
+		model.LocalConfigFile: "{\n  \"web\": {\"dev\": {\"env\": [1]}}\n}",
+	})
+	diagnostics, err := Validate(filepath.Join(dir, model.ConfigFile))
+	if err != nil {
+		t.Fatalf("Validate() error = %v", err)
+	}
+	got := []string{}
+	for _, diagnostic := range diagnostics {
+		got = append(got, diagnostic.String())
+	}
+	want := []string{
+		"web.json:2:41: error: /web/dev/tty: expected a boolean but got a string",
+		"zwooc.local.json:2:27: error: /web/dev/env/0: expected a string but got an integer",
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Validate() =\n%v\nwant\n%v", got, want)
+	}
+
+	if _, err := Load(filepath.Join(dir, model.ConfigFile)); err == nil {
+		t.Errorf("Load() expected a validation error")
+	} else if validationError, ok := err.(*ValidationError); !ok || len(validationError.Diagnostics) != 2 {
+		t.Errorf("Load() error = %v, want a validation error", err)
+	}
+}
@@ -0,0 +1,270 @@
+package helper
+
+import (
+	"fmt"
+	"strings"
+)
+
+// A DotenvVariable is a variable defined in a dotenv file.
+type DotenvVariable struct {
+	Name  string
+	Value string
+}
+
+// ParseDotenv parses the content of a dotenv file. Lines are of the form `NAME=value` and may be prefixed
+// by `export`. Values may be single quoted (taken literally), double quoted (supporting escape sequences
+// and line breaks) or unquoted, unquoted values end at a ` #` comment. Variables of the form $NAME,
+// ${NAME} and ${NAME:-default} are expanded in unquoted and double quoted values, they are looked up in
+// the variables defined before in the file first and in lookup afterwards.
+func ParseDotenv(content string, lookup func(name string) (string, bool)) ([]DotenvVariable, error) {
+	p := dotenvParser{
+		content: strings.ReplaceAll(content, "\r\n", "\n"),
+		line:    1,
+		defined: map[string]string{},
+		lookup:  lookup,
+	}
+	return p.parse()
+}
+
+type dotenvParser struct {
+	content string
+	pos     int
+	line    int
+	defined map[string]string
+	lookup  func(name string) (string, bool)
+}
+
+func (p *dotenvParser) parse() ([]DotenvVariable, error) {
+	variables := []DotenvVariable{}
+	for {
+		p.skip(" \t\n")
+		if p.done() {
+			return variables, nil
+		}
+		if p.peek() == '#' {
+			p.skipLine()
+			continue
+		}
+
+		name := p.name()
+		if name == "export" && p.peekAny(" \t") {
+			p.skip(" \t")
+			name = p.name()
+		}
+		if name == "" {
+			return nil, p.errorf("expected a variable name")
+		}
+		p.skip(" \t")
+		if p.done() || p.peek() != '=' {
+			return nil, p.errorf("expected '=' after '%s'", name)
+		}
+		p.pos++
+		p.skip(" \t")
+
+		value, err := p.value()
+		if err != nil {
+			return nil, err
+		}
+		p.defined[name] = value
+		variables = append(variables, DotenvVariable{Name: name, Value: value})
+	}
+}
+
+func (p *dotenvParser) value() (string, error) {
+	if p.done() {
+		return "", nil
+	}
+
+	switch p.peek() {
+	case '\'':
+		p.pos++
+		end := strings.IndexByte(p.content[p.pos:], '\'')
+		if end < 0 {
+			return "", p.errorf("unterminated single quoted value")
+		}
+		value := p.content[p.pos : p.pos+end]
+		p.advance(end + 1)
+		return value, p.endOfLine()
+	case '"':
+		p.pos++
+		value, err := p.doubleQuoted()
+		if err != nil {
+			return "", err
+		}
+		return value, p.endOfLine()
+	}
+
+	end := strings.IndexByte(p.content[p.pos:], '\n')
+	if end < 0 {
+		end = len(p.content) - p.pos
+	}
+	raw := p.content[p.pos : p.pos+end]
+	p.pos += end
+	if comment := strings.Index(raw, " #"); comment >= 0 {
+		raw = raw[:comment]
+	} else if comment := strings.Index(raw, "\t#"); comment >= 0 {
+		raw = raw[:comment]
+	}
+	return p.expand(strings.TrimSpace(raw)), nil
+}
+
+func (p *dotenvParser) doubleQuoted() (string, error) {
+	raw := strings.Builder{}
+	for !p.done() {
+		char := p.content[p.pos]
+		switch char {
+		case '"':
+			p.pos++
+			return p.expand(raw.String()), nil
+		case '\\':
+			if p.pos+1 >= len(p.content) {
+				break
+			}
+			p.pos++
+			switch escaped := p.content[p.pos]; escaped {
+			case 'n':
+				raw.WriteByte('\n')
+			case 'r':
+				raw.WriteByte('\r')
+			case 't':
+				raw.WriteByte('\t')
+			case '$':
+				// keep the escape so that the variable is not expanded
+				raw.WriteString("\\$")
+			default:
+				raw.WriteByte(escaped)
+			}
+			p.pos++
+			continue
+		case '\n':
+			p.line++
+		}
+		raw.WriteByte(char)
+		p.pos++
+	}
+	return "", p.errorf("unterminated double quoted value")
+}
+
+// expand replaces all variables in the value, escaped dollar signs (\$) are kept literally.
+func (p *dotenvParser) expand(value string) string {
+	result := strings.Builder{}
+	for i := 0; i < len(value); i++ {
+		if value[i] == '\\' && i+1 < len(value) && value[i+1] == '$' {
+			result.WriteByte('$')
+			i++
+			continue
+		}
+		if value[i] != '$' || i+1 >= len(value) {
+			result.WriteByte(value[i])
+			continue
+		}
+
+		if value[i+1] == '{' {
+			end := strings.IndexByte(value[i:], '}')
+			if end < 0 {
+				result.WriteByte(value[i])
+				continue
+			}
+			name, fallback, hasFallback := strings.Cut(value[i+2:i+end], ":-")
+			resolved := p.resolve(name)
+			if resolved == "" && hasFallback {
+				resolved = fallback
+			}
+			result.WriteString(resolved)
+			i += end
+			continue
+		}
+
+		length := nameLength(value[i+1:])
+		if length == 0 {
+			result.WriteByte(value[i])
+			continue
+		}
+		result.WriteString(p.resolve(value[i+1 : i+1+length]))
+		i += length
+	}
+	return result.String()
+}
+
+func (p *dotenvParser) resolve(name string) string {
+	if value, ok := p.defined[name]; ok {
+		return value
+	}
+	if p.lookup != nil {
+		value, _ := p.lookup(name)
+		return value
+	}
+	return ""
+}
+
+func (p *dotenvParser) name() string {
+	length := nameLength(p.content[p.pos:])
+	name := p.content[p.pos : p.pos+length]
+	p.pos += length
+	return name
+}
+
+// nameLength returns the length of the variable name at the start of value.
+func nameLength(value string) int {
+	for i, char := range value {
+		isLetter := (char >= 'a' && char <= 'z') || (char >= 'A' && char <= 'Z') || char == '_'
+		isDigit := char >= '0' && char <= '9'
+		if !isLetter && (!isDigit || i == 0) {
+			return i
+		}
+	}
+	return len(value)
+}
+
+// endOfLine skips the remainder of the line after a quoted value, which may only contain a comment.
+func (p *dotenvParser) endOfLine() error {
+	p.skip(" \t")
+	if p.done() || p.peek() == '\n' {
+		return nil
+	}
+	if p.peek() == '#' {
+		p.skipLine()
+		return nil
+	}
+	return p.errorf("unexpected character '%c' after quoted value", p.peek())
+}
+
+func (p *dotenvParser) done() bool {
+	return p.pos >= len(p.content)
+}
+
+func (p *dotenvParser) peek() byte {
+	return p.content[p.pos]
+}
+
+func (p *dotenvParser) peekAny(chars string) bool {
+	return !p.done() && strings.IndexByte(chars, p.peek()) >= 0
+}
+
+func (p *dotenvParser) skip(chars string) {
+	for p.peekAny(chars) {
+		if p.peek() == '\n' {
+			p.line++
+		}
+		p.pos++
+	}
+}
+
+func (p *dotenvParser) skipLine() {
+	end := strings.IndexByte(p.content[p.pos:], '\n')
+	if end < 0 {
+		p.pos = len(p.content)
+		return
+	}
+	p.pos += end
+}
+
+// advance moves forward by n bytes while counting the passed line breaks.
+func (p *dotenvParser) advance(n int) {
+	p.line += strings.Count(p.content[p.pos:p.pos+n], "\n")
+	p.pos += n
+}
+
+func (p *dotenvParser) errorf(format string, args ...interface{}) error {
+	return fmt.Errorf("line %d: %s", p.line, fmt.Sprintf(format, args...))
+}
@@ -0,0 +1,56 @@
+package helper
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestParseDotenv(t *testing.T) {
+	lookup := func(name string) (string, bool) {
+		if name == "HOME" {
+			return "/home/user", true
+		}
+		return "", false
+	}
+
+	tests := []struct {
+		name    string
+		content string
+		want    []DotenvVariable
+		wantErr string
+	}{
+		{"empty", "", []DotenvVariable{}, ""},
+		{"comments and blank lines", "# comment\n\nA=1\n  # indented comment\n", []DotenvVariable{{"A", "1"}}, ""},
+		{"export prefix", "export A=1\nexport=2", []DotenvVariable{{"A", "1"}, {"export", "2"}}, ""},
+		{"whitespace", "  A = value with spaces  \r\nB=", []DotenvVariable{{"A", "value with spaces"}, {"B", ""}}, ""},
+		{"inline comment", "A=value # comment\nB=a#b", []DotenvVariable{{"A", "value"}, {"B", "a#b"}}, ""},
+		{"single quotes", "A='$HOME \\n # not a comment' # comment", []DotenvVariable{{"A", "$HOME \\n # not a comment"}}, ""},
+		{"double quotes", `A="line\nnext \"quoted\" \$HOME"`, []DotenvVariable{{"A", "line\nnext \"quoted\" $HOME"}}, ""},
+		{"multiline", "A=\"first\nsecond\"\nB=2", []DotenvVariable{{"A", "first\nsecond"}, {"B", "2"}}, ""},
+		{"expansion", "A=${HOME}/a\nB=$A/b\nC=\"${MISSING:-fallback} $MISSING.\"", []DotenvVariable{{"A", "/home/user/a"}, {"B", "/home/user/a/b"}, {"C", "fallback ."}}, ""},
+		{"redefinition", "A=1\nA=$A$A", []DotenvVariable{{"A", "1"}, {"A", "11"}}, ""},
+		{"missing equals", "A=1\nB", nil, "line 2: expected '=' after 'B'"},
+		{"invalid name", "1A=1", nil, "line 1: expected a variable name"},
+		{"unterminated quote", "A=\"value\n", nil, "unterminated double quoted value"},
+		{"text after quote", "A='value' rest", nil, "line 1: unexpected character 'r' after quoted value"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseDotenv(tt.content, lookup)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("ParseDotenv() error = %v, want %s", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("ParseDotenv() error = %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ParseDotenv() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
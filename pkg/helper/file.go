@@ -1,12 +1,17 @@
 package helper
 
 import (
+	"errors"
 	"fmt"
+	"io/fs"
 	"os"
 	"path/filepath"
+	"strings"
 )
 
-func FindFile(filename string) (string, error) {
+// FindFile searches the working directory and its parents for a file with one of the names and returns
+// the path of the file closest to the working directory.
+func FindFile(filenames ...string) (string, error) {
 	// start searching in the current working directory
 	currentDir, err := os.Getwd()
 	if err != nil {
@@ -16,9 +21,9 @@ func FindFile(filename string) (string, error) {
 	// recursively search for the file by going upwards one level
 	for {
 		// check if the file exists in the current directory
-		filePath := filepath.Join(currentDir, filename)
-		if _, err := os.Stat(filePath); err == nil {
-			return filePath, nil
+		filePath, err := FindFileIn(currentDir, filenames...)
+		if err != nil || filePath != "" {
+			return filePath, err
 		}
 
 		// go up one level
@@ -30,5 +35,26 @@ func FindFile(filename string) (string, error) {
 		currentDir = parentDir
 	}
 
-	return "", fmt.Errorf("file not found: '%s'", filename)
+	return "", fmt.Errorf("file not found: '%s'", strings.Join(filenames, "' or '"))
+}
+
+// FindFileIn returns the path of the file in dir with one of the names. The path is empty if there is no
+// such file, it's an error if there are files with multiple of the names.
+func FindFileIn(dir string, filenames ...string) (string, error) {
+	found := []string{}
+	for _, filename := range filenames {
+		if _, err := os.Stat(filepath.Join(dir, filename)); err == nil {
+			found = append(found, filename)
+		} else if !errors.Is(err, fs.ErrNotExist) {
+			return "", err
+		}
+	}
+
+	switch len(found) {
+	case 0:
+		return "", nil
+	case 1:
+		return filepath.Join(dir, found[0]), nil
+	}
+	return "", fmt.Errorf("found both '%s' in '%s', only one of them may exist", strings.Join(found, "' and '"), dir)
 }
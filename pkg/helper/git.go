@@ -0,0 +1,71 @@
+package helper
+
+import (
+	"bytes"
+	"fmt"
+	"os/exec"
+	"path/filepath"
+	"slices"
+	"strings"
+)
+
+// ChangedFiles returns the absolute paths of all files in the git repository containing dir that changed
+// since the merge base of base and HEAD, including uncommitted and untracked files. If base is empty, only
+// uncommitted and untracked changes are returned.
+func ChangedFiles(dir, base string) ([]string, error) {
+	root, err := git(dir, "rev-parse", "--show-toplevel")
+	if err != nil {
+		return nil, err
+	}
+	root = strings.TrimSpace(root)
+
+	since := "HEAD"
+	if base != "" {
+		mergeBase, err := git(dir, "merge-base", base, "HEAD")
+		if err != nil {
+			return nil, err
+		}
+		since = strings.TrimSpace(mergeBase)
+	}
+
+	// comparing the working tree with the merge base includes both committed and uncommitted changes
+	changed, err := git(dir, "diff", "--name-only", "--no-renames", "-z", since)
+	if err != nil {
+		return nil, err
+	}
+	// ls-files only lists files below its working directory
+	untracked, err := git(root, "ls-files", "--others", "--exclude-standard", "-z")
+	if err != nil {
+		return nil, err
+	}
+
+	files := []string{}
+	for _, file := range strings.Split(changed+untracked, "\x00") {
+		if file != "" {
+			files = append(files, filepath.Join(root, filepath.FromSlash(file)))
+		}
+	}
+	slices.Sort(files)
+	return slices.Compact(files), nil
+}
+
+func git(dir string, args ...string) (string, error) {
+	cmd := exec.Command("git", args...)
+	cmd.Dir = dir
+	stderr := bytes.Buffer{}
+	cmd.Stderr = &stderr
+	out, err := cmd.Output()
+	if err != nil {
+		return "", fmt.Errorf("git %s failed: %s", strings.Join(args, " "), strings.TrimSpace(stderr.String()))
+	}
+	return string(out), nil
+}
+
+// GitRevision returns the hash of the commit checked out in the git repository containing dir.
+func GitRevision(dir string) (string, error) {
+	sha, err := git(dir, "rev-parse", "HEAD")
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(sha), nil
+}
@@ -0,0 +1,66 @@
+package helper
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"slices"
+	"testing"
+)
+
+func TestChangedFiles(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git is not installed")
+	}
+
+	dir, _ := filepath.EvalSymlinks(t.TempDir())
+	run := func(args ...string) {
+		cmd := exec.Command("git", args...)
+		cmd.Dir = dir
+		cmd.Env = append(os.Environ(), "GIT_AUTHOR_NAME=test", "GIT_AUTHOR_EMAIL=test@test", "GIT_COMMITTER_NAME=test", "GIT_COMMITTER_EMAIL=test@test")
+		if out, err := cmd.CombinedOutput(); err != nil {
+			t.Fatalf("git %v failed: %s", args, out)
+		}
+	}
+	write := func(name, content string) {
+		os.MkdirAll(filepath.Dir(filepath.Join(dir, name)), 0755)
+		os.WriteFile(filepath.Join(dir, name), []byte(content), 0644)
+	}
+
+	run("init", "-q", "-b", "main")
+	write("a/file.txt", "a")
+	write("b/file.txt", "b")
+	run("add", "-A")
+	run("commit", "-q", "-m", "initial")
+	run("checkout", "-q", "-b", "feature")
+	write("a/file.txt", "changed")
+	run("commit", "-q", "-am", "change a")
+	write("b/file.txt", "uncommitted")
+	write("c/new.txt", "untracked")
+
+	tests := []struct {
+		name     string
+		base     string
+		expected []string
+	}{
+		{"uncommitted changes", "", []string{"b/file.txt", "c/new.txt"}},
+		{"changes since base", "main", []string{"a/file.txt", "b/file.txt", "c/new.txt"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			files, err := ChangedFiles(filepath.Join(dir, "a"), tt.base)
+			if err != nil {
+				t.Fatalf("ChangedFiles() error = %v", err)
+			}
+			expected := MapTo(tt.expected, func(file string) string { return filepath.Join(dir, file) })
+			if !slices.Equal(files, expected) {
+				t.Errorf("ChangedFiles() = %v, want %v", files, expected)
+			}
+		})
+	}
+
+	if _, err := ChangedFiles(dir, "unknown-ref"); err == nil {
+		t.Errorf("expected an error for an unknown base ref")
+	}
+}
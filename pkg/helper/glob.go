@@ -0,0 +1,134 @@
+package helper
+
+import (
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"slices"
+	"strings"
+)
+
+// Glob returns the paths (relative to dir and slash separated) of all files in dir matching at least one
+// of the patterns. Patterns use the syntax of path.Match extended by ** matching any amount of directories,
+// patterns starting with ! exclude the files they match. The returned paths are sorted.
+func Glob(dir string, patterns []string) ([]string, error) {
+	includes := []string{}
+	excludes := []string{}
+	for _, pattern := range patterns {
+		if strings.HasPrefix(pattern, "!") {
+			excludes = append(excludes, cleanPattern(pattern[1:]))
+		} else {
+			includes = append(includes, cleanPattern(pattern))
+		}
+	}
+
+	// directories excluded as a whole are not walked at all
+	excludedDirs := []string{}
+	for _, pattern := range excludes {
+		if strings.HasSuffix(pattern, "/**") {
+			excludedDirs = append(excludedDirs, strings.TrimSuffix(pattern, "/**"))
+		}
+	}
+
+	found := map[string]bool{}
+	for _, pattern := range includes {
+		if _, err := path.Match(pattern, ""); err != nil {
+			return nil, err
+		}
+
+		if !hasWildcards(pattern) {
+			if info, err := os.Stat(filepath.Join(dir, filepath.FromSlash(pattern))); err == nil && !info.IsDir() {
+				found[pattern] = true
+			}
+			continue
+		}
+
+		root := staticPrefix(pattern)
+		err := filepath.WalkDir(filepath.Join(dir, filepath.FromSlash(root)), func(file string, entry fs.DirEntry, err error) error {
+			if err != nil {
+				if os.IsNotExist(err) {
+					return nil
+				}
+				return err
+			}
+
+			relative, err := filepath.Rel(dir, file)
+			if err != nil {
+				return err
+			}
+			relative = filepath.ToSlash(relative)
+			if entry.IsDir() {
+				if Some(excludedDirs, func(excluded string) bool { return MatchGlob(excluded, relative) }) {
+					return filepath.SkipDir
+				}
+				return nil
+			}
+			if MatchGlob(pattern, relative) {
+				found[relative] = true
+			}
+			return nil
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	files := []string{}
+	for file := range found {
+		if !Some(excludes, func(pattern string) bool { return MatchGlob(pattern, file) }) {
+			files = append(files, file)
+		}
+	}
+	slices.Sort(files)
+	return files, nil
+}
+
+// MatchGlob reports whether the slash separated name matches the pattern, ** matches any amount of directories.
+func MatchGlob(pattern, name string) bool {
+	return matchSegments(strings.Split(pattern, "/"), strings.Split(name, "/"))
+}
+
+func matchSegments(pattern, name []string) bool {
+	if len(pattern) == 0 {
+		return len(name) == 0
+	}
+
+	if pattern[0] == "**" {
+		for i := 0; i <= len(name); i++ {
+			if matchSegments(pattern[1:], name[i:]) {
+				return true
+			}
+		}
+		return false
+	}
+
+	if len(name) == 0 {
+		return false
+	}
+	if ok, _ := path.Match(pattern[0], name[0]); !ok {
+		return false
+	}
+	return matchSegments(pattern[1:], name[1:])
+}
+
+func hasWildcards(pattern string) bool {
+	return strings.ContainsAny(pattern, "*?[\\")
+}
+
+func cleanPattern(pattern string) string {
+	return strings.TrimPrefix(path.Clean(filepath.ToSlash(pattern)), "./")
+}
+
+// staticPrefix returns the leading directories of the pattern that don't contain any wildcards.
+func staticPrefix(pattern string) string {
+	segments := strings.Split(pattern, "/")
+	prefix := []string{}
+	for _, segment := range segments[:len(segments)-1] {
+		if hasWildcards(segment) {
+			break
+		}
+		prefix = append(prefix, segment)
+	}
+	return strings.Join(prefix, "/")
+}
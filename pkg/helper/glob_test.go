@@ -0,0 +1,71 @@
+package helper
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestMatchGlob(t *testing.T) {
+	tests := []struct {
+		pattern string
+		name    string
+		want    bool
+	}{
+		{"*.go", "main.go", true},
+		{"*.go", "pkg/main.go", false},
+		{"src/*.ts", "src/index.ts", true},
+		{"src/**", "src/a/b/index.ts", true},
+		{"src/**/*.ts", "src/index.ts", true},
+		{"src/**/*.ts", "src/a/b/index.ts", true},
+		{"src/**/*.ts", "lib/index.ts", false},
+		{"**/*.json", "package.json", true},
+		{"**/*.json", "a/b/c.json", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.pattern+" "+tt.name, func(t *testing.T) {
+			if got := MatchGlob(tt.pattern, tt.name); got != tt.want {
+				t.Errorf("MatchGlob(%q, %q) = %v, want %v", tt.pattern, tt.name, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestGlob(t *testing.T) {
+	dir := t.TempDir()
+	for _, file := range []string{"package.json", "src/index.ts", "src/lib/util.ts", "src/lib/util.test.ts", "node_modules/dep/index.js"} {
+		path := filepath.Join(dir, filepath.FromSlash(file))
+		os.MkdirAll(filepath.Dir(path), 0755)
+		os.WriteFile(path, []byte(file), 0644)
+	}
+
+	tests := []struct {
+		name     string
+		patterns []string
+		want     []string
+	}{
+		{"should match exact files", []string{"package.json", "missing.json"}, []string{"package.json"}},
+		{"should match recursive patterns", []string{"src/**/*.ts"}, []string{"src/index.ts", "src/lib/util.test.ts", "src/lib/util.ts"}},
+		{"should exclude files", []string{"src/**", "!**/*.test.ts"}, []string{"src/index.ts", "src/lib/util.ts"}},
+		{"should exclude directories", []string{"**/*.js", "**/*.json", "!node_modules/**"}, []string{"package.json"}},
+		{"should not match anything", []string{"dist/**"}, []string{}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Glob(dir, tt.patterns)
+			if err != nil {
+				t.Fatalf("Glob() error = %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Glob() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+
+	if _, err := Glob(dir, []string{"src/[.ts"}); err == nil {
+		t.Errorf("Glob() expected error for malformed pattern")
+	}
+}
@@ -0,0 +1,245 @@
+package helper
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"slices"
+	"strings"
+	"unicode/utf8"
+
+	"golang.org/x/exp/maps"
+)
+
+// KeyOrder contains the keys of all objects of a decoded json document in document order,
+// indexed by the json pointer of the object (e.g. "" for the root or "/a/b" for a nested object).
+type KeyOrder map[string][]string
+
+// Offsets contains the byte offset of all values of a decoded json document, indexed by their json pointer.
+// The offset of a value of an object is the offset of its key.
+type Offsets map[string]int
+
+// DecodeOrdered decodes a json object like json.Unmarshal does, but additionally returns the order of the keys of all objects.
+func DecodeOrdered(content []byte) (map[string]interface{}, KeyOrder, error) {
+	data, order, _, err := DecodeWithOffsets(content)
+	return data, order, err
+}
+
+// DecodeWithOffsets decodes a json object like DecodeOrdered does, but additionally returns the offsets of all values.
+// The document may contain comments, trailing commas, unquoted keys and single quoted strings (jsonc), offsets
+// and the offsets of syntax errors (returned as *SyntaxError) refer to the original document.
+func DecodeWithOffsets(content []byte) (map[string]interface{}, KeyOrder, Offsets, error) {
+	normalized, offsetMap, err := normalizeJsonc(content)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+
+	d := orderedDecoder{
+		decoder: json.NewDecoder(bytes.NewReader(normalized)),
+		content: normalized,
+		order:   KeyOrder{},
+		offsets: Offsets{},
+	}
+	d.offsets[""] = d.nextOffset()
+	value, err := d.value("")
+	if err != nil {
+		var syntaxError *json.SyntaxError
+		if errors.As(err, &syntaxError) {
+			// the offset of a json.SyntaxError is the offset after the invalid character
+			offset := max(int(syntaxError.Offset)-1, 0)
+			if strings.HasPrefix(syntaxError.Error(), "unexpected end") {
+				return nil, nil, nil, &SyntaxError{Offset: len(content), Msg: syntaxError.Error()}
+			}
+			return nil, nil, nil, &SyntaxError{Offset: offsetMap.original(offset), Msg: syntaxError.Error()}
+		}
+		if errors.Is(err, io.ErrUnexpectedEOF) || errors.Is(err, io.EOF) {
+			return nil, nil, nil, &SyntaxError{Offset: len(content), Msg: "unexpected end of JSON input"}
+		}
+		return nil, nil, nil, err
+	}
+	if rest := bytes.TrimLeft(normalized[d.decoder.InputOffset():], " \t\r\n"); len(rest) > 0 {
+		return nil, nil, nil, &SyntaxError{Offset: offsetMap.original(len(normalized) - len(rest)), Msg: "invalid character after top-level value"}
+	}
+
+	data, ok := value.(map[string]interface{})
+	if !ok {
+		return nil, nil, nil, fmt.Errorf("expected a json object but got %T", value)
+	}
+	for pointer, offset := range d.offsets {
+		d.offsets[pointer] = offsetMap.original(offset)
+	}
+	return data, d.order, d.offsets, nil
+}
+
+type orderedDecoder struct {
+	decoder *json.Decoder
+	content []byte
+	order   KeyOrder
+	offsets Offsets
+}
+
+func (d *orderedDecoder) value(pointer string) (interface{}, error) {
+	token, err := d.decoder.Token()
+	if err != nil {
+		return nil, err
+	}
+
+	switch token {
+	case json.Delim('{'):
+		object := map[string]interface{}{}
+		keys := []string{}
+		for d.decoder.More() {
+			offset := d.nextOffset()
+			keyToken, err := d.decoder.Token()
+			if err != nil {
+				return nil, err
+			}
+			key := keyToken.(string)
+			d.offsets[JsonPointer(pointer, key)] = offset
+			value, err := d.value(JsonPointer(pointer, key))
+			if err != nil {
+				return nil, err
+			}
+			if _, exists := object[key]; !exists {
+				keys = append(keys, key)
+			}
+			object[key] = value
+		}
+		// consume the closing delimiter
+		if _, err := d.decoder.Token(); err != nil {
+			return nil, err
+		}
+		d.order[pointer] = keys
+		return object, nil
+	case json.Delim('['):
+		array := []interface{}{}
+		for d.decoder.More() {
+			itemPointer := JsonPointer(pointer, fmt.Sprint(len(array)))
+			d.offsets[itemPointer] = d.nextOffset()
+			value, err := d.value(itemPointer)
+			if err != nil {
+				return nil, err
+			}
+			array = append(array, value)
+		}
+		if _, err := d.decoder.Token(); err != nil {
+			return nil, err
+		}
+		return array, nil
+	}
+	return token, nil
+}
+
+// nextOffset returns the offset of the next token, skipping whitespace and separators.
+func (d *orderedDecoder) nextOffset() int {
+	offset := int(d.decoder.InputOffset())
+	for offset < len(d.content) && strings.IndexByte(" \t\r\n,:", d.content[offset]) >= 0 {
+		offset++
+	}
+	return offset
+}
+
+// Position returns the line and column (both starting at 1) of a byte offset in content.
+func Position(content []byte, offset int) (line, column int) {
+	offset = min(max(offset, 0), len(content))
+	before := content[:offset]
+	line = bytes.Count(before, []byte("\n")) + 1
+	lineStart := bytes.LastIndexByte(before, '\n') + 1
+	return line, utf8.RuneCount(before[lineStart:]) + 1
+}
+
+// Keys returns the keys of the object at pointer in document order. Keys that are not part of the
+// document (e.g. because the object was not decoded with DecodeOrdered) follow in alphabetical order.
+func (o KeyOrder) Keys(pointer string, object map[string]interface{}) []string {
+	keys := []string{}
+	for _, key := range o[pointer] {
+		if _, ok := object[key]; ok {
+			keys = append(keys, key)
+		}
+	}
+
+	remaining := maps.Keys(object)
+	slices.Sort(remaining)
+	for _, key := range remaining {
+		if !slices.Contains(keys, key) {
+			keys = append(keys, key)
+		}
+	}
+	return keys
+}
+
+// JsonPointer appends the key to a json pointer.
+func JsonPointer(pointer string, key string) string {
+	key = strings.ReplaceAll(key, "~", "~0")
+	key = strings.ReplaceAll(key, "/", "~1")
+	return pointer + "/" + key
+}
+
+// EncodeOrdered encodes a value decoded from json as indented json, the keys of objects are written
+// in the order returned by order.Keys.
+func EncodeOrdered(value interface{}, order KeyOrder) ([]byte, error) {
+	buffer := bytes.Buffer{}
+	if err := encodeOrderedValue(&buffer, value, "", order, ""); err != nil {
+		return nil, err
+	}
+	buffer.WriteByte('\n')
+	return buffer.Bytes(), nil
+}
+
+func encodeOrderedValue(buffer *bytes.Buffer, value interface{}, pointer string, order KeyOrder, indent string) error {
+	switch value := value.(type) {
+	case map[string]interface{}:
+		if len(value) == 0 {
+			buffer.WriteString("{}")
+			return nil
+		}
+		buffer.WriteString("{\n")
+		for index, key := range order.Keys(pointer, value) {
+			if index > 0 {
+				buffer.WriteString(",\n")
+			}
+			buffer.WriteString(indent + "  ")
+			if err := EncodeJsonValue(buffer, key); err != nil {
+				return err
+			}
+			buffer.WriteString(": ")
+			if err := encodeOrderedValue(buffer, value[key], JsonPointer(pointer, key), order, indent+"  "); err != nil {
+				return err
+			}
+		}
+		buffer.WriteString("\n" + indent + "}")
+		return nil
+	case []interface{}:
+		if len(value) == 0 {
+			buffer.WriteString("[]")
+			return nil
+		}
+		buffer.WriteString("[\n")
+		for index, item := range value {
+			if index > 0 {
+				buffer.WriteString(",\n")
+			}
+			buffer.WriteString(indent + "  ")
+			if err := encodeOrderedValue(buffer, item, JsonPointer(pointer, fmt.Sprint(index)), order, indent+"  "); err != nil {
+				return err
+			}
+		}
+		buffer.WriteString("\n" + indent + "]")
+		return nil
+	}
+	return EncodeJsonValue(buffer, value)
+}
+
+// EncodeJsonValue writes the compact json encoding of a value to the writer without escaping html characters.
+func EncodeJsonValue(writer io.Writer, value interface{}) error {
+	buffer := bytes.Buffer{}
+	encoder := json.NewEncoder(&buffer)
+	encoder.SetEscapeHTML(false)
+	if err := encoder.Encode(value); err != nil {
+		return err
+	}
+	_, err := writer.Write(bytes.TrimSuffix(buffer.Bytes(), []byte("\n")))
+	return err
+}
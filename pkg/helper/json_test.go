@@ -0,0 +1,89 @@
+package helper
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestDecodeOrdered(t *testing.T) {
+	content := []byte(`{"b": {"z": 1, "a/b": [{"y": true, "x": null}]}, "a": "value"}`)
+	data, order, err := DecodeOrdered(content)
+	if err != nil {
+		t.Fatalf("DecodeOrdered() error = %v", err)
+	}
+
+	expected := map[string]interface{}{
+		"b": map[string]interface{}{
+			"z":   float64(1),
+			"a/b": []interface{}{map[string]interface{}{"y": true, "x": nil}},
+		},
+		"a": "value",
+	}
+	if !reflect.DeepEqual(data, expected) {
+		t.Errorf("DecodeOrdered() = %v, want %v", data, expected)
+	}
+
+	expectedOrder := KeyOrder{
+		"":          {"b", "a"},
+		"/b":        {"z", "a/b"},
+		"/b/a~1b/0": {"y", "x"},
+	}
+	if !reflect.DeepEqual(order, expectedOrder) {
+		t.Errorf("DecodeOrdered() order = %v, want %v", order, expectedOrder)
+	}
+}
+
+func TestDecodeWithOffsets(t *testing.T) {
+	content := []byte("{\n  \"a\": {\"b\": [1, \"ä\", true]},\n  \"c\" : null\n}")
+	_, _, offsets, err := DecodeWithOffsets(content)
+	if err != nil {
+		t.Fatalf("DecodeWithOffsets() error = %v", err)
+	}
+
+	expected := map[string][2]int{
+		"":       {1, 1},
+		"/a":     {2, 3},
+		"/a/b":   {2, 9},
+		"/a/b/0": {2, 15},
+		"/a/b/1": {2, 18},
+		"/a/b/2": {2, 23},
+		"/c":     {3, 3},
+	}
+	for pointer, want := range expected {
+		line, column := Position(content, offsets[pointer])
+		if line != want[0] || column != want[1] {
+			t.Errorf("Position(%s) = %d:%d, want %d:%d", pointer, line, column, want[0], want[1])
+		}
+	}
+}
+
+func TestDecodeOrderedErrors(t *testing.T) {
+	for _, content := range []string{`[]`, `{"a": }`, `{} {}`, `"text"`} {
+		t.Run(content, func(t *testing.T) {
+			if _, _, err := DecodeOrdered([]byte(content)); err == nil {
+				t.Errorf("DecodeOrdered(%s) expected an error", content)
+			}
+		})
+	}
+}
+
+func TestKeyOrder_Keys(t *testing.T) {
+	object := map[string]interface{}{"c": 1, "a": 2, "d": 3, "b": 4}
+	tests := []struct {
+		name  string
+		order KeyOrder
+		want  []string
+	}{
+		{"should keep the document order", KeyOrder{"": {"c", "a", "d", "b"}}, []string{"c", "a", "d", "b"}},
+		{"should sort unknown keys", KeyOrder{"": {"d", "x"}}, []string{"d", "a", "b", "c"}},
+		{"should sort without order", nil, []string{"a", "b", "c", "d"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.order.Keys("", object); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("KeyOrder.Keys() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,210 @@
+package helper
+
+import (
+	"bytes"
+	"sort"
+)
+
+// A SyntaxError describes invalid syntax of a json document.
+type SyntaxError struct {
+	// Offset is the byte offset of the invalid character in the document
+	Offset int
+	Msg    string
+}
+
+func (e *SyntaxError) Error() string {
+	return e.Msg
+}
+
+// offsetAnchor marks an offset from which on the offsets of the normalized and the original document
+// differ by a constant until the next anchor.
+type offsetAnchor struct {
+	normalized int
+	original   int
+}
+
+// offsetMap maps offsets of a normalized document back to the original document.
+type offsetMap []offsetAnchor
+
+func (m offsetMap) original(offset int) int {
+	index := sort.Search(len(m), func(i int) bool { return m[i].normalized > offset }) - 1
+	if index < 0 {
+		return offset
+	}
+	return m[index].original + offset - m[index].normalized
+}
+
+// normalizeJsonc converts a json document with comments (// and /* */), trailing commas, unquoted keys
+// and single quoted strings into plain json. Comments and trailing commas are replaced with whitespace,
+// so that offsets only shift at quoted keys and converted strings.
+func normalizeJsonc(content []byte) ([]byte, offsetMap, error) {
+	n := jsoncNormalizer{
+		content:   content,
+		output:    make([]byte, 0, len(content)),
+		lastToken: -1,
+	}
+	for n.pos < len(content) {
+		char := content[n.pos]
+		switch {
+		case char == '/' && n.peek(1) == '/':
+			for n.pos < len(content) && content[n.pos] != '\n' {
+				n.blank()
+			}
+		case char == '/' && n.peek(1) == '*':
+			end := bytes.Index(content[n.pos+2:], []byte("*/"))
+			if end < 0 {
+				return nil, nil, &SyntaxError{Offset: n.pos, Msg: "unterminated comment"}
+			}
+			for end = n.pos + 2 + end + 2; n.pos < end; {
+				n.blank()
+			}
+		case char == '"':
+			n.doubleQuoted()
+		case char == '\'':
+			if err := n.singleQuoted(); err != nil {
+				return nil, nil, err
+			}
+		case char == '{' || char == '[':
+			n.containers = append(n.containers, char)
+			n.token()
+		case char == '}' || char == ']':
+			if n.lastToken >= 0 && n.output[n.lastToken] == ',' {
+				// remove the trailing comma
+				n.output[n.lastToken] = ' '
+			}
+			if len(n.containers) > 0 {
+				n.containers = n.containers[:len(n.containers)-1]
+			}
+			n.token()
+		case isIdentifierChar(char) && n.isKeyPosition():
+			n.unquotedKey()
+		case char == ' ' || char == '\t' || char == '\r' || char == '\n':
+			n.sync()
+			n.output = append(n.output, char)
+			n.pos++
+		default:
+			n.token()
+		}
+	}
+	return n.output, n.offsets, nil
+}
+
+type jsoncNormalizer struct {
+	content []byte
+	pos     int
+	output  []byte
+	offsets offsetMap
+	// containers contains the opening delimiters of all objects and arrays around the position
+	containers []byte
+	// lastToken is the index of the last character of the output, which is neither whitespace nor part of a comment
+	lastToken int
+}
+
+// sync anchors the current position if the difference between the offsets of output and content changed.
+func (n *jsoncNormalizer) sync() {
+	shift := 0
+	if len(n.offsets) > 0 {
+		last := n.offsets[len(n.offsets)-1]
+		shift = last.normalized - last.original
+	}
+	if len(n.output)-n.pos != shift {
+		n.offsets = append(n.offsets, offsetAnchor{normalized: len(n.output), original: n.pos})
+	}
+}
+
+func (n *jsoncNormalizer) peek(distance int) byte {
+	if n.pos+distance < len(n.content) {
+		return n.content[n.pos+distance]
+	}
+	return 0
+}
+
+// token copies the current character.
+func (n *jsoncNormalizer) token() {
+	n.sync()
+	n.lastToken = len(n.output)
+	n.output = append(n.output, n.content[n.pos])
+	n.pos++
+}
+
+// blank replaces the current character with whitespace, line breaks are kept for the line numbers of errors.
+func (n *jsoncNormalizer) blank() {
+	n.sync()
+	if char := n.content[n.pos]; char == '\n' || char == '\r' {
+		n.output = append(n.output, char)
+	} else {
+		n.output = append(n.output, ' ')
+	}
+	n.pos++
+}
+
+func (n *jsoncNormalizer) doubleQuoted() {
+	n.token()
+	for n.pos < len(n.content) {
+		char := n.content[n.pos]
+		n.token()
+		if char == '\\' && n.pos < len(n.content) {
+			n.token()
+		} else if char == '"' {
+			return
+		}
+	}
+}
+
+// singleQuoted converts a single quoted string into a double quoted one.
+func (n *jsoncNormalizer) singleQuoted() error {
+	start := n.pos
+	n.sync()
+	n.output = append(n.output, '"')
+	n.pos++
+	for n.pos < len(n.content) {
+		n.sync()
+		switch char := n.content[n.pos]; {
+		case char == '\\' && n.peek(1) == '\'':
+			n.output = append(n.output, '\'')
+			n.pos += 2
+		case char == '\\' && n.pos+1 < len(n.content):
+			n.output = append(n.output, n.content[n.pos:n.pos+2]...)
+			n.pos += 2
+		case char == '"':
+			n.output = append(n.output, '\\', '"')
+			n.pos++
+		case char == '\'':
+			n.lastToken = len(n.output)
+			n.output = append(n.output, '"')
+			n.pos++
+			return nil
+		default:
+			n.output = append(n.output, char)
+			n.pos++
+		}
+	}
+	return &SyntaxError{Offset: start, Msg: "unterminated string"}
+}
+
+// isKeyPosition reports whether the position is at the start of a key of an object.
+func (n *jsoncNormalizer) isKeyPosition() bool {
+	if len(n.containers) == 0 || n.containers[len(n.containers)-1] != '{' || n.lastToken < 0 {
+		return false
+	}
+	last := n.output[n.lastToken]
+	return last == '{' || last == ','
+}
+
+// unquotedKey quotes a key consisting of letters, digits, _ and $.
+func (n *jsoncNormalizer) unquotedKey() {
+	n.sync()
+	n.output = append(n.output, '"')
+	for n.pos < len(n.content) && isIdentifierChar(n.content[n.pos]) {
+		n.sync()
+		n.output = append(n.output, n.content[n.pos])
+		n.pos++
+	}
+	n.sync()
+	n.lastToken = len(n.output)
+	n.output = append(n.output, '"')
+}
+
+func isIdentifierChar(char byte) bool {
+	return char == '_' || char == '$' || (char >= 'a' && char <= 'z') || (char >= 'A' && char <= 'Z') || (char >= '0' && char <= '9')
+}
@@ -0,0 +1,84 @@
+package helper
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestDecodeWithOffsets_Jsonc(t *testing.T) {
+	content := []byte(`// the config
+{
+  /* projects */
+  web: {
+    'dir': 'it\'s "quoted"', // trailing comment
+    "list": [1, 2, /* three */],
+    $adapter: "vite-yarn",
+  },
+  "url": "http://localhost/*not a comment*/",
+}`)
+	data, order, offsets, err := DecodeWithOffsets(content)
+	if err != nil {
+		t.Fatalf("DecodeWithOffsets() error = %v", err)
+	}
+
+	expected := map[string]interface{}{
+		"web": map[string]interface{}{
+			"dir":      `it's "quoted"`,
+			"list":     []interface{}{float64(1), float64(2)},
+			"$adapter": "vite-yarn",
+		},
+		"url": "http://localhost/*not a comment*/",
+	}
+	if !reflect.DeepEqual(data, expected) {
+		t.Errorf("DecodeWithOffsets() = %v, want %v", data, expected)
+	}
+	if !reflect.DeepEqual(order["/web"], []string{"dir", "list", "$adapter"}) {
+		t.Errorf("DecodeWithOffsets() order = %v", order["/web"])
+	}
+
+	positions := map[string][2]int{
+		"":              {2, 1},
+		"/web":          {4, 3},
+		"/web/dir":      {5, 5},
+		"/web/list":     {6, 5},
+		"/web/list/1":   {6, 17},
+		"/web/$adapter": {7, 5},
+		"/url":          {9, 3},
+	}
+	for pointer, want := range positions {
+		line, column := Position(content, offsets[pointer])
+		if line != want[0] || column != want[1] {
+			t.Errorf("Position(%s) = %d:%d, want %d:%d", pointer, line, column, want[0], want[1])
+		}
+	}
+}
+
+func TestDecodeWithOffsets_SyntaxErrors(t *testing.T) {
+	tests := []struct {
+		content string
+		line    int
+		column  int
+	}{
+		{"{\n  key: 'value'\n  other: 1\n}", 3, 3},
+		{"{\n  \"a\": }", 2, 8},
+		{"{\n  /* unterminated\n}", 2, 3},
+		{"{\n  'unterminated\n}", 2, 3},
+		{"{\n  \"a\": 1\n", 3, 1},
+		{"{} // comment\n}", 2, 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.content, func(t *testing.T) {
+			_, _, _, err := DecodeWithOffsets([]byte(tt.content))
+			var syntaxError *SyntaxError
+			if !errors.As(err, &syntaxError) {
+				t.Fatalf("DecodeWithOffsets() error = %v, want a syntax error", err)
+			}
+			line, column := Position([]byte(tt.content), syntaxError.Offset)
+			if line != tt.line || column != tt.column {
+				t.Errorf("DecodeWithOffsets() error at %d:%d, want %d:%d (%v)", line, column, tt.line, tt.column, err)
+			}
+		})
+	}
+}
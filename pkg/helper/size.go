@@ -0,0 +1,48 @@
+package helper
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+var sizeUnits = []struct {
+	suffix string
+	factor int64
+}{
+	{"TB", 1 << 40},
+	{"GB", 1 << 30},
+	{"MB", 1 << 20},
+	{"KB", 1 << 10},
+	{"B", 1},
+}
+
+// ParseSize parses a size like "512MB" or "2GB" into bytes. Units are binary (1KB = 1024B),
+// a number without unit is interpreted as bytes.
+func ParseSize(value string) (int64, error) {
+	normalized := strings.ToUpper(strings.TrimSpace(value))
+	factor := int64(1)
+	for _, unit := range sizeUnits {
+		if strings.HasSuffix(normalized, unit.suffix) {
+			normalized = strings.TrimSpace(strings.TrimSuffix(normalized, unit.suffix))
+			factor = unit.factor
+			break
+		}
+	}
+
+	amount, err := strconv.ParseFloat(normalized, 64)
+	if err != nil || amount < 0 {
+		return 0, fmt.Errorf("invalid size '%s'", value)
+	}
+	return int64(amount * float64(factor)), nil
+}
+
+// FormatSize formats a size in bytes using the largest fitting unit.
+func FormatSize(size int64) string {
+	for _, unit := range sizeUnits {
+		if size >= unit.factor && unit.factor > 1 {
+			return fmt.Sprintf("%.1f%s", float64(size)/float64(unit.factor), unit.suffix)
+		}
+	}
+	return fmt.Sprintf("%dB", size)
+}
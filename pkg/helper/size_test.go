@@ -0,0 +1,53 @@
+package helper
+
+import "testing"
+
+func TestParseSize(t *testing.T) {
+	tests := []struct {
+		value   string
+		want    int64
+		wantErr bool
+	}{
+		{"1024", 1024, false},
+		{"10B", 10, false},
+		{"2KB", 2048, false},
+		{"512mb", 512 << 20, false},
+		{"1.5GB", 3 << 29, false},
+		{" 1 TB ", 1 << 40, false},
+		{"GB", 0, true},
+		{"-1MB", 0, true},
+		{"ten", 0, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.value, func(t *testing.T) {
+			got, err := ParseSize(tt.value)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParseSize(%s) error = %v, wantErr %v", tt.value, err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("ParseSize(%s) = %d, want %d", tt.value, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFormatSize(t *testing.T) {
+	tests := []struct {
+		size int64
+		want string
+	}{
+		{0, "0B"},
+		{512, "512B"},
+		{1536, "1.5KB"},
+		{2 << 30, "2.0GB"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.want, func(t *testing.T) {
+			if got := FormatSize(tt.size); got != tt.want {
+				t.Errorf("FormatSize(%d) = %s, want %s", tt.size, got, tt.want)
+			}
+		})
+	}
+}
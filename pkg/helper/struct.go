@@ -4,20 +4,33 @@ import (
 	"reflect"
 )
 
+// A ValueDecoder converts the raw value of a json field itself, for fields supporting multiple notations.
+type ValueDecoder interface {
+	DecodeValue(value interface{})
+}
+
 func MapToStruct[T any](data map[string]interface{}, target T) T {
-	targetValue := reflect.ValueOf(&target)
+	mapToValue(data, reflect.ValueOf(&target))
+	return target
+}
 
+func mapToValue(data map[string]interface{}, targetValue reflect.Value) {
 	for key, value := range data {
 		field := FindJsonField(targetValue, key)
 		if !field.IsValid() || !field.CanSet() {
 			continue
 		}
 
+		if decoder, ok := field.Addr().Interface().(ValueDecoder); ok {
+			decoder.DecodeValue(value)
+			continue
+		}
+
 		switch valueType := value.(type) {
 		case map[string]interface{}:
 			if field.Kind() == reflect.Struct {
 				// recurse
-				field.Set(reflect.ValueOf(MapToStruct(valueType, field.Addr().Interface())))
+				mapToValue(valueType, field.Addr())
 			} else if field.Kind() == reflect.Map {
 				// convert map
 				mapValue := reflect.MakeMap(field.Type())
@@ -38,12 +51,19 @@ func MapToStruct[T any](data map[string]interface{}, target T) T {
 			}
 		default:
 			// try to set field
-			if reflect.TypeOf(value).AssignableTo(field.Type()) {
-				field.Set(reflect.ValueOf(value))
+			fieldValue := reflect.ValueOf(value)
+			if fieldValue.Type().AssignableTo(field.Type()) {
+				field.Set(fieldValue)
+			} else if isNumber(fieldValue.Kind()) && isNumber(field.Kind()) {
+				// json numbers are always decoded as float64
+				field.Set(fieldValue.Convert(field.Type()))
 			}
 		}
 	}
-	return target
+}
+
+func isNumber(kind reflect.Kind) bool {
+	return (kind >= reflect.Int && kind <= reflect.Uint64) || kind == reflect.Float32 || kind == reflect.Float64
 }
 
 func FindJsonField(value reflect.Value, key string) reflect.Value {
@@ -98,3 +118,26 @@ func MergeDeep(a map[string]interface{}, b map[string]interface{}) map[string]in
 	}
 	return a
 }
+
+// CloneDeep returns a copy of a map decoded from json, nested maps and slices are copied as well.
+func CloneDeep(m map[string]interface{}) map[string]interface{} {
+	return cloneValue(m).(map[string]interface{})
+}
+
+func cloneValue(value interface{}) interface{} {
+	switch value := value.(type) {
+	case map[string]interface{}:
+		result := make(map[string]interface{}, len(value))
+		for key, item := range value {
+			result[key] = cloneValue(item)
+		}
+		return result
+	case []interface{}:
+		result := make([]interface{}, len(value))
+		for index, item := range value {
+			result[index] = cloneValue(item)
+		}
+		return result
+	}
+	return value
+}
@@ -129,6 +129,30 @@ func TestMergeDeep(t *testing.T) {
 	}
 }
 
+func TestCloneDeep(t *testing.T) {
+	original := map[string]interface{}{
+		"field": "value",
+		"map":   map[string]interface{}{"nested": true},
+		"slice": []interface{}{map[string]interface{}{"item": 1}},
+	}
+	clone := CloneDeep(original)
+	if !reflect.DeepEqual(clone, original) {
+		t.Fatalf("CloneDeep() = %v, want %v", clone, original)
+	}
+
+	clone["map"].(map[string]interface{})["nested"] = false
+	clone["slice"].([]interface{})[0].(map[string]interface{})["item"] = 2
+	MergeDeep(clone, map[string]interface{}{"slice": []interface{}{"appended"}})
+	want := map[string]interface{}{
+		"field": "value",
+		"map":   map[string]interface{}{"nested": true},
+		"slice": []interface{}{map[string]interface{}{"item": 1}},
+	}
+	if !reflect.DeepEqual(original, want) {
+		t.Errorf("original was modified: %v", original)
+	}
+}
+
 type NestedStruct struct {
 	NestedField string `json:"nestedField"`
 }
@@ -139,6 +163,7 @@ type MappedStruct struct {
 	NestedMap    map[string]int `json:"nestedMap"`
 	NestedSlice  []int          `json:"nestedSlice"`
 	NestedStruct NestedStruct   `json:"nestedStruct"`
+	Number       int            `json:"number"`
 }
 
 func TestMapToStruct(t *testing.T) {
@@ -153,6 +178,11 @@ func TestMapToStruct(t *testing.T) {
 		}, MappedStruct{
 			Field1: "value1",
 		}},
+		{"should convert numbers", map[string]interface{}{
+			"number": float64(3),
+		}, MappedStruct{
+			Number: 3,
+		}},
 		{"should skip fields without json tag", map[string]interface{}{
 			"Field2": "value2",
 		}, MappedStruct{}},
@@ -170,13 +200,13 @@ func TestMapToStruct(t *testing.T) {
 		}, MappedStruct{
 			NestedSlice: []int{1},
 		}},
-		// {"should set nested struct", map[string]interface{}{
-		// 	"nestedStruct": map[string]interface{}{
-		// 		"nestedField": "nestedValue",
-		// 	},
-		// }, MappedStruct{
-		// 	NestedStruct: NestedStruct{"nestedValue"},
-		// }},
+		{"should set nested struct", map[string]interface{}{
+			"nestedStruct": map[string]interface{}{
+				"nestedField": "nestedValue",
+			},
+		}, MappedStruct{
+			NestedStruct: NestedStruct{"nestedValue"},
+		}},
 	}
 
 	for _, tt := range tests {
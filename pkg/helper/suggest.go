@@ -0,0 +1,42 @@
+package helper
+
+// Suggest returns the candidate most similar to value, it's empty if no candidate is similar enough
+// to be considered a typo.
+func Suggest(value string, candidates []string) string {
+	suggestion := ""
+	best := len(value)/3 + 1
+	for _, candidate := range candidates {
+		if distance := editDistance(value, candidate); distance <= best && (distance < best || suggestion == "") {
+			suggestion = candidate
+			best = distance
+		}
+	}
+	return suggestion
+}
+
+// editDistance returns the levenshtein distance of a and b, transposing two characters counts as a single edit.
+func editDistance(a, b string) int {
+	source, target := []rune(a), []rune(b)
+	distances := make([][]int, len(source)+1)
+	for i := range distances {
+		distances[i] = make([]int, len(target)+1)
+		distances[i][0] = i
+	}
+	for j := range distances[0] {
+		distances[0][j] = j
+	}
+
+	for i := 1; i <= len(source); i++ {
+		for j := 1; j <= len(target); j++ {
+			cost := 1
+			if source[i-1] == target[j-1] {
+				cost = 0
+			}
+			distances[i][j] = min(distances[i-1][j]+1, distances[i][j-1]+1, distances[i-1][j-1]+cost)
+			if i > 1 && j > 1 && source[i-1] == target[j-2] && source[i-2] == target[j-1] {
+				distances[i][j] = min(distances[i][j], distances[i-2][j-2]+1)
+			}
+		}
+	}
+	return distances[len(source)][len(target)]
+}
@@ -0,0 +1,28 @@
+package helper
+
+import "testing"
+
+func TestSuggest(t *testing.T) {
+	candidates := []string{"build", "run", "watch", "$adapter", "$fragments", "includeFragments"}
+	tests := []struct {
+		value string
+		want  string
+	}{
+		{"biuld", "build"},
+		{"buidl", "build"},
+		{"rn", "run"},
+		{"$adpater", "$adapter"},
+		{"$fragment", "$fragments"},
+		{"includeFragment", "includeFragments"},
+		{"Build", "build"},
+		{"foo", ""},
+		{"deploy", ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.value, func(t *testing.T) {
+			if got := Suggest(tt.value, candidates); got != tt.want {
+				t.Errorf("Suggest(%s) = '%s', want '%s'", tt.value, got, tt.want)
+			}
+		})
+	}
+}
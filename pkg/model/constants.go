@@ -17,12 +17,59 @@ const (
 	AdapterCustom    = "custom"
 )
 
+// Adapters contains all built in adapters a project can use, further adapters can be defined via $adapters
+// or provided by adapter plugins.
+var Adapters = []string{AdapterViteYarn, AdapterViteNpm, AdapterVitePnpm, AdapterTauriYarn, AdapterTauriNpm, AdapterTauriPnpm, AdapterDotnet, AdapterCustom}
+
+const (
+	// ArgStyleSpace passes flags with their value as two arguments (--key value).
+	ArgStyleSpace = "space"
+	// ArgStyleEquals passes flags with their value as one argument (--key=value).
+	ArgStyleEquals = "equals"
+)
+
+const (
+	KeyDefault      = "$default"
+	KeyAdapter      = "$adapter"
+	KeyDirectory    = "$dir"
+	KeyFragment     = "$fragments"
+	KeyCompound     = "$compounds"
+	KeyPre          = "$pre"
+	KeyPost         = "$post"
+	KeyOnError      = "$onError"
+	KeyFinally      = "$finally"
+	KeyCache        = "$cache"
+	KeyEnvFile      = "$envFile"
+	KeyEnvironments = "$environments"
+	KeyInclude      = "$include"
+	KeyOptions      = "$options"
+	KeyAdapters     = "$adapters"
+)
+
 const (
-	KeyDefault   = "$default"
-	KeyAdapter   = "$adapter"
-	KeyDirectory = "$dir"
-	KeyFragment  = "$fragments"
-	KeyCompound  = "$compounds"
-	KeyPre       = "$pre"
-	KeyPost      = "$post"
+	// ConfigFile is the file name of the root config, which is searched for in the working directory and its parents.
+	ConfigFile = "zwooc.config.json"
+	// ProjectConfigFile is the file name of project configs, which are discovered in all directories below the root config.
+	ProjectConfigFile = "zwooc.project.json"
+	// LocalConfigFile is the file name of the optional config next to the root config, which overrides the
+	// config on a single machine and should not be committed.
+	LocalConfigFile = "zwooc.local.json"
+	// JsoncExtension is the extension of config files, which may be used instead of .json to indicate
+	// that the file contains comments (all config files may contain comments, trailing commas and unquoted keys).
+	JsoncExtension = ".jsonc"
 )
+
+// CacheDirectory is the directory (relative to the config) zwooc stores its cache in.
+const CacheDirectory = ".zwooc/cache"
+
+// AdapterPluginDirectory is the directory (relative to the config) adapter plugins are searched for in before the PATH.
+const AdapterPluginDirectory = ".zwooc/adapters"
+
+// AdapterPluginPrefix is the prefix of the executable name of adapter plugins (zwooc-adapter-<name>).
+const AdapterPluginPrefix = "zwooc-adapter-"
+
+// EnvCacheDir overrides the directory the artifact cache is stored in, e.g. to share it between ci runs.
+const EnvCacheDir = "ZWOOC_CACHE_DIR"
+
+// EnvEnvironment selects the environment of the config which is applied to all profiles, like --env does.
+const EnvEnvironment = "ZWOOC_ENV"
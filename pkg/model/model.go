@@ -1,41 +1,118 @@
 package model
 
-import "github.com/zwoo-hq/zwooc/pkg/tasks"
+import (
+	"slices"
 
+	"github.com/zwoo-hq/zwooc/pkg/helper"
+	"github.com/zwoo-hq/zwooc/pkg/tasks"
+	"golang.org/x/exp/maps"
+)
+
+// The option types are decoded from the config, besides the json key a field may be tagged with a
+// description and further constraints (`required`, `enum=<name>`, `keys=<name>`, `minimum=<n>`, `pattern=<regex>`)
+// which are used to generate the json schema of the config.
 type (
 	FragmentOptions map[string]interface{}
 
 	HookOptions struct {
-		Command   string            `json:"command"`
-		Fragments []string          `json:"fragments"`
-		Profiles  map[string]string `json:"profiles"`
+		Command      string         `json:"command" description:"A script for the hook."`
+		Fragments    []string       `json:"fragments" description:"All fragment dependencies of the hook."`
+		Profiles     ProfileTargets `json:"profiles" description:"All profile dependencies of the hook."`
+		AllowFailure bool           `json:"allowFailure" description:"Whether a failure of the hook should not fail the run."`
+		Env          []string       `json:"env" description:"Environment variables (NAME=value) to set for the task, they take precedence over env files."`
+		EnvFile      StringList     `json:"envFile" description:"One or more dotenv files (relative to the project directory) to load the environment of the task from, later files take precedence."`
+	}
+
+	TaskOptions struct {
+		AllowFailure bool           `json:"allowFailure" description:"Whether a failure of the task should not fail the run."`
+		ReadyWhen    ReadyOptions   `json:"readyWhen" description:"A readiness probe, once all conditions are satisfied dependent tasks are started while the task keeps running."`
+		Restart      RestartOptions `json:"restart" description:"A restart policy for long running tasks (run and watch mode)."`
+		StopSignal   string         `json:"stopSignal" description:"The signal sent to the process group of the task when it's stopped (defaults to SIGTERM)." schema:"enum=signals"`
+		StopTimeout  string         `json:"stopTimeout" description:"The time (e.g. 10s) to wait for the task to exit after the stop signal before it's killed (defaults to 5s)."`
+		Tty          bool           `json:"tty" description:"Whether the task should be attached to a pseudo-terminal, so that tools keep their colors and tty behavior."`
+		Inputs       []string       `json:"inputs" description:"Glob patterns (relative to the project directory) of the files the task depends on, patterns starting with ! exclude files. The task is skipped if neither its inputs nor its command changed."`
+		Outputs      []string       `json:"outputs" description:"Glob patterns (relative to the project directory) of the files the task produces. The task is executed if any of them is missing, after a successful run they are stored in the artifact cache."`
+		InputEnv     []string       `json:"inputEnv" description:"The names of environment variables the task depends on."`
+	}
+
+	RestartOptions struct {
+		Policy      string `json:"policy" description:"When the task should be restarted after it exited." schema:"required,enum=restartPolicies"`
+		MaxAttempts int    `json:"maxAttempts" description:"The maximum amount of restarts, 0 means unlimited." schema:"minimum=0"`
+		Backoff     string `json:"backoff" description:"The delay before the first restart (e.g. 500ms), doubled with every further restart."`
+	}
+
+	ReadyOptions struct {
+		Output string `json:"output" description:"A regular expression matching a line of the task output."`
+		Tcp    string `json:"tcp" description:"A tcp address (host:port) accepting connections."`
+		Http   string `json:"http" description:"A url responding with a 2xx status code."`
+		File   string `json:"file" description:"A file (relative to the project directory) that needs to exist."`
 	}
 
 	BaseOptions struct {
-		Base             string   `json:"base"`
-		IncludeFragments []string `json:"includeFragments"`
+		Base             string   `json:"base" description:"A profile whose options are inherited."`
+		IncludeFragments []string `json:"includeFragments" description:"Fragments which are run in parallel to the profile."`
 	}
 
 	ProfileOptions struct {
-		Args map[string]string `json:"args"`
-		Env  []string          `json:"env"`
+		Args    map[string]string `json:"args" description:"Arguments passed to the process, a key is prefixed with -- unless it starts with a hyphen."`
+		Env     []string          `json:"env" description:"Environment variables (NAME=value) to set for the task, they take precedence over env files."`
+		EnvFile StringList        `json:"envFile" description:"One or more dotenv files (relative to the project directory) to load the environment of the task from, later files take precedence."`
+	}
+
+	EnvOptions struct {
+		Env     []string   `json:"env" description:"Environment variables (NAME=value) to set for the task, they take precedence over env files."`
+		EnvFile StringList `json:"envFile" description:"One or more dotenv files (relative to the project directory) to load the environment of the task from, later files take precedence."`
 	}
 
+	// StringList is a list of strings, which can be configured as a single string too.
+	StringList []string
+
 	ViteOptions struct {
-		Mode string `json:"mode"`
+		Mode string `json:"mode" description:"The vite mode of the profile."`
 	}
 
 	DotNetOptions struct {
-		Project string `json:"project"`
+		Project string `json:"project" description:"The .csproj of the profile."`
 	}
 
 	CustomOptions struct {
-		Command string `json:"command"`
+		Command string `json:"command" description:"The command to run for the profile."`
 	}
 
 	CompoundOptions struct {
-		Profiles         map[string]string `json:"profiles"`
-		IncludeFragments []string          `json:"includeFragments"`
+		Profiles         ProfileTargets `json:"profiles" description:"All profile dependencies of the compound."`
+		IncludeFragments []string       `json:"includeFragments" description:"Fragments which are run in parallel to the profiles."`
+	}
+
+	// ProfileTargets are the profiles (and the run mode they are executed in) of a hook or compound.
+	// They are configured either as a map from profile to mode or as a list of targets, which allows
+	// including the same profile in multiple modes.
+	ProfileTargets []ProfileTarget
+
+	ProfileTarget struct {
+		Profile string `json:"profile" description:"The key of the profile." schema:"required"`
+		Mode    string `json:"mode" description:"The run mode the profile is executed in." schema:"required,enum=modes"`
+	}
+
+	// AdapterDefinition defines an adapter in the config ($adapters), which creates commands of an executable.
+	AdapterDefinition struct {
+		Command  string            `json:"command" description:"The executable of the adapter, optionally followed by arguments passed in every mode." schema:"required"`
+		Modes    map[string]string `json:"modes" description:"The arguments (like a subcommand) passed in each run mode, the adapter supports the listed modes only." schema:"keys=modes"`
+		Options  map[string]string `json:"options" description:"Options of the profiles mapped to the flag they are passed as. The flag is passed without a value for true, once per item for lists and with the value otherwise."`
+		Env      []string          `json:"env" description:"Default environment variables (NAME=value) of the tasks of the adapter, env files and env of the profiles take precedence."`
+		ArgStyle string            `json:"argStyle" description:"Whether flags with a value (including args) are passed as --key value (space, the default) or --key=value (equals)." schema:"enum=argStyles"`
+	}
+
+	// WorkspaceOptions are defaults for options of the cli, which apply to every run.
+	WorkspaceOptions struct {
+		MaxConcurrency int      `json:"maxConcurrency" description:"The max amount of parallel tasks, --max-concurrency and --serial take precedence." schema:"minimum=1"`
+		Exclude        []string `json:"exclude" description:"Keys of profiles and fragments which are not executed, in addition to the ones passed via --exclude."`
+	}
+
+	CacheOptions struct {
+		Dir      string `json:"dir" description:"The directory (relative to the config) the artifacts are stored in. Defaults to .zwooc/cache/artifacts and can be overridden by ZWOOC_CACHE_DIR."`
+		MaxSize  string `json:"maxSize" description:"The size the cache is pruned to by removing the least recently used artifacts, e.g. 500MB. Defaults to 2GB." schema:"pattern=^\\s*[0-9]+(\\.[0-9]+)?\\s*([kKmMgGtT]?[bB])?\\s*$"`
+		Disabled bool   `json:"disabled" description:"Disables storing and restoring artifacts, up to date checks are still performed."`
 	}
 )
 
@@ -50,6 +127,7 @@ type (
 		GetDotNetOptions() DotNetOptions
 		GetBaseOptions() BaseOptions
 		GetProfileOptions() ProfileOptions
+		GetEnv() []string
 	}
 
 	Adapter interface {
@@ -62,3 +140,45 @@ type (
 		SwitchMode(mode string)
 	}
 )
+
+var _ helper.ValueDecoder = (*ProfileTargets)(nil)
+
+// DecodeValue decodes both notations of profile targets. Targets of the map notation are sorted by their
+// profile, since maps do not preserve the order of the config. Loaded configs use the list notation only.
+func (p *ProfileTargets) DecodeValue(value interface{}) {
+	targets := ProfileTargets{}
+	switch value := value.(type) {
+	case map[string]interface{}:
+		profiles := maps.Keys(value)
+		slices.Sort(profiles)
+		for _, profile := range profiles {
+			if mode, ok := value[profile].(string); ok {
+				targets = append(targets, ProfileTarget{Profile: profile, Mode: mode})
+			}
+		}
+	case []interface{}:
+		for _, item := range value {
+			if target, ok := item.(map[string]interface{}); ok {
+				targets = append(targets, helper.MapToStruct(target, ProfileTarget{}))
+			}
+		}
+	}
+	*p = targets
+}
+
+var _ helper.ValueDecoder = (*StringList)(nil)
+
+func (l *StringList) DecodeValue(value interface{}) {
+	list := StringList{}
+	switch value := value.(type) {
+	case string:
+		list = append(list, value)
+	case []interface{}:
+		for _, item := range value {
+			if item, ok := item.(string); ok {
+				list = append(list, item)
+			}
+		}
+	}
+	*l = list
+}
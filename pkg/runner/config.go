@@ -2,4 +2,10 @@ package runner
 
 type RunnerConfig struct {
 	MaxConcurrency int
+	// Loose indicates whether a runner continues to execute independent tasks after a task failed.
+	Loose bool
+	// Force indicates whether up to date checks are ignored, thus all tasks are executed.
+	Force bool
+	// Executions deduplicates nodes with the same key across runners, a runner deduplicates only its own nodes if it's nil.
+	Executions *SharedExecutions
 }
@@ -0,0 +1,74 @@
+package runner
+
+import "sync"
+
+// SharedExecutions deduplicates the execution of nodes with the same key across all runners sharing it.
+// The first node of a key that is scheduled executes its main task, all other nodes with the key wait for
+// its result instead. The result is shared regardless of the outcome, thus a failed or canceled execution
+// fails or cancels all nodes with the key. Only a rerun executes the main task again.
+type SharedExecutions struct {
+	executions map[string]*sharedExecution
+	mu         sync.Mutex
+}
+
+type sharedExecution struct {
+	result  *sharedResult
+	waiters []func(result sharedResult)
+}
+
+// A sharedResult is the result of the main task of a node with which waiting nodes continue.
+type sharedResult struct {
+	status  TaskStatus
+	outcome nodeOutcome
+	err     error
+}
+
+func NewSharedExecutions() *SharedExecutions {
+	return &SharedExecutions{
+		executions: map[string]*sharedExecution{},
+	}
+}
+
+// claim reports whether the caller executes the main task of the key. Otherwise, the result of the
+// execution is returned if it's already available, or passed to wait once the execution finishes.
+func (s *SharedExecutions) claim(key string, wait func(result sharedResult)) (bool, *sharedResult) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	execution, ok := s.executions[key]
+	if !ok {
+		s.executions[key] = &sharedExecution{}
+		return true, nil
+	}
+
+	if execution.result != nil {
+		return false, execution.result
+	}
+	execution.waiters = append(execution.waiters, wait)
+	return false, nil
+}
+
+// resolve stores the result of the execution of the key and notifies all waiting nodes.
+// Waiters are notified asynchronously, since the caller may hold the lock of their runner.
+func (s *SharedExecutions) resolve(key string, result sharedResult) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	execution, ok := s.executions[key]
+	if !ok || execution.result != nil {
+		return
+	}
+
+	execution.result = &result
+	for _, wait := range execution.waiters {
+		go wait(result)
+	}
+	execution.waiters = nil
+}
+
+// release forgets the finished execution of the key, so that the next node with the key executes its main task again.
+func (s *SharedExecutions) release(key string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if execution, ok := s.executions[key]; ok && execution.result != nil {
+		delete(s.executions, key)
+	}
+}
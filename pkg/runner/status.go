@@ -18,6 +18,10 @@ const (
 	StatusError
 	// StatusCanceled indicates that the task has been canceled.
 	StatusCanceled
+	// StatusSkipped indicates that the task was skipped because one of its dependencies failed.
+	StatusSkipped
+	// StatusCached indicates that the task was not executed since it's up to date.
+	StatusCached
 )
 
 // A RunnerStatus represents the status of a runner.
@@ -65,10 +69,21 @@ type TreeStatusNode struct {
 	PreNodes []*TreeStatusNode
 	// PostNodes is a collection of nodes that should be executed after the main task.
 	PostNodes []*TreeStatusNode
+	// OnErrorNodes is a collection of nodes that should be executed when the main task or its pre nodes failed.
+	OnErrorNodes []*TreeStatusNode
+	// FinallyNodes is a collection of nodes that should always be executed after the main task.
+	FinallyNodes []*TreeStatusNode
 	// Parent is the parent node.
 	Parent *TreeStatusNode
 	// Error is the error that occurred during the execution of the main task.
 	Error error
+	// AllowFailure indicates whether a failure of this node is tolerated by its parent.
+	AllowFailure bool
+	// Restarts is the amount of times the main task was restarted.
+	Restarts int
+
+	// parents contains the additional parents of a node with several dependents.
+	parents []*TreeStatusNode
 }
 
 func (t *TreeStatusNode) Iterate(handler func(node *TreeStatusNode)) {
@@ -76,7 +91,7 @@ func (t *TreeStatusNode) Iterate(handler func(node *TreeStatusNode)) {
 		pre.Iterate(handler)
 	}
 	handler(t)
-	for _, post := range t.PostNodes {
+	for _, post := range helper.Concat(t.PostNodes, t.OnErrorNodes, t.FinallyNodes) {
 		post.Iterate(handler)
 	}
 }
@@ -85,11 +100,23 @@ func (t *TreeStatusNode) GetDirectChildren() []*TreeStatusNode {
 	children := []*TreeStatusNode{}
 	children = append(children, t.PreNodes...)
 	children = append(children, t.PostNodes...)
+	children = append(children, t.OnErrorNodes...)
+	children = append(children, t.FinallyNodes...)
 	return children
 }
 
 func (t *TreeStatusNode) IsDone() bool {
-	return t.AggregatedStatus == StatusDone || t.AggregatedStatus == StatusError || t.AggregatedStatus == StatusCanceled
+	return isFinalStatus(t.AggregatedStatus)
+}
+
+// adopt adds the node as parent of the child, the first parent of a child is its Parent.
+func (t *TreeStatusNode) adopt(child *TreeStatusNode) *TreeStatusNode {
+	if child.Parent == nil {
+		child.Parent = t
+	} else {
+		child.parents = append(child.parents, t)
+	}
+	return child
 }
 
 func (t *TreeStatusNode) Update() {
@@ -97,6 +124,9 @@ func (t *TreeStatusNode) Update() {
 		if t.Parent != nil {
 			t.Parent.Update()
 		}
+		for _, parent := range t.parents {
+			parent.Update()
+		}
 	}()
 
 	children := t.GetDirectChildren()
@@ -105,17 +135,36 @@ func (t *TreeStatusNode) Update() {
 		return
 	}
 
+	// the status of the main task is part of the aggregated status as well
+	statuses := helper.MapTo(children, func(n *TreeStatusNode) TaskStatus {
+		if n.AllowFailure && n.AggregatedStatus == StatusError {
+			// tolerated failures don't affect the parent
+			return StatusDone
+		}
+		return n.AggregatedStatus
+	})
+	statuses = append(statuses, t.Status)
+
 	// applies the status based on children by precedence -> the order of the if statements matters
-	if someChildWithStatus(children, StatusError) {
+	if someWithStatus(statuses, StatusError) {
 		t.AggregatedStatus = StatusError
-	} else if someChildWithStatus(children, StatusCanceled) {
+	} else if someWithStatus(statuses, StatusCanceled) {
 		t.AggregatedStatus = StatusCanceled
-	} else if allChildrenWithStatus(children, StatusDone) {
+	} else if allWithStatus(statuses, StatusSkipped) {
+		t.AggregatedStatus = StatusSkipped
+	} else if allWithStatus(statuses, StatusCached) {
+		t.AggregatedStatus = StatusCached
+	} else if helper.All(statuses, isSuccessfulStatus) {
 		t.AggregatedStatus = StatusDone
-	} else if someChildWithStatus(children, StatusRunning) {
+	} else if someWithStatus(statuses, StatusRunning) {
 		t.AggregatedStatus = StatusRunning
-	} else if someChildWithStatus(children, StatusScheduled) {
+	} else if someWithStatus(statuses, StatusScheduled) {
 		t.AggregatedStatus = StatusScheduled
+	} else if someWithStatus(statuses, StatusDone) {
+		// some parts are done while others are waiting to be scheduled
+		t.AggregatedStatus = StatusRunning
+	} else {
+		t.AggregatedStatus = StatusPending
 	}
 }
 
@@ -131,14 +180,56 @@ func (t *TreeStatusNode) IsPost() bool {
 	})
 }
 
-func someChildWithStatus(children []*TreeStatusNode, status TaskStatus) bool {
-	return helper.Some(children, func(n *TreeStatusNode) bool {
-		return n.AggregatedStatus == status
+func isFinalStatus(status TaskStatus) bool {
+	return status == StatusDone || status == StatusError || status == StatusCanceled || status == StatusSkipped || status == StatusCached
+}
+
+func isSuccessfulStatus(status TaskStatus) bool {
+	return status == StatusDone || status == StatusSkipped || status == StatusCached
+}
+
+func someWithStatus(statuses []TaskStatus, status TaskStatus) bool {
+	return helper.Some(statuses, func(s TaskStatus) bool {
+		return s == status
 	})
 }
 
-func allChildrenWithStatus(children []*TreeStatusNode, status TaskStatus) bool {
-	return helper.All(children, func(n *TreeStatusNode) bool {
-		return n.AggregatedStatus == status
+func allWithStatus(statuses []TaskStatus, status TaskStatus) bool {
+	return helper.All(statuses, func(s TaskStatus) bool {
+		return s == status
 	})
 }
+
+// snapshot copies the node together with its parents, so that an update reflects the status at the time it
+// was sent, even if it's processed after the status changed again. Child nodes are not copied.
+func (t *TreeStatusNode) snapshot() *TreeStatusNode {
+	snapshot := *t
+	if t.Parent != nil {
+		snapshot.Parent = t.Parent.snapshot()
+	}
+	return &snapshot
+}
+
+// snapshots returns a snapshot of the node and of each additional parent of the node and its ancestors,
+// so that the update of a node with several parents reaches all of them.
+func (t *TreeStatusNode) snapshots() []*TreeStatusNode {
+	snapshots := []*TreeStatusNode{t.snapshot()}
+	visited := map[*TreeStatusNode]bool{}
+	pending := []*TreeStatusNode{t}
+	for len(pending) > 0 {
+		node := pending[0]
+		pending = pending[1:]
+		if visited[node] {
+			continue
+		}
+		visited[node] = true
+		for _, parent := range node.parents {
+			snapshots = append(snapshots, parent.snapshot())
+		}
+		if node.Parent != nil {
+			pending = append(pending, node.Parent)
+		}
+		pending = append(pending, node.parents...)
+	}
+	return snapshots
+}
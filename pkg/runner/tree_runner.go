@@ -1,12 +1,63 @@
 package runner
 
 import (
+	"errors"
+	"fmt"
 	"sync"
 	"sync/atomic"
+	"time"
 
+	"github.com/zwoo-hq/zwooc/pkg/model"
 	"github.com/zwoo-hq/zwooc/pkg/tasks"
 )
 
+var (
+	ErrNodeNotFound        = errors.New("node not found")
+	ErrNodeNotRunning      = errors.New("node is not running")
+	ErrNodeNotStopped      = errors.New("node is not stopped")
+	ErrNodeNotControllable = errors.New("node can't be controlled")
+	ErrRunnerNotDone       = errors.New("runner did not finish yet")
+)
+
+// A nodeOutcome represents the result of the execution of a (sub)tree.
+// Outcomes are ordered by severity.
+type nodeOutcome int
+
+const (
+	outcomeSuccess nodeOutcome = iota
+	outcomeCanceled
+	outcomeFailed
+)
+
+// A nodePhase represents the execution phase of a task tree node.
+type nodePhase int
+
+const (
+	phasePre nodePhase = iota
+	phaseMain
+	phasePost
+	phaseOnError
+	phaseFinally
+	phaseFinished
+)
+
+// A nodeState tracks the execution of a task tree node and its subtrees.
+type nodeState struct {
+	// phase is the current execution phase of the node.
+	phase nodePhase
+	// outcome is the accumulated outcome of the node and all finished subtrees.
+	outcome nodeOutcome
+	// pending is the amount of subtrees of the current phase that did not finish yet.
+	pending int
+	// ready indicates whether the main task passed its readiness probe while still running.
+	ready bool
+	// started indicates whether the subtree of the node was already started by one of its dependents.
+	started bool
+	// dependents contains the nodes waiting for the subtree of the node to finish, a node with several
+	// parents is executed once and reports its outcome to each of them.
+	dependents []*tasks.TaskTreeNode
+}
+
 // A TaskTreeRunner represents a runner for a task tree.
 type TaskTreeRunner struct {
 	// root is the root node of the task tree that should be executed.
@@ -15,11 +66,37 @@ type TaskTreeRunner struct {
 	status RunnerStatus
 	// statusTree is the mirrored statusTree tree of the task tree.
 	statusTree *TreeStatusNode
+	// statusNodes maps each node of the task tree to its node in the status tree.
+	statusNodes map[*tasks.TaskTreeNode]*TreeStatusNode
+	// states contains the execution state of each node.
+	states map[*tasks.TaskTreeNode]*nodeState
+	// cleanupNodes contains all nodes that are part of a $onError or $finally subtree.
+	cleanupNodes map[*tasks.TaskTreeNode]bool
+	// completedNodes contains all nodes whose main task already succeeded in a previous run.
+	completedNodes map[*tasks.TaskTreeNode]bool
+	// ownedNodes contains all shared nodes whose main task is executed by this runner.
+	ownedNodes map[*tasks.TaskTreeNode]bool
+	// waitingNodes contains all shared nodes waiting for the execution of another node with the same key.
+	waitingNodes map[*tasks.TaskTreeNode]bool
+	// executions deduplicates the execution of shared nodes.
+	executions *SharedExecutions
+	// config is the configuration the runner was created with.
+	config RunnerConfig
 
 	// scheduledNodes is a channel that is used to schedule nodes for execution.
 	scheduledNodes chan *tasks.TaskTreeNode
+	// isClosed indicates whether the scheduledNodes channel was already closed.
+	isClosed bool
 	// forwardCancel is a collection of channels that are used to forward cancel signals to running tasks.
 	forwardCancel map[string]chan bool
+	// canceledNodes contains all running nodes that received a cancel signal.
+	canceledNodes map[string]bool
+	// stoppedNodes contains all running nodes whose main task was stopped.
+	stoppedNodes map[string]bool
+	// shutdownNodes contains all running nodes that were shut down gracefully.
+	shutdownNodes map[string]bool
+	// cancelMu is used to synchronize access to forwardCancel, canceledNodes, stoppedNodes and shutdownNodes.
+	cancelMu sync.Mutex
 	// tickets is a concurrency provider that is used to limit the amount of concurrently running tasks.
 	tickets ConcurrencyProvider
 
@@ -33,28 +110,69 @@ type TaskTreeRunner struct {
 	cancelComplete chan bool
 	// hasError is a flag that indicates whether an error occurred during the execution of the task tree.
 	hasError atomic.Bool
-
-	// mutex is used to synchronize access to the status tree.
-	mutex  sync.RWMutex
-	isDone bool
+	// errs contains the errors of all failed nodes by their id.
+	errs map[string]error
+	// errMu is used to synchronize access to errs.
+	errMu sync.Mutex
+	// loose indicates whether independent nodes should continue to run after a node failed.
+	loose bool
+
+	// mutex is used to synchronize access to the status tree and the node states.
+	mutex sync.RWMutex
+	// isDone indicates whether the execution of the task tree finished.
+	isDone atomic.Bool
 }
 
-func NewTreeRunner(root *tasks.TaskTreeNode, p ConcurrencyProvider) *TaskTreeRunner {
+func NewTreeRunner(root *tasks.TaskTreeNode, p ConcurrencyProvider, conf RunnerConfig) *TaskTreeRunner {
 	status := buildStatus(root)
+	statusNodes := map[*tasks.TaskTreeNode]*TreeStatusNode{}
+	mapStatus(root, status, statusNodes)
+	executions := conf.Executions
+	if executions == nil {
+		executions = NewSharedExecutions()
+	}
+
+	states := map[*tasks.TaskTreeNode]*nodeState{}
+	cleanupNodes := map[*tasks.TaskTreeNode]bool{}
+	root.Iterate(func(node *tasks.TaskTreeNode) {
+		states[node] = &nodeState{
+			phase:   phasePre,
+			outcome: outcomeSuccess,
+			pending: len(node.Pre),
+		}
+		for _, cleanup := range append(node.OnError, node.Finally...) {
+			cleanup.Iterate(func(n *tasks.TaskTreeNode) {
+				cleanupNodes[n] = true
+			})
+		}
+	})
 
 	return &TaskTreeRunner{
-		root:       root,
-		status:     RunnerIdle,
-		statusTree: status,
+		root:           root,
+		status:         RunnerIdle,
+		statusTree:     status,
+		statusNodes:    statusNodes,
+		states:         states,
+		cleanupNodes:   cleanupNodes,
+		completedNodes: map[*tasks.TaskTreeNode]bool{},
+		ownedNodes:     map[*tasks.TaskTreeNode]bool{},
+		waitingNodes:   map[*tasks.TaskTreeNode]bool{},
+		executions:     executions,
+		config:         conf,
 
 		scheduledNodes: make(chan *tasks.TaskTreeNode, 16),
 		forwardCancel:  map[string]chan bool{},
+		canceledNodes:  map[string]bool{},
+		stoppedNodes:   map[string]bool{},
+		shutdownNodes:  map[string]bool{},
 		tickets:        p,
 
 		updates:        make(chan *TreeStatusNode, 1000),
 		wasCanceled:    atomic.Bool{},
 		cancel:         make(chan bool),
 		cancelComplete: make(chan bool),
+		errs:           map[string]error{},
+		loose:          conf.Loose,
 
 		mutex: sync.RWMutex{},
 	}
@@ -68,8 +186,13 @@ func (r *TaskTreeRunner) Status() *TreeStatusNode {
 	return r.statusTree
 }
 
+// IsDone reports whether the execution of the task tree finished.
+func (r *TaskTreeRunner) IsDone() bool {
+	return r.isDone.Load()
+}
+
 func (r *TaskTreeRunner) Cancel() {
-	if r.isDone {
+	if r.isDone.Load() {
 		return
 	}
 
@@ -80,46 +203,179 @@ func (r *TaskTreeRunner) Cancel() {
 
 // ShutdownGracefully cancels only long running tasks transitioning those trees into the $post subtree
 func (r *TaskTreeRunner) ShutdownGracefully() {
-	if r.isDone {
+	if r.isDone.Load() {
 		return
 	}
 
 	hasLonRunningNodes := false
-	r.mutex.Lock()
+	r.cancelMu.Lock()
 	r.root.Iterate(func(node *tasks.TaskTreeNode) {
 		if cancel, ok := r.forwardCancel[node.NodeID()]; ok && node.IsLongRunning {
-			cancel <- true
+			r.shutdownNodes[node.NodeID()] = true
+			notifyCancel(cancel)
 			hasLonRunningNodes = true
 		}
 	})
-	r.mutex.Unlock()
+	r.cancelMu.Unlock()
 
 	if !hasLonRunningNodes {
 		r.Cancel()
 	}
 }
 
+// Rerun creates a new runner for the task tree of the finished runner. Nodes whose main task
+// already succeeded are not executed again, thus only failed, canceled or skipped subtrees are executed.
+// The shared executions of these nodes are released, so that they are executed again as well.
+func (r *TaskTreeRunner) Rerun() (*TaskTreeRunner, error) {
+	if !r.isDone.Load() {
+		return nil, ErrRunnerNotDone
+	}
+
+	rerun := NewTreeRunner(r.root, r.tickets, r.config)
+	r.root.Iterate(func(node *tasks.TaskTreeNode) {
+		if status := r.statusNodes[node].Status; status == StatusDone || status == StatusCached {
+			rerun.completedNodes[node] = true
+			statusNode := rerun.statusNodes[node]
+			statusNode.Status = status
+			statusNode.Update()
+		} else if node.Key != "" {
+			r.executions.release(node.Key)
+		}
+	})
+	return rerun, nil
+}
+
+// RestartNode restarts the main task of a running node, a stopped node is started again.
+func (r *TaskTreeRunner) RestartNode(nodeID string) error {
+	node, task, err := r.findControlledNode(nodeID)
+	if err != nil {
+		return err
+	}
+
+	r.cancelMu.Lock()
+	delete(r.stoppedNodes, nodeID)
+	r.cancelMu.Unlock()
+	task.Restart()
+	r.updateTaskStatus(node, StatusRunning)
+	return nil
+}
+
+// StopNode stops the main task of a running node without finishing the node.
+func (r *TaskTreeRunner) StopNode(nodeID string) error {
+	node, task, err := r.findControlledNode(nodeID)
+	if err != nil {
+		return err
+	}
+
+	r.cancelMu.Lock()
+	r.stoppedNodes[nodeID] = true
+	r.cancelMu.Unlock()
+	task.Stop()
+	r.updateTaskStatus(node, StatusCanceled)
+	return nil
+}
+
+// StartNode starts the main task of a stopped node again.
+func (r *TaskTreeRunner) StartNode(nodeID string) error {
+	r.cancelMu.Lock()
+	isStopped := r.stoppedNodes[nodeID]
+	r.cancelMu.Unlock()
+	if !isStopped {
+		return ErrNodeNotStopped
+	}
+	return r.RestartNode(nodeID)
+}
+
+// WriteInput writes the data to the stdin of the main task of a running node.
+func (r *TaskTreeRunner) WriteInput(nodeID string, data []byte) error {
+	node, err := r.findRunningNode(nodeID)
+	if err != nil {
+		return err
+	}
+
+	task, ok := node.Main.(tasks.InputTask)
+	if !ok {
+		return tasks.ErrInputNotSupported
+	}
+	return task.WriteInput(data)
+}
+
+// findRunningNode returns the running node with the id.
+func (r *TaskTreeRunner) findRunningNode(nodeID string) (*tasks.TaskTreeNode, error) {
+	var node *tasks.TaskTreeNode
+	r.root.Iterate(func(n *tasks.TaskTreeNode) {
+		if n.NodeID() == nodeID {
+			node = n
+		}
+	})
+	if node == nil {
+		return nil, ErrNodeNotFound
+	}
+
+	r.cancelMu.Lock()
+	_, isRunning := r.forwardCancel[nodeID]
+	r.cancelMu.Unlock()
+	if !isRunning {
+		return nil, ErrNodeNotRunning
+	}
+	return node, nil
+}
+
+// findControlledNode returns the running node with the id and its controllable main task.
+func (r *TaskTreeRunner) findControlledNode(nodeID string) (*tasks.TaskTreeNode, model.ControlledTask, error) {
+	node, err := r.findRunningNode(nodeID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	task, ok := node.Main.(model.ControlledTask)
+	if !ok {
+		return nil, nil, ErrNodeNotControllable
+	}
+	return node, task, nil
+}
+
 func (r *TaskTreeRunner) updateTaskStatus(node *tasks.TaskTreeNode, status TaskStatus) {
 	r.mutex.Lock()
-	statusNode := findStatus(r.statusTree, node)
+	statusNode := r.statusNodes[node]
 	statusNode.Status = status
 	statusNode.Update()
-	r.updates <- statusNode
+	r.publish(statusNode)
 	r.mutex.Unlock()
 }
 
-func (r *TaskTreeRunner) setError(node *tasks.TaskTreeNode, err error) {
+func (r *TaskTreeRunner) updateRestarts(node *tasks.TaskTreeNode, restarts int) {
 	r.mutex.Lock()
-	statusNode := findStatus(r.statusTree, node)
-	statusNode.Error = err
-	r.hasError.Store(true)
+	statusNode := r.statusNodes[node]
+	statusNode.Restarts = restarts
+	r.publish(statusNode)
 	r.mutex.Unlock()
 }
 
+// publish sends an update of the status node, which reaches all of its parents. The caller must hold the mutex.
+func (r *TaskTreeRunner) publish(node *TreeStatusNode) {
+	for _, snapshot := range node.snapshots() {
+		r.updates <- snapshot
+	}
+}
+
+// setError marks the node as failed. Unless the failure is allowed, this stops the
+// scheduling of new nodes when not running in loose mode.
+func (r *TaskTreeRunner) setError(node *tasks.TaskTreeNode, err error, isAllowed bool) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	statusNode := r.statusNodes[node]
+	statusNode.Error = err
+	statusNode.Status = StatusError
+	statusNode.Update()
+	r.publish(statusNode)
+	if !isAllowed {
+		r.hasError.Store(true)
+	}
+}
+
 func (r *TaskTreeRunner) Start() error {
 	done := make(chan bool, 1)
-	errs := map[string]error{}
-	errMu := sync.Mutex{}
 	wg := sync.WaitGroup{}
 
 	// cleanup
@@ -137,47 +393,72 @@ func (r *TaskTreeRunner) Start() error {
 	// scheduler
 	go func() {
 		for scheduledNode := range r.scheduledNodes {
-			if r.wasCanceled.Load() {
-				break
-			}
-
 			wg.Add(1)
-			taskCancel := make(chan bool, 1)
-			r.mutex.Lock()
-			r.forwardCancel[scheduledNode.NodeID()] = taskCancel
-			r.mutex.Unlock()
-			go func(task *tasks.TaskTreeNode, cancel <-chan bool) {
+			go func(task *tasks.TaskTreeNode) {
+				defer wg.Done()
 				// acquire a ticket to run the task
 				ticket := r.tickets.Acquire()
+				cancel, ok := r.registerCancel(task)
+				if !ok {
+					// the node won't be executed anymore, but its dependents need to be resolved
+					r.tickets.Release(ticket)
+					r.finishMain(task, outcomeCanceled)
+					return
+				}
+
+				fingerprint, isUpToDate := r.checkUpToDate(task)
+				if isUpToDate {
+					r.unregisterCancel(task)
+					r.tickets.Release(ticket)
+					r.updateTaskStatus(task, StatusCached)
+					r.finishMain(task, outcomeSuccess)
+					return
+				}
+
+				if fingerprint != "" {
+					task.UpToDate.Capture(task.Main)
+				}
 				r.updateTaskStatus(task, StatusRunning)
-				if err := task.Main.Run(cancel); err != nil {
-					errMu.Lock()
-					errs[task.NodeID()] = err
-					if !r.hasError.Load() && !r.wasCanceled.Load() {
-						// first node erroring -> close the channel
-						close(r.scheduledNodes)
-					}
-					r.setError(task, err)
-					r.updateTaskStatus(task, StatusError)
-					errMu.Unlock()
-				} else if r.wasCanceled.Load() {
-					r.updateTaskStatus(task, StatusCanceled)
-				} else {
-					r.updateTaskStatus(task, StatusDone)
-					// continue execution
-					if len(errs) == 0 {
-						// TODO: add a mutex to avoid duplicate scheduling
-						r.scheduleNext(task)
-					}
+				stopProbe := make(chan bool)
+				if task.ReadyWhen != nil {
+					ready := task.ReadyWhen.Watch(task.Main, stopProbe)
+					go func() {
+						select {
+						case <-ready:
+							r.markReady(task)
+						case <-stopProbe:
+						}
+					}()
 				}
-				r.mutex.Lock()
-				delete(r.forwardCancel, task.NodeID())
-				r.mutex.Unlock()
+				err := r.runMain(task, cancel)
+				close(stopProbe)
+				wasCanceled := r.unregisterCancel(task)
 				// release the ticket to be used by another channel
 				r.tickets.Release(ticket)
-				wg.Done()
-			}(scheduledNode, taskCancel)
 
+				outcome := outcomeSuccess
+				if wasCanceled {
+					outcome = outcomeCanceled
+					r.updateTaskStatus(task, StatusCanceled)
+				} else if err != nil {
+					outcome = outcomeFailed
+					isAllowed := r.isFailureAllowed(task)
+					if !isAllowed {
+						r.addError(task, err)
+					}
+					r.setError(task, err, isAllowed)
+				} else {
+					if fingerprint != "" {
+						// the up to date check and the artifact cache are best effort only, thus failing to save them does not fail the task
+						if err := task.UpToDate.Save(fingerprint); err != nil {
+							tasks.WriteWarning(task.Main, fmt.Sprintf("failed to save the up to date record: %s", err))
+						}
+					}
+					r.updateTaskStatus(task, StatusDone)
+				}
+				// continue execution
+				r.finishMain(task, outcome)
+			}(scheduledNode)
 		}
 		wg.Done()
 	}()
@@ -188,10 +469,17 @@ func (r *TaskTreeRunner) Start() error {
 		case <-r.cancel:
 			// run was canceled - forward cancel to all tasks
 			r.wasCanceled.Store(true)
-			close(r.scheduledNodes)
+			r.cancelMu.Lock()
+			for id, cancel := range r.forwardCancel {
+				r.canceledNodes[id] = true
+				notifyCancel(cancel)
+			}
+			r.cancelMu.Unlock()
+
+			// nodes waiting for the execution of another runner are canceled as well
 			r.mutex.Lock()
-			for _, cancel := range r.forwardCancel {
-				cancel <- true
+			for node := range r.waitingNodes {
+				r.continueShared(node, sharedResult{status: StatusCanceled, outcome: outcomeCanceled})
 			}
 			r.mutex.Unlock()
 			return
@@ -204,145 +492,455 @@ func (r *TaskTreeRunner) Start() error {
 	// start scheduling
 	wg.Add(1)
 
-	startingNodes := getStartingNodes(r.root)
-	for _, node := range startingNodes {
-		r.scheduledNodes <- node
-	}
+	r.mutex.Lock()
+	r.startSubtree(r.root, nil)
+	r.mutex.Unlock()
 
 	wg.Wait()
 	done <- true
 	close(done)
-	r.isDone = true
+	r.isDone.Store(true)
 
 	if r.wasCanceled.Load() {
 		return tasks.ErrCancelled
 	}
 
-	if len(errs) > 0 {
-		return tasks.NewMultiTaskError(errs)
+	if len(r.errs) > 0 {
+		err := tasks.NewMultiTaskError(r.errs)
+		r.collectSummary(err)
+		return err
 	}
 	return nil
 }
 
-func (r *TaskTreeRunner) scheduleNext(node *tasks.TaskTreeNode) {
-	if r.hasError.Load() || r.wasCanceled.Load() {
-		return // fail fast
+// checkUpToDate computes the fingerprint of a node with an up to date check and reports whether the node
+// can be skipped, either since its outputs are up to date or since they were restored from the artifact cache.
+// The fingerprint is empty if the node has no check. Long running nodes are never skipped.
+func (r *TaskTreeRunner) checkUpToDate(node *tasks.TaskTreeNode) (string, bool) {
+	if node.UpToDate == nil || node.IsLongRunning {
+		return "", false
+	}
+
+	fingerprint, err := node.UpToDate.Fingerprint(node.Main)
+	if err != nil {
+		// the node is executed in order to report the error of the task itself
+		return "", false
+	}
+	if r.config.Force {
+		return fingerprint, false
 	}
+	if node.UpToDate.IsUpToDate(fingerprint) {
+		return fingerprint, true
+	}
+	// a failing restore is treated like a cache miss, thus the task is executed
+	restored, _ := node.UpToDate.Restore(node.Main, fingerprint)
+	return fingerprint, restored
+}
+
+// runMain executes the main task of a node and restarts it according to the restart policy of the node.
+func (r *TaskTreeRunner) runMain(node *tasks.TaskTreeNode, cancel <-chan bool) error {
+	restarts := 0
+	for {
+		err := node.Main.Run(cancel)
+		if node.Restart == nil || r.isStopping(node) || !node.Restart.ShouldRestart(err, restarts) {
+			return err
+		}
+
+		restarts++
+		r.updateRestarts(node, restarts)
+		select {
+		case <-time.After(node.Restart.Delay(restarts)):
+		case <-cancel:
+			// the node was canceled while waiting for the restart
+			return nil
+		}
+	}
+}
 
+// isStopping reports whether the running node received a cancel signal or was shut down gracefully.
+func (r *TaskTreeRunner) isStopping(node *tasks.TaskTreeNode) bool {
+	r.cancelMu.Lock()
+	defer r.cancelMu.Unlock()
+	return r.canceledNodes[node.NodeID()] || r.shutdownNodes[node.NodeID()]
+}
+
+// registerCancel creates the cancel channel for a node that is about to run.
+// It reports false if the node should not be executed anymore.
+func (r *TaskTreeRunner) registerCancel(node *tasks.TaskTreeNode) (<-chan bool, bool) {
+	r.cancelMu.Lock()
+	defer r.cancelMu.Unlock()
+	isStopped := r.wasCanceled.Load() || (r.hasError.Load() && !r.loose)
+	if isStopped && !r.cleanupNodes[node] {
+		// $onError and $finally nodes are executed even after the execution was stopped
+		return nil, false
+	}
+
+	cancel := make(chan bool, 1)
+	r.forwardCancel[node.NodeID()] = cancel
+	return cancel, true
+}
+
+// unregisterCancel removes the cancel channel of a node and reports whether the node was canceled.
+func (r *TaskTreeRunner) unregisterCancel(node *tasks.TaskTreeNode) bool {
+	r.cancelMu.Lock()
+	defer r.cancelMu.Unlock()
+	wasCanceled := r.canceledNodes[node.NodeID()]
+	delete(r.forwardCancel, node.NodeID())
+	delete(r.canceledNodes, node.NodeID())
+	delete(r.stoppedNodes, node.NodeID())
+	delete(r.shutdownNodes, node.NodeID())
+	return wasCanceled
+}
+
+// finishMain continues the execution of a node after its main task finished (or won't be executed).
+func (r *TaskTreeRunner) finishMain(node *tasks.TaskTreeNode, outcome nodeOutcome) {
 	r.mutex.Lock()
 	defer r.mutex.Unlock()
-	statusNode := findStatus(r.statusTree, node)
-	if allDone(r.statusTree) {
-		close(r.scheduledNodes)
-	} else if len(statusNode.PostNodes) > 0 {
-		for _, post := range node.Post {
-			for _, scheduled := range getStartingNodes(post) {
-				r.scheduledNodes <- scheduled
-			}
+	if r.ownedNodes[node] {
+		statusNode := r.statusNodes[node]
+		result := sharedResult{status: statusNode.Status, outcome: outcome, err: statusNode.Error}
+		if outcome == outcomeCanceled {
+			result.status = StatusCanceled
 		}
-	} else if statusNode.IsPre() && allChildrenWithStatus(statusNode.Parent.PreNodes, StatusDone) {
-		r.scheduledNodes <- node.Parent
-	} else if statusNode.IsPost() && allChildrenWithStatus(statusNode.Parent.PostNodes, StatusDone) {
-		r.scheduledNodes <- node.Parent.Parent
+		r.executions.resolve(node.Key, result)
+	}
+	if r.states[node].ready {
+		// the dependents of the node were already executed once the node became ready
+		return
+	}
+	r.continueAfterMain(node, outcome)
+}
+
+// markReady continues the execution of a node whose main task is still running but passed its readiness probe.
+func (r *TaskTreeRunner) markReady(node *tasks.TaskTreeNode) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	state := r.states[node]
+	if state.phase != phaseMain || state.ready {
+		// the main task already finished
+		return
 	}
+	state.ready = true
+	if r.ownedNodes[node] {
+		// nodes waiting for a ready node continue as if it finished
+		r.executions.resolve(node.Key, sharedResult{status: StatusDone, outcome: outcomeSuccess})
+	}
+	r.continueAfterMain(node, outcomeSuccess)
 }
 
-func getStartingNodes(root *tasks.TaskTreeNode) []*tasks.TaskTreeNode {
-	if len(root.Pre) == 0 {
-		return []*tasks.TaskTreeNode{root}
+// continueShared continues the execution of a waiting node with the result of the node executing its main task.
+// The caller must hold the mutex.
+func (r *TaskTreeRunner) continueShared(node *tasks.TaskTreeNode, result sharedResult) {
+	if !r.waitingNodes[node] {
+		// the node was already canceled
+		return
 	}
+	delete(r.waitingNodes, node)
 
-	allNodes := []*tasks.TaskTreeNode{}
-	for _, pre := range root.Pre {
-		allNodes = append(allNodes, getStartingNodes(pre)...)
+	statusNode := r.statusNodes[node]
+	statusNode.Status = result.status
+	statusNode.Error = result.err
+	statusNode.Update()
+	r.publish(statusNode)
+	if result.outcome == outcomeFailed && !r.isFailureAllowed(node) {
+		r.hasError.Store(true)
+		r.addError(node, result.err)
 	}
+	r.continueAfterMain(node, result.outcome)
+}
 
-	return allNodes
+// addError records the error of a failed node.
+func (r *TaskTreeRunner) addError(node *tasks.TaskTreeNode, err error) {
+	r.errMu.Lock()
+	defer r.errMu.Unlock()
+	r.errs[node.NodeID()] = err
 }
 
-func buildStatus(root *tasks.TaskTreeNode) *TreeStatusNode {
-	status := &TreeStatusNode{
-		Name:             root.Name,
-		AggregatedStatus: StatusPending,
-		MainName:         root.Main.Name(),
-		Status:           StatusPending,
-		PreNodes:         []*TreeStatusNode{},
-		PostNodes:        []*TreeStatusNode{},
-		ID:               root.NodeID(),
+// continueAfterMain schedules the $post or $onError nodes of a node. The caller must hold the mutex.
+func (r *TaskTreeRunner) continueAfterMain(node *tasks.TaskTreeNode, outcome nodeOutcome) {
+	state := r.states[node]
+	state.outcome = max(state.outcome, outcome)
+
+	if state.outcome != outcomeSuccess {
+		if r.loose {
+			r.skipSubtrees(node.Post)
+		}
+		r.enterOnError(node)
+		return
 	}
 
-	for _, pre := range root.Pre {
-		preStatus := buildStatus(pre)
-		preStatus.Parent = status
-		status.PreNodes = append(status.PreNodes, preStatus)
+	r.skipSubtrees(node.OnError)
+	state.phase = phasePost
+	state.pending = len(node.Post)
+	if state.pending == 0 {
+		r.enterFinally(node)
+		return
 	}
+	for _, post := range node.Post {
+		r.startSubtree(post, node)
+	}
+}
 
-	for _, post := range root.Post {
-		postStatus := buildStatus(post)
-		postStatus.Parent = status
-		status.PostNodes = append(status.PostNodes, postStatus)
+// enterOnError schedules the $onError nodes if the main task or a $pre node failed.
+func (r *TaskTreeRunner) enterOnError(node *tasks.TaskTreeNode) {
+	state := r.states[node]
+	if state.outcome != outcomeFailed || len(node.OnError) == 0 {
+		r.skipSubtrees(node.OnError)
+		r.enterFinally(node)
+		return
 	}
 
-	return status
+	state.phase = phaseOnError
+	state.pending = len(node.OnError)
+	for _, onError := range node.OnError {
+		r.startSubtree(onError, node)
+	}
+}
+
+// enterFinally schedules the $finally nodes of a node.
+func (r *TaskTreeRunner) enterFinally(node *tasks.TaskTreeNode) {
+	state := r.states[node]
+	state.phase = phaseFinally
+	state.pending = len(node.Finally)
+	if state.pending == 0 {
+		r.finishNode(node)
+		return
+	}
+	for _, finally := range node.Finally {
+		r.startSubtree(finally, node)
+	}
 }
 
-func allDone(status *TreeStatusNode) bool {
-	if status.Status == StatusPending || status.Status == StatusRunning || status.Status == StatusScheduled {
-		return false
+// finishNode reports the outcome of a completely executed subtree to all of its dependents.
+func (r *TaskTreeRunner) finishNode(node *tasks.TaskTreeNode) {
+	state := r.states[node]
+	state.phase = phaseFinished
+	dependents := state.dependents
+	state.dependents = nil
+
+	if node == r.root {
+		// the whole tree is finished
+		r.closeScheduledNodes()
+		return
 	}
+	for _, dependent := range dependents {
+		r.finishSubtree(dependent, r.finishedOutcome(node))
+	}
+}
 
-	for _, pre := range status.PreNodes {
-		if !allDone(pre) {
-			return false
+// finishedOutcome returns the outcome a finished subtree reports to its dependents.
+func (r *TaskTreeRunner) finishedOutcome(node *tasks.TaskTreeNode) nodeOutcome {
+	outcome := r.states[node].outcome
+	if outcome == outcomeFailed && node.AllowFailure {
+		return outcomeSuccess
+	}
+	return outcome
+}
+
+// finishSubtree is called when a child subtree of the node finished.
+func (r *TaskTreeRunner) finishSubtree(node *tasks.TaskTreeNode, outcome nodeOutcome) {
+	state := r.states[node]
+	state.outcome = max(state.outcome, outcome)
+	state.pending--
+	if state.pending > 0 {
+		return
+	}
+
+	switch state.phase {
+	case phasePre:
+		if state.outcome == outcomeSuccess {
+			state.phase = phaseMain
+			r.queue(node)
+			return
 		}
+		if r.loose && state.outcome == outcomeFailed {
+			// a failed $pre node prevents the main task from running
+			r.skipNode(r.statusNodes[node])
+		}
+		r.continueAfterMain(node, state.outcome)
+	case phasePost, phaseOnError:
+		r.enterFinally(node)
+	case phaseFinally:
+		r.finishNode(node)
 	}
+}
 
-	for _, post := range status.PostNodes {
-		if !allDone(post) {
-			return false
+// startSubtree schedules all nodes of the subtree that can be executed immediately and registers the dependent,
+// which is notified once the subtree finished. A node with several dependents is only started once, dependents
+// of an already finished node are notified immediately. The caller must hold the mutex.
+func (r *TaskTreeRunner) startSubtree(node, dependent *tasks.TaskTreeNode) {
+	state := r.states[node]
+	if dependent != nil {
+		if state.phase == phaseFinished {
+			r.finishSubtree(dependent, r.finishedOutcome(node))
+			return
 		}
+		state.dependents = append(state.dependents, dependent)
+	}
+	if state.started {
+		return
 	}
 
-	return true
+	state.started = true
+	if len(node.Pre) == 0 {
+		r.queue(node)
+		return
+	}
+	for _, pre := range node.Pre {
+		r.startSubtree(pre, node)
+	}
 }
 
-func findStatus(status *TreeStatusNode, target *tasks.TaskTreeNode) *TreeStatusNode {
-	path := []string{target.Name}
-	for target.Parent != nil {
-		path = append([]string{target.Parent.Name}, path...)
-		target = target.Parent
-	}
-	if len(path) == 1 {
-		return status
+// queue marks the node as scheduled and passes it to the scheduler. The caller must hold the mutex.
+// A shared node whose main task is already executed by another node waits for its result instead.
+func (r *TaskTreeRunner) queue(node *tasks.TaskTreeNode) {
+	r.states[node].phase = phaseMain
+	if r.completedNodes[node] {
+		// the main task already succeeded in a previous run
+		r.continueAfterMain(node, outcomeSuccess)
+		return
 	}
 
-	current := status
-outer:
-	for i, name := range path[1:] {
-		if current.Name == name {
-			return current
-		}
+	statusNode := r.statusNodes[node]
+	statusNode.Status = StatusScheduled
+	statusNode.Update()
 
-		for _, pre := range current.PreNodes {
-			if pre.Name == name {
-				if i == len(path)-2 {
-					return pre
-				}
-				current = pre
-				continue outer
+	if node.Key != "" {
+		isOwner, result := r.executions.claim(node.Key, func(result sharedResult) {
+			r.mutex.Lock()
+			defer r.mutex.Unlock()
+			r.continueShared(node, result)
+		})
+		if !isOwner {
+			// the status of the node is published by the runner executing it
+			r.waitingNodes[node] = true
+			if result != nil {
+				r.continueShared(node, *result)
 			}
+			return
 		}
+		r.ownedNodes[node] = true
+	}
+	r.publish(statusNode)
+	r.scheduledNodes <- node
+}
 
-		for _, post := range current.PostNodes {
-			if post.Name == name {
-				if i == len(path)-2 {
-					return post
-				}
-				current = post
-				continue outer
-			}
+// closeScheduledNodes stops the scheduling of new nodes. The caller must hold the mutex.
+func (r *TaskTreeRunner) closeScheduledNodes() {
+	if !r.isClosed {
+		r.isClosed = true
+		close(r.scheduledNodes)
+	}
+}
+
+func (r *TaskTreeRunner) skipSubtrees(nodes []*tasks.TaskTreeNode) {
+	for _, node := range nodes {
+		r.statusNodes[node].Iterate(r.skipNode)
+	}
+}
+
+func (r *TaskTreeRunner) skipNode(node *TreeStatusNode) {
+	if node.Status != StatusPending {
+		return
+	}
+	node.Status = StatusSkipped
+	node.Update()
+	r.publish(node)
+}
+
+// collectSummary adds all skipped and never started nodes to the error.
+func (r *TaskTreeRunner) collectSummary(err *tasks.MultiTaskError) {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+	r.root.Iterate(func(node *tasks.TaskTreeNode) {
+		// nodes without a task and without children (like the nodes of compounds) do nothing on their own
+		if tasks.IsEmptyTask(node.Main) && node.IsLeaf() {
+			return
+		}
+		statusNode := r.statusNodes[node]
+		switch statusNode.Status {
+		case StatusSkipped:
+			err.Skipped = append(err.Skipped, statusNode.ID)
+		case StatusPending, StatusScheduled:
+			err.NotStarted = append(err.NotStarted, statusNode.ID)
+		}
+	})
+}
+
+// isFailureAllowed reports whether the node or one of its ancestors within the tree of the runner tolerates failures.
+func (r *TaskTreeRunner) isFailureAllowed(node *tasks.TaskTreeNode) bool {
+	for current := node; current != nil; current = current.Parent {
+		if current.AllowFailure {
+			return true
+		}
+		if current == r.root {
+			break
 		}
-		break
 	}
-	return nil
+	return false
+}
+
+func notifyCancel(cancel chan bool) {
+	select {
+	case cancel <- true:
+	default:
+		// the task was already notified
+	}
+}
+
+// buildStatus mirrors the task tree as status tree. A node with several parents is mirrored
+// by a single status node with several parents as well.
+func buildStatus(root *tasks.TaskTreeNode) *TreeStatusNode {
+	return buildStatusNode(root, map[*tasks.TaskTreeNode]*TreeStatusNode{})
+}
+
+func buildStatusNode(root *tasks.TaskTreeNode, nodes map[*tasks.TaskTreeNode]*TreeStatusNode) *TreeStatusNode {
+	if status, ok := nodes[root]; ok {
+		return status
+	}
+
+	status := &TreeStatusNode{
+		Name:             root.Name,
+		AggregatedStatus: StatusPending,
+		MainName:         root.Main.Name(),
+		Status:           StatusPending,
+		PreNodes:         []*TreeStatusNode{},
+		PostNodes:        []*TreeStatusNode{},
+		OnErrorNodes:     []*TreeStatusNode{},
+		FinallyNodes:     []*TreeStatusNode{},
+		ID:               root.NodeID(),
+		AllowFailure:     root.AllowFailure,
+	}
+	nodes[root] = status
+
+	for _, pre := range root.Pre {
+		status.PreNodes = append(status.PreNodes, status.adopt(buildStatusNode(pre, nodes)))
+	}
+	for _, post := range root.Post {
+		status.PostNodes = append(status.PostNodes, status.adopt(buildStatusNode(post, nodes)))
+	}
+	for _, onError := range root.OnError {
+		status.OnErrorNodes = append(status.OnErrorNodes, status.adopt(buildStatusNode(onError, nodes)))
+	}
+	for _, finally := range root.Finally {
+		status.FinallyNodes = append(status.FinallyNodes, status.adopt(buildStatusNode(finally, nodes)))
+	}
+	return status
+}
+
+// mapStatus maps each node of the task tree to its node in the mirrored status tree.
+func mapStatus(root *tasks.TaskTreeNode, status *TreeStatusNode, nodes map[*tasks.TaskTreeNode]*TreeStatusNode) {
+	nodes[root] = status
+	for i, pre := range root.Pre {
+		mapStatus(pre, status.PreNodes[i], nodes)
+	}
+	for i, post := range root.Post {
+		mapStatus(post, status.PostNodes[i], nodes)
+	}
+	for i, onError := range root.OnError {
+		mapStatus(onError, status.OnErrorNodes[i], nodes)
+	}
+	for i, finally := range root.Finally {
+		mapStatus(finally, status.FinallyNodes[i], nodes)
+	}
 }
@@ -1,10 +1,19 @@
 package runner
 
 import (
+	"errors"
+	"fmt"
 	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strings"
+	"sync"
+	"sync/atomic"
 	"testing"
+	"time"
 
-	"github.com/zwoo-hq/zwooc/pkg/helper"
 	"github.com/zwoo-hq/zwooc/pkg/tasks"
 )
 
@@ -82,94 +91,812 @@ func TestBuildStatus(t *testing.T) {
 	})
 }
 
-func TestFindStatus(t *testing.T) {
-	t.Run("finds status node for task", func(t *testing.T) {
-		tree.Pre[0].Parent = tree
-		tree.Pre[0].Pre[0].Parent = tree.Pre[0]
-		tree.Post[0].Parent = tree
-		tree.Post[0].Post[0].Parent = tree.Post[0]
-
+func TestMapStatus(t *testing.T) {
+	t.Run("maps task nodes to status nodes", func(t *testing.T) {
 		status := buildStatus(tree)
+		nodes := map[*tasks.TaskTreeNode]*TreeStatusNode{}
+		mapStatus(tree, status, nodes)
 
-		node := findStatus(status, tree)
-		if node.Name != "root" {
-			t.Errorf("Expected root, got %s", node.Name)
+		expected := map[*tasks.TaskTreeNode]string{
+			tree:                 "root",
+			tree.Pre[0]:          "pre1",
+			tree.Post[0]:         "post1",
+			tree.Pre[0].Pre[0]:   "pre1-1",
+			tree.Post[0].Post[0]: "post1-1",
+		}
+		for node, name := range expected {
+			if nodes[node].Name != name {
+				t.Errorf("Expected %s, got %s", name, nodes[node].Name)
+			}
 		}
+	})
+}
+
+func createLooseTestTree(executed *sync.Map) *tasks.TaskTreeNode {
+	newTask := func(name string, fail bool) tasks.Task {
+		return tasks.NewTask(name, func(cancel <-chan bool, out io.Writer) error {
+			executed.Store(name, true)
+			if fail {
+				return errors.New("failed")
+			}
+			return nil
+		})
+	}
+
+	root := tasks.NewTaskTree("root", newTask("main", false), false)
+	failing := tasks.NewTaskTree("failing", newTask("failingt", true), false)
+	failing.AddPostChild(tasks.NewTaskTree("failing-post", newTask("failing-postt", false), false))
+	root.AddPreChild(failing, tasks.NewTaskTree("independent", newTask("independentt", false), false))
+	root.AddPostChild(tasks.NewTaskTree("post", newTask("postt", false), false))
+	return root
+}
 
-		node = findStatus(status, tree.Pre[0])
-		if node.Name != "pre1" {
-			t.Errorf("Expected pre1, got %s", node.Name)
+func TestTreeRunnerLoose(t *testing.T) {
+	t.Run("continues independent nodes in loose mode", func(t *testing.T) {
+		executed := &sync.Map{}
+		root := createLooseTestTree(executed)
+		r := NewTreeRunner(root, NewSharedProvider(1), RunnerConfig{Loose: true})
+		go func() {
+			for range r.Updates() {
+			}
+		}()
+
+		err := r.Start()
+		var multiErr *tasks.MultiTaskError
+		if !errors.As(err, &multiErr) {
+			t.Fatalf("Expected MultiTaskError, got %v", err)
+		}
+		if _, ok := multiErr.Errors["root/failing"]; !ok || len(multiErr.Errors) != 1 {
+			t.Errorf("Expected root/failing to fail, got %v", multiErr.Errors)
+		}
+		if _, ok := executed.Load("independentt"); !ok {
+			t.Errorf("Expected independent node to be executed")
+		}
+		for _, name := range []string{"main", "postt", "failing-postt"} {
+			if _, ok := executed.Load(name); ok {
+				t.Errorf("Expected %s not to be executed", name)
+			}
 		}
 
-		node = findStatus(status, tree.Post[0])
-		if node.Name != "post1" {
-			t.Errorf("Expected post1, got %s", node.Name)
+		expectedSkipped := []string{"root/failing/failing-post", "root", "root/post"}
+		if len(multiErr.Skipped) != len(expectedSkipped) {
+			t.Errorf("Expected skipped %v, got %v", expectedSkipped, multiErr.Skipped)
+		}
+		for _, id := range expectedSkipped {
+			if !slices.Contains(multiErr.Skipped, id) {
+				t.Errorf("Expected %s to be skipped, got %v", id, multiErr.Skipped)
+			}
 		}
+		if len(multiErr.NotStarted) != 0 {
+			t.Errorf("Expected no unstarted nodes, got %v", multiErr.NotStarted)
+		}
+	})
+
+	t.Run("stops scheduling in strict mode", func(t *testing.T) {
+		executed := &sync.Map{}
+		root := createLooseTestTree(executed)
+		r := NewTreeRunner(root, NewSharedProvider(1), RunnerConfig{})
+		go func() {
+			for range r.Updates() {
+			}
+		}()
 
-		node = findStatus(status, tree.Pre[0].Pre[0])
-		if node.Name != "pre1-1" {
-			t.Errorf("Expected pre1-1, got %s", node.Name)
+		err := r.Start()
+		var multiErr *tasks.MultiTaskError
+		if !errors.As(err, &multiErr) {
+			t.Fatalf("Expected MultiTaskError, got %v", err)
+		}
+		if len(multiErr.Skipped) != 0 {
+			t.Errorf("Expected no skipped nodes, got %v", multiErr.Skipped)
+		}
+		for _, id := range []string{"root", "root/post", "root/failing/failing-post"} {
+			if !slices.Contains(multiErr.NotStarted, id) {
+				t.Errorf("Expected %s to be not started, got %v", id, multiErr.NotStarted)
+			}
 		}
+	})
+
+	t.Run("excludes empty nodes from the summary", func(t *testing.T) {
+		executed := &sync.Map{}
+		root := createLooseTestTree(executed)
+		root.AddPostChild(tasks.NewTaskTree("empty", tasks.Empty(), false))
+		for _, loose := range []bool{true, false} {
+			r := NewTreeRunner(root, NewSharedProvider(1), RunnerConfig{Loose: loose})
+			go func() {
+				for range r.Updates() {
+				}
+			}()
 
-		node = findStatus(status, tree.Post[0].Post[0])
-		if node.Name != "post1-1" {
-			t.Errorf("Expected post1-1, got %s", node.Name)
+			err := r.Start()
+			var multiErr *tasks.MultiTaskError
+			if !errors.As(err, &multiErr) {
+				t.Fatalf("Expected MultiTaskError, got %v", err)
+			}
+			if slices.Contains(multiErr.Skipped, "root/empty") || slices.Contains(multiErr.NotStarted, "root/empty") {
+				t.Errorf("Expected the empty node to be excluded, got skipped %v and not started %v", multiErr.Skipped, multiErr.NotStarted)
+			}
 		}
 	})
 }
 
-func TestGetStartingNodes(t *testing.T) {
-	t.Run("gets starting nodes from tree", func(t *testing.T) {
-		tree := &tasks.TaskTreeNode{
-			Name: "root",
-			Pre: []*tasks.TaskTreeNode{
-				{
-					Name: "pre1",
-				},
-				{
-					Name: "pre2",
-					Pre: []*tasks.TaskTreeNode{
-						{
-							Name: "pre2-1",
-						},
-						{
-							Name: "pre2-2",
-						},
-					},
-				},
-				{
-					Name: "pre3",
-					Pre: []*tasks.TaskTreeNode{
-						{
-							Name: "pre3-1",
-						},
-						{
-							Name: "pre3-2",
-							Pre: []*tasks.TaskTreeNode{
-								{
-									Name: "pre3-2-1",
-								},
-							},
-						},
-					},
-				},
-			},
+func TestTreeRunnerStart(t *testing.T) {
+	t.Run("runs all nodes of the tree in order", func(t *testing.T) {
+		order := []string{}
+		mu := sync.Mutex{}
+		newTask := func(name string) tasks.Task {
+			return tasks.NewTask(name, func(cancel <-chan bool, out io.Writer) error {
+				mu.Lock()
+				order = append(order, name)
+				mu.Unlock()
+				return nil
+			})
+		}
+
+		root := tasks.NewTaskTree("root", newTask("main"), false)
+		pre := tasks.NewTaskTree("pre", newTask("pre"), false)
+		pre.AddPreChild(tasks.NewTaskTree("pre-pre", newTask("pre-pre"), false))
+		pre.AddPostChild(tasks.NewTaskTree("pre-post", newTask("pre-post"), false))
+		root.AddPreChild(pre)
+		root.AddPostChild(tasks.NewTaskTree("post", newTask("post"), false))
+
+		r := NewTreeRunner(root, NewSharedProvider(2), RunnerConfig{})
+		go func() {
+			for range r.Updates() {
+			}
+		}()
+
+		if err := r.Start(); err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		expected := []string{"pre-pre", "pre", "pre-post", "main", "post"}
+		if !slices.Equal(order, expected) {
+			t.Errorf("Expected %v, got %v", expected, order)
+		}
+		if r.Status().AggregatedStatus != StatusDone {
+			t.Errorf("Expected done, got %d", r.Status().AggregatedStatus)
+		}
+	})
+}
+
+func createHookTestTree(executed *sync.Map, failMain bool) *tasks.TaskTreeNode {
+	newTask := func(name string, fail bool) tasks.Task {
+		return tasks.NewTask(name, func(cancel <-chan bool, out io.Writer) error {
+			executed.Store(name, true)
+			if fail {
+				return errors.New("failed")
+			}
+			return nil
+		})
+	}
+
+	root := tasks.NewTaskTree("root", newTask("main", failMain), false)
+	allowed := tasks.NewTaskTree("allowed", newTask("allowedt", true), false)
+	allowed.AllowFailure = true
+	root.AddPreChild(allowed)
+	root.AddPostChild(tasks.NewTaskTree("post", newTask("postt", false), false))
+	root.AddOnErrorChild(tasks.NewTaskTree("onError", newTask("onErrort", false), false))
+	root.AddFinallyChild(tasks.NewTaskTree("finally", newTask("finallyt", false), false))
+	return root
+}
+
+func TestTreeRunnerHooks(t *testing.T) {
+	t.Run("tolerates allowed failures and skips $onError", func(t *testing.T) {
+		executed := &sync.Map{}
+		r := NewTreeRunner(createHookTestTree(executed, false), NewSharedProvider(1), RunnerConfig{})
+		go func() {
+			for range r.Updates() {
+			}
+		}()
+
+		if err := r.Start(); err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		for _, name := range []string{"allowedt", "main", "postt", "finallyt"} {
+			if _, ok := executed.Load(name); !ok {
+				t.Errorf("Expected %s to be executed", name)
+			}
+		}
+		if _, ok := executed.Load("onErrort"); ok {
+			t.Errorf("Expected $onError not to be executed")
+		}
+		if r.Status().AggregatedStatus != StatusDone {
+			t.Errorf("Expected done, got %d", r.Status().AggregatedStatus)
+		}
+	})
+
+	t.Run("runs $onError and $finally after a failure", func(t *testing.T) {
+		executed := &sync.Map{}
+		r := NewTreeRunner(createHookTestTree(executed, true), NewSharedProvider(1), RunnerConfig{})
+		go func() {
+			for range r.Updates() {
+			}
+		}()
+
+		err := r.Start()
+		var multiErr *tasks.MultiTaskError
+		if !errors.As(err, &multiErr) {
+			t.Fatalf("Expected MultiTaskError, got %v", err)
+		}
+		if _, ok := multiErr.Errors["root"]; !ok || len(multiErr.Errors) != 1 {
+			t.Errorf("Expected only root to fail, got %v", multiErr.Errors)
+		}
+		for _, name := range []string{"onErrort", "finallyt"} {
+			if _, ok := executed.Load(name); !ok {
+				t.Errorf("Expected %s to be executed", name)
+			}
+		}
+		if _, ok := executed.Load("postt"); ok {
+			t.Errorf("Expected $post not to be executed")
+		}
+	})
+}
+
+func TestTreeRunnerReadiness(t *testing.T) {
+	t.Run("runs dependents once a long running node is ready", func(t *testing.T) {
+		dependentStarted := make(chan bool)
+		server := tasks.NewTaskTree("server", tasks.NewTask("servert", func(cancel <-chan bool, out io.Writer) error {
+			out.Write([]byte("starting\nlistening on port 8080\n"))
+			// the server keeps running until its dependent was executed
+			<-dependentStarted
+			return nil
+		}), true)
+		server.ReadyWhen = &tasks.ReadinessProbe{Output: regexp.MustCompile(`listening on port \d+`)}
+
+		root := tasks.NewTaskTree("root", tasks.NewTask("client", func(cancel <-chan bool, out io.Writer) error {
+			close(dependentStarted)
+			return nil
+		}), false)
+		root.AddPreChild(server)
+
+		r := NewTreeRunner(root, NewSharedProvider(2), RunnerConfig{})
+		go func() {
+			for range r.Updates() {
+			}
+		}()
+
+		errChan := make(chan error, 1)
+		go func() {
+			errChan <- r.Start()
+		}()
+
+		select {
+		case err := <-errChan:
+			if err != nil {
+				t.Fatalf("Expected no error, got %v", err)
+			}
+		case <-time.After(5 * time.Second):
+			t.Fatalf("Expected dependent to start once the server is ready")
+		}
+		if r.Status().AggregatedStatus != StatusDone {
+			t.Errorf("Expected done, got %d", r.Status().AggregatedStatus)
+		}
+	})
+}
+
+func TestTreeRunnerRestart(t *testing.T) {
+	t.Run("restarts failed tasks", func(t *testing.T) {
+		runs := 0
+		root := tasks.NewTaskTree("root", tasks.NewTask("main", func(cancel <-chan bool, out io.Writer) error {
+			runs++
+			if runs < 3 {
+				return errors.New("crashed")
+			}
+			return nil
+		}), true)
+		root.Restart = &tasks.RestartPolicy{Mode: tasks.RestartOnFailure, Backoff: time.Millisecond}
+
+		r := NewTreeRunner(root, NewSharedProvider(1), RunnerConfig{})
+		go func() {
+			for range r.Updates() {
+			}
+		}()
+
+		if err := r.Start(); err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		if runs != 3 {
+			t.Errorf("Expected 3 runs, got %d", runs)
+		}
+		if r.Status().Restarts != 2 {
+			t.Errorf("Expected 2 restarts, got %d", r.Status().Restarts)
+		}
+	})
+
+	t.Run("stops after max attempts", func(t *testing.T) {
+		runs := 0
+		root := tasks.NewTaskTree("root", tasks.NewTask("main", func(cancel <-chan bool, out io.Writer) error {
+			runs++
+			return errors.New("crashed")
+		}), true)
+		root.Restart = &tasks.RestartPolicy{Mode: tasks.RestartAlways, MaxAttempts: 2, Backoff: time.Millisecond}
+
+		r := NewTreeRunner(root, NewSharedProvider(1), RunnerConfig{})
+		go func() {
+			for range r.Updates() {
+			}
+		}()
+
+		if err := r.Start(); err == nil {
+			t.Fatalf("Expected an error")
+		}
+		if runs != 3 {
+			t.Errorf("Expected 3 runs, got %d", runs)
+		}
+	})
+
+	t.Run("doesn't restart after a graceful shutdown", func(t *testing.T) {
+		var runs atomic.Int32
+		started := make(chan bool, 10)
+		root := tasks.NewTaskTree("root", tasks.NewTask("main", func(cancel <-chan bool, out io.Writer) error {
+			runs.Add(1)
+			started <- true
+			<-cancel
+			return nil
+		}), true)
+		root.Restart = &tasks.RestartPolicy{Mode: tasks.RestartAlways, Backoff: time.Millisecond}
+
+		r := NewTreeRunner(root, NewSharedProvider(1), RunnerConfig{})
+		go func() {
+			for range r.Updates() {
+			}
+		}()
+
+		errChan := make(chan error, 1)
+		go func() {
+			errChan <- r.Start()
+		}()
+		<-started
+		r.ShutdownGracefully()
+
+		select {
+		case <-errChan:
+		case <-time.After(5 * time.Second):
+			t.Fatalf("Expected the runner to finish after a graceful shutdown")
+		}
+		if runs.Load() != 1 {
+			t.Errorf("Expected 1 run, got %d", runs.Load())
+		}
+	})
+}
+
+func TestTreeRunnerRerun(t *testing.T) {
+	t.Run("reruns failed subtrees only", func(t *testing.T) {
+		runs := map[string]int{}
+		mu := sync.Mutex{}
+		newTask := func(name string, failFirst bool) tasks.Task {
+			return tasks.NewTask(name, func(cancel <-chan bool, out io.Writer) error {
+				mu.Lock()
+				defer mu.Unlock()
+				runs[name]++
+				if failFirst && runs[name] == 1 {
+					return errors.New("flaky")
+				}
+				return nil
+			})
+		}
+
+		root := tasks.NewTaskTree("root", newTask("main", false), false)
+		root.AddPreChild(
+			tasks.NewTaskTree("stable", newTask("stable", false), false),
+			tasks.NewTaskTree("flaky", newTask("flaky", true), false),
+		)
+
+		r := NewTreeRunner(root, NewSharedProvider(1), RunnerConfig{})
+		if _, err := r.Rerun(); !errors.Is(err, ErrRunnerNotDone) {
+			t.Errorf("Expected ErrRunnerNotDone, got %v", err)
+		}
+		go func() {
+			for range r.Updates() {
+			}
+		}()
+		if err := r.Start(); err == nil {
+			t.Fatalf("Expected an error")
 		}
 
-		startingNodes := getStartingNodes(tree)
-		expected := []string{"pre1", "pre2-1", "pre2-2", "pre3-1", "pre3-2-1"}
+		rerun, err := r.Rerun()
+		if err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		go func() {
+			for range rerun.Updates() {
+			}
+		}()
+		if err := rerun.Start(); err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
 
-		if len(expected) != len(startingNodes) {
-			t.Errorf("Expected len %d, got %d", len(expected), len(startingNodes))
+		expected := map[string]int{"stable": 1, "flaky": 2, "main": 1}
+		for name, count := range expected {
+			if runs[name] != count {
+				t.Errorf("Expected %s to run %d times, got %d", name, count, runs[name])
+			}
+		}
+		if rerun.Status().AggregatedStatus != StatusDone {
+			t.Errorf("Expected done, got %d", rerun.Status().AggregatedStatus)
 		}
+	})
+}
 
-		for _, expectedName := range expected {
-			if _, found := helper.FindBy(startingNodes, func(node *tasks.TaskTreeNode) bool {
-				return node.Name == expectedName
-			}); !found {
-				t.Errorf("Expected %s, got <not found>", expectedName)
+func TestTreeRunnerControlNodes(t *testing.T) {
+	t.Run("stops and starts running nodes", func(t *testing.T) {
+		started := make(chan bool, 4)
+		mainTask, _ := tasks.NewControlledTask("run", func(mode string) (tasks.Task, error) {
+			return tasks.NewTask("server", func(cancel <-chan bool, out io.Writer) error {
+				started <- true
+				<-cancel
+				return nil
+			}), nil
+		})
+		root := tasks.NewTaskTree("root", mainTask, true)
+		r := NewTreeRunner(root, NewSharedProvider(1), RunnerConfig{})
+		go func() {
+			for range r.Updates() {
 			}
+		}()
+
+		if err := r.StopNode("root"); !errors.Is(err, ErrNodeNotRunning) {
+			t.Errorf("Expected ErrNodeNotRunning, got %v", err)
+		}
+		if err := r.StopNode("unknown"); !errors.Is(err, ErrNodeNotFound) {
+			t.Errorf("Expected ErrNodeNotFound, got %v", err)
+		}
+
+		errChan := make(chan error, 1)
+		go func() {
+			errChan <- r.Start()
+		}()
+		<-started
+
+		if err := r.StartNode("root"); !errors.Is(err, ErrNodeNotStopped) {
+			t.Errorf("Expected ErrNodeNotStopped, got %v", err)
+		}
+		if err := r.StopNode("root"); err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		if err := r.StartNode("root"); err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		<-started
+		if err := r.RestartNode("root"); err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		<-started
+
+		r.Cancel()
+		if err := <-errChan; !errors.Is(err, tasks.ErrCancelled) {
+			t.Errorf("Expected ErrCancelled, got %v", err)
+		}
+	})
+}
+
+type inputTask struct {
+	tasks.Task
+	input chan []byte
+}
+
+func (t inputTask) WriteInput(data []byte) error {
+	t.input <- data
+	return nil
+}
+
+func TestTreeRunnerWriteInput(t *testing.T) {
+	input := make(chan []byte, 1)
+	started := make(chan bool, 1)
+	mainTask := inputTask{
+		Task: tasks.NewTask("server", func(cancel <-chan bool, out io.Writer) error {
+			started <- true
+			<-cancel
+			return nil
+		}),
+		input: input,
+	}
+	root := tasks.NewTaskTree("root", mainTask, true)
+	r := NewTreeRunner(root, NewSharedProvider(1), RunnerConfig{})
+	go func() {
+		for range r.Updates() {
 		}
+	}()
 
+	if err := r.WriteInput("root", []byte("r\n")); !errors.Is(err, ErrNodeNotRunning) {
+		t.Errorf("Expected ErrNodeNotRunning, got %v", err)
+	}
+
+	errChan := make(chan error, 1)
+	go func() {
+		errChan <- r.Start()
+	}()
+	<-started
+
+	if err := r.WriteInput("root", []byte("r\n")); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if data := <-input; string(data) != "r\n" {
+		t.Errorf("Expected input 'r\\n', got %q", data)
+	}
+
+	r.Cancel()
+	<-errChan
+}
+
+func TestTreeRunnerUpToDate(t *testing.T) {
+	dir := t.TempDir()
+	input := filepath.Join(dir, "input.txt")
+	os.WriteFile(input, []byte("v1"), 0644)
+
+	runs := 0
+	root := tasks.NewTaskTree("root", tasks.NewTask("build", func(cancel <-chan bool, out io.Writer) error {
+		runs++
+		return nil
+	}), false)
+	root.UpToDate = &tasks.UpToDateCheck{
+		Directory: dir,
+		Inputs:    []string{"*.txt"},
+		Record:    filepath.Join(dir, ".zwooc", "root.json"),
+	}
+
+	run := func(config RunnerConfig) TaskStatus {
+		r := NewTreeRunner(root, NewSharedProvider(1), config)
+		go func() {
+			for range r.Updates() {
+			}
+		}()
+		if err := r.Start(); err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		return r.Status().Status
+	}
+
+	steps := []struct {
+		name     string
+		prepare  func()
+		config   RunnerConfig
+		status   TaskStatus
+		expected int
+	}{
+		{"runs without record", func() {}, RunnerConfig{}, StatusDone, 1},
+		{"skips unchanged inputs", func() {}, RunnerConfig{}, StatusCached, 1},
+		{"runs changed inputs", func() { os.WriteFile(input, []byte("v2"), 0644) }, RunnerConfig{}, StatusDone, 2},
+		{"skips unchanged inputs again", func() {}, RunnerConfig{}, StatusCached, 2},
+		{"runs when forced", func() {}, RunnerConfig{Force: true}, StatusDone, 3},
+	}
+	for _, step := range steps {
+		step.prepare()
+		if status := run(step.config); status != step.status {
+			t.Errorf("%s: expected status %v, got %v", step.name, step.status, status)
+		}
+		if runs != step.expected {
+			t.Errorf("%s: expected %d runs, got %d", step.name, step.expected, runs)
+		}
+	}
+}
+
+func TestTreeRunnerWarnsAboutUnsavedRecord(t *testing.T) {
+	dir := t.TempDir()
+	os.WriteFile(filepath.Join(dir, "input.txt"), []byte("v1"), 0644)
+	// a regular file in place of the records directory makes saving the record fail
+	os.WriteFile(filepath.Join(dir, ".zwooc"), []byte{}, 0644)
+
+	root := tasks.NewTaskTree("root", tasks.NewTask("build", func(cancel <-chan bool, out io.Writer) error {
+		return nil
+	}), false)
+	root.UpToDate = &tasks.UpToDateCheck{
+		Directory: dir,
+		Inputs:    []string{"*.txt"},
+		Record:    filepath.Join(dir, ".zwooc", "root.json"),
+	}
+	log := tasks.NewCapturer()
+	root.Main.Pipe(log)
+
+	r := NewTreeRunner(root, NewSharedProvider(1), RunnerConfig{})
+	go func() {
+		for range r.Updates() {
+		}
+	}()
+	if err := r.Start(); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if status := r.Status().Status; status != StatusDone {
+		t.Errorf("Expected status %v, got %v", StatusDone, status)
+	}
+	if !strings.Contains(log.String(), "warning: failed to save the up to date record") {
+		t.Errorf("Expected a warning in the task output, got %q", log.String())
+	}
+}
+
+func TestTreeRunnerRestoresArtifacts(t *testing.T) {
+	dir := t.TempDir()
+	input := filepath.Join(dir, "input.txt")
+	output := filepath.Join(dir, "dist", "out.txt")
+	os.WriteFile(input, []byte("v1"), 0644)
+
+	runs := 0
+	root := tasks.NewTaskTree("root", tasks.NewTask("build", func(cancel <-chan bool, out io.Writer) error {
+		runs++
+		content, _ := os.ReadFile(input)
+		os.MkdirAll(filepath.Dir(output), 0755)
+		os.WriteFile(output, content, 0644)
+		fmt.Fprintf(out, "built %s\n", content)
+		return nil
+	}), false)
+	root.UpToDate = &tasks.UpToDateCheck{
+		Directory: dir,
+		Inputs:    []string{"*.txt"},
+		Outputs:   []string{"dist/**"},
+		Record:    filepath.Join(dir, ".zwooc", "root.json"),
+		Artifacts: &tasks.ArtifactCache{Dir: filepath.Join(dir, ".zwooc", "artifacts")},
+	}
+	log := tasks.NewCapturer()
+	root.Main.Pipe(log)
+
+	run := func() TaskStatus {
+		r := NewTreeRunner(root, NewSharedProvider(1), RunnerConfig{})
+		go func() {
+			for range r.Updates() {
+			}
+		}()
+		if err := r.Start(); err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		return r.Status().Status
+	}
+
+	run()
+	os.WriteFile(input, []byte("v2"), 0644)
+	run()
+	// switching back to the first input restores its outputs instead of running the task
+	os.WriteFile(input, []byte("v1"), 0644)
+	log.Reset()
+	if status := run(); status != StatusCached {
+		t.Errorf("expected status %v, got %v", StatusCached, status)
+	}
+	if runs != 2 {
+		t.Errorf("expected 2 runs, got %d", runs)
+	}
+	if content, _ := os.ReadFile(output); string(content) != "v1" {
+		t.Errorf("expected restored output 'v1', got '%s'", content)
+	}
+	if log.String() != "built v1\n" {
+		t.Errorf("expected the log to be replayed, got '%s'", log.String())
+	}
+}
+
+func TestTreeRunnerSharedNodes(t *testing.T) {
+	newShared := func(runs *atomic.Int32, err error) *tasks.TaskTreeNode {
+		node := tasks.NewTaskTree("i18n", tasks.NewTask("i18n", func(cancel <-chan bool, out io.Writer) error {
+			runs.Add(1)
+			time.Sleep(10 * time.Millisecond)
+			return err
+		}), false)
+		node.Key = "fragment:i18n"
+		return node
+	}
+	newDependent := func(name string, shared *tasks.TaskTreeNode) *tasks.TaskTreeNode {
+		node := tasks.NewTaskTree(name, tasks.NewTask(name, func(cancel <-chan bool, out io.Writer) error {
+			return nil
+		}), false)
+		node.AddPreChild(shared)
+		return node
+	}
+	start := func(r *TaskTreeRunner) chan error {
+		go func() {
+			for range r.Updates() {
+			}
+		}()
+		errChan := make(chan error, 1)
+		go func() {
+			errChan <- r.Start()
+		}()
+		return errChan
+	}
+
+	t.Run("executes shared dependencies of a tree once", func(t *testing.T) {
+		runs := atomic.Int32{}
+		shared := newShared(&runs, nil)
+		root := tasks.NewTaskTree("root", tasks.Empty(), false)
+		root.AddPreChild(newDependent("web", shared), newDependent("app", shared))
+
+		r := NewTreeRunner(root, NewSharedProvider(1), RunnerConfig{})
+		if err := <-start(r); err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		if runs.Load() != 1 {
+			t.Errorf("Expected 1 run, got %d", runs.Load())
+		}
+		if r.Status().PreNodes[0].PreNodes[0] != r.Status().PreNodes[1].PreNodes[0] {
+			t.Errorf("Expected the shared node to have a single status node")
+		}
+		for _, dependent := range r.Status().PreNodes {
+			if dependent.Status != StatusDone {
+				t.Errorf("Expected %s to run after the shared node, got %d", dependent.Name, dependent.Status)
+			}
+		}
+		if r.Status().AggregatedStatus != StatusDone {
+			t.Errorf("Expected done, got %d", r.Status().AggregatedStatus)
+		}
+	})
+
+	t.Run("executes shared dependencies across runners once", func(t *testing.T) {
+		runs := atomic.Int32{}
+		shared := newShared(&runs, errors.New("failed"))
+		executions := NewSharedExecutions()
+		provider := NewSharedProvider(2)
+		web := NewTreeRunner(newDependent("web", shared), provider, RunnerConfig{Executions: executions})
+		app := NewTreeRunner(newDependent("app", shared), provider, RunnerConfig{Executions: executions})
+
+		webErr, appErr := start(web), start(app)
+		if err := <-webErr; err == nil {
+			t.Errorf("Expected web to fail")
+		}
+		if err := <-appErr; err == nil {
+			t.Errorf("Expected app to fail")
+		}
+		if runs.Load() != 1 {
+			t.Errorf("Expected 1 run, got %d", runs.Load())
+		}
+		for _, r := range []*TaskTreeRunner{web, app} {
+			if status := r.Status().PreNodes[0].Status; status != StatusError {
+				t.Errorf("Expected shared node to fail in %s, got %d", r.Status().Name, status)
+			}
+			if status := r.Status().Status; status != StatusPending {
+				t.Errorf("Expected %s not to run, got %d", r.Status().Name, status)
+			}
+		}
+	})
+
+	t.Run("shares failed executions with later dependents", func(t *testing.T) {
+		runs := atomic.Int32{}
+		shared := newShared(&runs, errors.New("failed"))
+		executions := NewSharedExecutions()
+		web := NewTreeRunner(newDependent("web", shared), NewSharedProvider(1), RunnerConfig{Executions: executions})
+		if err := <-start(web); err == nil {
+			t.Errorf("Expected web to fail")
+		}
+
+		app := NewTreeRunner(newDependent("app", shared), NewSharedProvider(1), RunnerConfig{Executions: executions})
+		if err := <-start(app); err == nil {
+			t.Errorf("Expected app to fail with the result of the shared node")
+		}
+		if runs.Load() != 1 {
+			t.Errorf("Expected 1 run, got %d", runs.Load())
+		}
+
+		rerun, err := web.Rerun()
+		if err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		<-start(rerun)
+		if runs.Load() != 2 {
+			t.Errorf("Expected the rerun to execute the shared node again, got %d runs", runs.Load())
+		}
+	})
+}
+
+func TestTreeRunnerUpdates(t *testing.T) {
+	t.Run("sends the status at the time of the update", func(t *testing.T) {
+		noop := func(cancel <-chan bool, out io.Writer) error { return nil }
+		root := tasks.NewTaskTree("root", tasks.NewTask("main", noop), false)
+		root.AddPreChild(tasks.NewTaskTree("pre", tasks.NewTask("pre", noop), false))
+		r := NewTreeRunner(root, NewSharedProvider(1), RunnerConfig{})
+
+		statuses := make(chan []TaskStatus)
+		go func() {
+			collected := []TaskStatus{}
+			for update := range r.Updates() {
+				if update.ID == "root" {
+					// processing the updates slower than the runner continues
+					time.Sleep(10 * time.Millisecond)
+					collected = append(collected, update.Status)
+				}
+			}
+			statuses <- collected
+		}()
+
+		if err := r.Start(); err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		expected := []TaskStatus{StatusScheduled, StatusRunning, StatusDone}
+		if got := <-statuses; !slices.Equal(got, expected) {
+			t.Errorf("Expected the updates %v, got %v", expected, got)
+		}
 	})
 }
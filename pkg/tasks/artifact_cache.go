@@ -0,0 +1,303 @@
+package tasks
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/zwoo-hq/zwooc/pkg/helper"
+)
+
+const (
+	// DefaultArtifactCacheSize is the size the artifact cache is pruned to unless configured otherwise.
+	DefaultArtifactCacheSize int64 = 2 << 30
+	artifactExtension              = ".tar.gz"
+	artifactLogEntry               = "zwooc.log"
+	artifactOutputPrefix           = "outputs/"
+)
+
+// An ArtifactCache stores the outputs and the log of successful runs as compressed archives keyed by
+// the fingerprint of the run. Archives are written atomically, so that the directory can be shared
+// between multiple checkouts or ci runners.
+type ArtifactCache struct {
+	Dir     string // the directory the archives are stored in
+	MaxSize int64  // the total size the cache is pruned to after storing an archive, 0 disables pruning
+}
+
+// ArtifactCacheStats describes the content of an artifact cache.
+type ArtifactCacheStats struct {
+	Entries int
+	Size    int64
+	Oldest  time.Time
+	Newest  time.Time
+}
+
+type artifactEntry struct {
+	path   string
+	size   int64
+	usedAt time.Time
+}
+
+// Store archives the files (relative to directory) together with the log of the run.
+func (c ArtifactCache) Store(fingerprint, directory string, files []string, log []byte) error {
+	if err := os.MkdirAll(c.Dir, 0755); err != nil {
+		return err
+	}
+
+	temp, err := os.CreateTemp(c.Dir, fingerprint+".*.tmp")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(temp.Name())
+
+	if err := writeArtifact(temp, directory, files, log); err != nil {
+		temp.Close()
+		return err
+	}
+	if err := temp.Close(); err != nil {
+		return err
+	}
+	if err := os.Rename(temp.Name(), c.archivePath(fingerprint)); err != nil {
+		return err
+	}
+
+	if c.MaxSize > 0 {
+		_, _, err = c.Prune()
+	}
+	return err
+}
+
+// Restore extracts the archive of the fingerprint into directory and writes the stored log to log.
+// It reports false if the cache contains no archive for the fingerprint. Files matching one of the
+// outputs are removed before extracting, so that no stale outputs of another run are left over.
+func (c ArtifactCache) Restore(fingerprint, directory string, outputs []string, log io.Writer) (bool, error) {
+	archive := c.archivePath(fingerprint)
+	file, err := os.Open(archive)
+	if errors.Is(err, os.ErrNotExist) {
+		return false, nil
+	} else if err != nil {
+		return false, err
+	}
+	defer file.Close()
+
+	stale, err := helper.Glob(directory, outputs)
+	if err != nil {
+		return false, err
+	}
+	for _, output := range stale {
+		if err := os.Remove(filepath.Join(directory, filepath.FromSlash(output))); err != nil && !errors.Is(err, os.ErrNotExist) {
+			return false, err
+		}
+	}
+
+	if err := readArtifact(file, directory, log); err != nil {
+		return false, fmt.Errorf("corrupt cache entry %s: %w", archive, err)
+	}
+	// the modification time tracks the last usage in order to prune the least recently used entries first
+	now := time.Now()
+	os.Chtimes(archive, now, now)
+	return true, nil
+}
+
+// Stats returns the amount, total size and age of the stored archives.
+func (c ArtifactCache) Stats() (ArtifactCacheStats, error) {
+	entries, err := c.entries()
+	if err != nil {
+		return ArtifactCacheStats{}, err
+	}
+
+	stats := ArtifactCacheStats{Entries: len(entries)}
+	for _, entry := range entries {
+		stats.Size += entry.size
+		if stats.Oldest.IsZero() || entry.usedAt.Before(stats.Oldest) {
+			stats.Oldest = entry.usedAt
+		}
+		if entry.usedAt.After(stats.Newest) {
+			stats.Newest = entry.usedAt
+		}
+	}
+	return stats, nil
+}
+
+// Prune removes the least recently used archives until the cache fits into MaxSize.
+// It returns the amount of removed archives and the freed size.
+func (c ArtifactCache) Prune() (int, int64, error) {
+	entries, err := c.entries()
+	if err != nil || c.MaxSize <= 0 {
+		return 0, 0, err
+	}
+
+	total := int64(0)
+	for _, entry := range entries {
+		total += entry.size
+	}
+
+	sort.Slice(entries, func(i, j int) bool {
+		return entries[i].usedAt.Before(entries[j].usedAt)
+	})
+	removed, freed := 0, int64(0)
+	for _, entry := range entries {
+		if total <= c.MaxSize {
+			break
+		}
+		if err := os.Remove(entry.path); err != nil && !errors.Is(err, os.ErrNotExist) {
+			return removed, freed, err
+		}
+		total -= entry.size
+		freed += entry.size
+		removed++
+	}
+	return removed, freed, nil
+}
+
+// Clear removes all archives.
+func (c ArtifactCache) Clear() error {
+	return os.RemoveAll(c.Dir)
+}
+
+func (c ArtifactCache) archivePath(fingerprint string) string {
+	return filepath.Join(c.Dir, fingerprint+artifactExtension)
+}
+
+func (c ArtifactCache) entries() ([]artifactEntry, error) {
+	files, err := os.ReadDir(c.Dir)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	} else if err != nil {
+		return nil, err
+	}
+
+	entries := []artifactEntry{}
+	for _, file := range files {
+		if file.IsDir() || !strings.HasSuffix(file.Name(), artifactExtension) {
+			continue
+		}
+		info, err := file.Info()
+		if err != nil {
+			// the entry was removed concurrently
+			continue
+		}
+		entries = append(entries, artifactEntry{
+			path:   filepath.Join(c.Dir, file.Name()),
+			size:   info.Size(),
+			usedAt: info.ModTime(),
+		})
+	}
+	return entries, nil
+}
+
+func writeArtifact(destination io.Writer, directory string, files []string, log []byte) error {
+	compressed := gzip.NewWriter(destination)
+	archive := tar.NewWriter(compressed)
+
+	if err := archive.WriteHeader(&tar.Header{
+		Name:    artifactLogEntry,
+		Mode:    0644,
+		Size:    int64(len(log)),
+		ModTime: time.Now(),
+	}); err != nil {
+		return err
+	}
+	if _, err := archive.Write(log); err != nil {
+		return err
+	}
+
+	for _, name := range files {
+		if err := writeArtifactFile(archive, directory, name); err != nil {
+			return err
+		}
+	}
+
+	if err := archive.Close(); err != nil {
+		return err
+	}
+	return compressed.Close()
+}
+
+func writeArtifactFile(archive *tar.Writer, directory, name string) error {
+	file, err := os.Open(filepath.Join(directory, filepath.FromSlash(name)))
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	info, err := file.Stat()
+	if err != nil {
+		return err
+	}
+	header, err := tar.FileInfoHeader(info, "")
+	if err != nil {
+		return err
+	}
+	header.Name = artifactOutputPrefix + name
+	if err := archive.WriteHeader(header); err != nil {
+		return err
+	}
+	_, err = io.Copy(archive, file)
+	return err
+}
+
+func readArtifact(source io.Reader, directory string, log io.Writer) error {
+	compressed, err := gzip.NewReader(source)
+	if err != nil {
+		return err
+	}
+	defer compressed.Close()
+
+	archive := tar.NewReader(compressed)
+	for {
+		header, err := archive.Next()
+		if err == io.EOF {
+			return nil
+		} else if err != nil {
+			return err
+		}
+
+		if header.Name == artifactLogEntry {
+			if _, err := io.Copy(log, archive); err != nil {
+				return err
+			}
+			continue
+		}
+
+		name := strings.TrimPrefix(header.Name, artifactOutputPrefix)
+		if header.Typeflag != tar.TypeReg || name == header.Name || !isLocalPath(name) {
+			return fmt.Errorf("unexpected entry '%s'", header.Name)
+		}
+		if err := readArtifactFile(archive, filepath.Join(directory, filepath.FromSlash(name)), header); err != nil {
+			return err
+		}
+	}
+}
+
+func readArtifactFile(archive io.Reader, destination string, header *tar.Header) error {
+	if err := os.MkdirAll(filepath.Dir(destination), 0755); err != nil {
+		return err
+	}
+	file, err := os.OpenFile(destination, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, header.FileInfo().Mode().Perm())
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(file, archive); err != nil {
+		file.Close()
+		return err
+	}
+	if err := file.Close(); err != nil {
+		return err
+	}
+	return os.Chtimes(destination, header.ModTime, header.ModTime)
+}
+
+// isLocalPath reports whether the slash separated path stays inside the directory it's relative to.
+func isLocalPath(name string) bool {
+	cleaned := path.Clean(name)
+	return !path.IsAbs(cleaned) && cleaned != ".." && !strings.HasPrefix(cleaned, "../")
+}
@@ -0,0 +1,81 @@
+package tasks
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestArtifactCacheStoreAndRestore(t *testing.T) {
+	dir := t.TempDir()
+	cache := ArtifactCache{Dir: filepath.Join(dir, "cache")}
+	os.MkdirAll(filepath.Join(dir, "dist", "assets"), 0755)
+	os.WriteFile(filepath.Join(dir, "dist", "index.html"), []byte("index"), 0644)
+	os.WriteFile(filepath.Join(dir, "dist", "assets", "app.js"), []byte("app"), 0644)
+
+	err := cache.Store("abc", dir, []string{"dist/assets/app.js", "dist/index.html"}, []byte("build log\n"))
+	if err != nil {
+		t.Fatalf("Store() error = %v", err)
+	}
+
+	// outputs of another run are replaced
+	os.WriteFile(filepath.Join(dir, "dist", "assets", "stale.js"), []byte("stale"), 0644)
+	os.WriteFile(filepath.Join(dir, "dist", "index.html"), []byte("changed"), 0644)
+
+	log := bytes.Buffer{}
+	restored, err := cache.Restore("abc", dir, []string{"dist/**"}, &log)
+	if err != nil || !restored {
+		t.Fatalf("Restore() = %v, %v, want true", restored, err)
+	}
+	if log.String() != "build log\n" {
+		t.Errorf("expected log 'build log', got '%s'", log.String())
+	}
+	if content, _ := os.ReadFile(filepath.Join(dir, "dist", "index.html")); string(content) != "index" {
+		t.Errorf("expected restored content 'index', got '%s'", content)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "dist", "assets", "stale.js")); !os.IsNotExist(err) {
+		t.Errorf("expected stale output to be removed")
+	}
+
+	restored, err = cache.Restore("missing", dir, []string{"dist/**"}, &log)
+	if err != nil || restored {
+		t.Errorf("Restore() of missing entry = %v, %v, want false", restored, err)
+	}
+}
+
+func TestArtifactCachePrune(t *testing.T) {
+	dir := t.TempDir()
+	cache := ArtifactCache{Dir: dir}
+	os.WriteFile(filepath.Join(dir, "out.txt"), bytes.Repeat([]byte("x"), 1024), 0644)
+
+	for i, fingerprint := range []string{"old", "used", "new"} {
+		if err := cache.Store(fingerprint, dir, []string{"out.txt"}, nil); err != nil {
+			t.Fatalf("Store() error = %v", err)
+		}
+		usedAt := time.Now().Add(time.Duration(i-10) * time.Minute)
+		os.Chtimes(cache.archivePath(fingerprint), usedAt, usedAt)
+	}
+	// restoring an entry marks it as recently used
+	cache.Restore("used", t.TempDir(), nil, &bytes.Buffer{})
+
+	stats, err := cache.Stats()
+	if err != nil || stats.Entries != 3 {
+		t.Fatalf("Stats() = %+v, %v, want 3 entries", stats, err)
+	}
+
+	cache.MaxSize = stats.Size - 1
+	removed, _, err := cache.Prune()
+	if err != nil || removed != 1 {
+		t.Fatalf("Prune() removed %d, %v, want 1", removed, err)
+	}
+	if _, err := os.Stat(cache.archivePath("old")); !os.IsNotExist(err) {
+		t.Errorf("expected least recently used entry to be pruned")
+	}
+	for _, fingerprint := range []string{"used", "new"} {
+		if _, err := os.Stat(cache.archivePath(fingerprint)); err != nil {
+			t.Errorf("expected entry '%s' to be kept", fingerprint)
+		}
+	}
+}
@@ -23,6 +23,13 @@ func (cc *CommandCapturer) Write(p []byte) (n int, err error) {
 	return cc.data.Write(p)
 }
 
+// Reset discards the captured output.
+func (cc *CommandCapturer) Reset() {
+	cc.mu.Lock()
+	defer cc.mu.Unlock()
+	cc.data.Reset()
+}
+
 func (cc *CommandCapturer) Bytes() []byte {
 	cc.mu.RLock()
 	defer cc.mu.RUnlock()
@@ -1,13 +1,15 @@
 package tasks
 
 import (
+	"errors"
 	"fmt"
 	"io"
+	"os"
 	"os/exec"
-	"runtime"
-	"strconv"
 	"strings"
 	"sync"
+	"syscall"
+	"time"
 
 	"github.com/zwoo-hq/zwooc/pkg/helper"
 )
@@ -17,18 +19,28 @@ type commandTask struct {
 	cmd    *exec.Cmd
 	writer *multiWriter
 	stdIn  io.WriteCloser
+	stop   StopPolicy
+	tty    bool
+	// terminal is the controlling side of the pseudo-terminal of a running tty task
+	terminal *os.File
+	cols     int
+	rows     int
+	// ioMu guards stdIn and the terminal, which are replaced when the task is restarted
+	ioMu sync.Mutex
 }
 
 func NewCommandTask(name string, cmd *exec.Cmd) Task {
 	writer := newMultiWriter()
 	cmd.Stdout = writer
 	cmd.Stderr = writer
+	setProcessGroup(cmd)
 	in, _ := cmd.StdinPipe()
-	return commandTask{
+	return &commandTask{
 		name:   name,
 		cmd:    cmd,
 		writer: writer,
 		stdIn:  in,
+		stop:   DefaultStopPolicy,
 	}
 }
 
@@ -42,25 +54,91 @@ func NewBasicCommandTask(name string, command string, dir string, args []string)
 	in, _ := cmd.StdinPipe()
 	cmd.Stdout = writer
 	cmd.Stderr = writer
-	return commandTask{
+	setProcessGroup(cmd)
+	return &commandTask{
 		name:   name,
 		cmd:    cmd,
 		writer: writer,
 		stdIn:  in,
+		stop:   DefaultStopPolicy,
 	}
 }
 
-func (ct commandTask) Name() string {
+func (ct *commandTask) Name() string {
 	return ct.name
 }
 
-func (ct commandTask) Pipe(destination io.Writer) {
+func (ct *commandTask) Pipe(destination io.Writer) {
 	ct.writer.Pipe(destination)
 }
 
-func (ct commandTask) Run(cancel <-chan bool) error {
+func (ct *commandTask) output() io.Writer {
+	return ct.writer
+}
+
+// WriteInput writes the data to the stdin of the command.
+func (ct *commandTask) WriteInput(data []byte) error {
+	ct.ioMu.Lock()
+	defer ct.ioMu.Unlock()
+	if ct.stdIn == nil {
+		return ErrInputNotSupported
+	}
+	_, err := ct.stdIn.Write(data)
+	return err
+}
+
+// commandLine returns the arguments of the command prefixed by the environment variables
+// it sets in addition to the environment of zwooc.
+func (ct *commandTask) commandLine() string {
+	inherited := map[string]bool{}
+	for _, variable := range os.Environ() {
+		inherited[variable] = true
+	}
+
+	parts := []string{}
+	for _, variable := range ct.cmd.Env {
+		if !inherited[variable] {
+			parts = append(parts, variable)
+		}
+	}
+	return strings.Join(append(parts, ct.cmd.Args...), " ")
+}
+
+func (ct *commandTask) setStopPolicy(policy StopPolicy) {
+	ct.stop = policy
+}
+
+func (ct *commandTask) enableTTY() {
+	ct.tty = true
+}
+
+func (ct *commandTask) resizeTerminal(cols, rows int) {
+	ct.ioMu.Lock()
+	defer ct.ioMu.Unlock()
+	if ct.cols == cols && ct.rows == rows {
+		return
+	}
+	ct.cols = cols
+	ct.rows = rows
+	if ct.terminal != nil {
+		setTerminalSize(ct.terminal, cols, rows)
+	}
+}
+
+func (ct *commandTask) Run(cancel <-chan bool) error {
+	if ct.cmd.Process != nil || ct.tty {
+		// the command was already executed (e.g. the task is restarted) or must be attached to a terminal
+		ct.reset()
+	}
+
 	// start the command
-	if err := ct.cmd.Start(); err != nil {
+	var terminalDone <-chan bool
+	if ct.tty {
+		var err error
+		if terminalDone, err = ct.startTerminal(); err != nil {
+			return err
+		}
+	} else if err := ct.cmd.Start(); err != nil {
 		return err
 	}
 
@@ -71,6 +149,9 @@ func (ct commandTask) Run(cancel <-chan bool) error {
 		// wait until the command is finished
 		ct.writer.Write([]byte(fmt.Sprintf("pid: %d\n", ct.cmd.Process.Pid)))
 		err := ct.cmd.Wait()
+		if terminalDone != nil {
+			ct.closeTerminal(terminalDone)
+		}
 		if err != nil {
 			errChan <- err
 		}
@@ -81,19 +162,7 @@ func (ct commandTask) Run(cancel <-chan bool) error {
 	select {
 	case <-cancel:
 		// task go cancelled
-		var err error
-		if runtime.GOOS == "windows" {
-			err = exec.Command("taskkill", "/F", "/T", "/PID", strconv.Itoa(ct.cmd.Process.Pid)).Run()
-		} else {
-			err = exec.Command("pkill", "-P", strconv.Itoa(ct.cmd.Process.Pid)).Run()
-		}
-		// err = syscall.Kill(ct.cmd.Process.Pid, syscall.SIGKILL)
-		if err != nil {
-			// fall back to builtin kill
-			if err := ct.cmd.Process.Kill(); err != nil {
-				return err
-			}
-		}
+		return ct.terminate(helper.WaitFor(&wg))
 	case <-helper.WaitFor(&wg):
 		// task finished
 		for err := range errChan {
@@ -102,5 +171,117 @@ func (ct commandTask) Run(cancel <-chan bool) error {
 		}
 		return nil
 	}
+}
+
+// terminate sends the stop signal to the process group of the command and kills the group
+// if it did not exit within the stop timeout. done is closed once the command exited.
+func (ct *commandTask) terminate(done <-chan bool) error {
+	signal := ct.stop.Signal
+	if signal == 0 {
+		signal = DefaultStopPolicy.Signal
+	}
+	timeout := ct.stop.Timeout
+	if timeout <= 0 {
+		timeout = DefaultStopTimeout
+	}
+
+	if err := signalProcessGroup(ct.cmd, signal); err != nil {
+		ct.writer.Write([]byte(fmt.Sprintf("failed to send %s: %s, killing process group\n", signalName(signal), err)))
+		return ct.kill(done)
+	}
+
+	if signal == syscall.SIGKILL {
+		ct.writer.Write([]byte("killed process group with SIGKILL\n"))
+		<-done
+		return nil
+	}
+
+	select {
+	case <-done:
+		ct.writer.Write([]byte(fmt.Sprintf("stopped process group with %s\n", signalName(signal))))
+		return nil
+	case <-time.After(timeout):
+	}
+
+	ct.writer.Write([]byte(fmt.Sprintf("process group did not exit within %s after %s, killing it with SIGKILL\n", timeout, signalName(signal))))
+	return ct.kill(done)
+}
+
+// kill forcefully terminates the process group of the command, only if that fails the process itself is killed.
+func (ct *commandTask) kill(done <-chan bool) error {
+	if err := signalProcessGroup(ct.cmd, syscall.SIGKILL); err != nil {
+		if err := ct.cmd.Process.Kill(); err != nil && !errors.Is(err, os.ErrProcessDone) {
+			return err
+		}
+	}
+	<-done
 	return nil
 }
+
+// startTerminal starts the command attached to a pseudo-terminal, whose output is copied to the writer.
+// The returned channel is closed once all output was copied.
+func (ct *commandTask) startTerminal() (<-chan bool, error) {
+	ct.ioMu.Lock()
+	defer ct.ioMu.Unlock()
+	if ct.cols == 0 || ct.rows == 0 {
+		ct.cols = DefaultTerminalCols
+		ct.rows = DefaultTerminalRows
+	}
+
+	terminal, err := startTerminal(ct.cmd, ct.cols, ct.rows)
+	if err != nil {
+		return nil, err
+	}
+	ct.terminal = terminal
+	ct.stdIn = terminal
+
+	done := make(chan bool)
+	go func() {
+		// reading fails once the terminal is closed
+		io.Copy(ct.writer, terminal)
+		close(done)
+	}()
+	return done, nil
+}
+
+// closeTerminal closes the terminal after its remaining output was copied. Processes that
+// outlived the command may keep the terminal open, thus the output is drained for a short time only.
+func (ct *commandTask) closeTerminal(done <-chan bool) {
+	select {
+	case <-done:
+	case <-time.After(terminalDrainTimeout):
+	}
+
+	ct.ioMu.Lock()
+	defer ct.ioMu.Unlock()
+	ct.terminal.Close()
+	ct.terminal = nil
+	ct.stdIn = nil
+}
+
+// reset replaces the command with a fresh copy, since a command can be started only once.
+// The copy of a tty task is not connected to any pipes, since it's attached to a terminal when started.
+func (ct *commandTask) reset() {
+	cmd := exec.Command(ct.cmd.Path)
+	cmd.Args = ct.cmd.Args
+	cmd.Dir = ct.cmd.Dir
+	cmd.Env = ct.cmd.Env
+	if stdin, ok := ct.cmd.Stdin.(*os.File); ok && ct.cmd.Process == nil {
+		// release the stdin pipe of a command that was never started
+		stdin.Close()
+	}
+
+	ct.ioMu.Lock()
+	defer ct.ioMu.Unlock()
+	if ct.stdIn != nil {
+		ct.stdIn.Close()
+	}
+	ct.stdIn = nil
+	if !ct.tty {
+		cmd.Stdout = ct.writer
+		cmd.Stderr = ct.writer
+		setProcessGroup(cmd)
+		ct.stdIn, _ = cmd.StdinPipe()
+	}
+	ct.cmd = cmd
+}
@@ -0,0 +1,115 @@
+//go:build !windows
+
+package tasks
+
+import (
+	"strings"
+	"syscall"
+	"testing"
+	"time"
+)
+
+func runAndCancel(t *testing.T, task Task, after time.Duration) (time.Duration, error) {
+	t.Helper()
+	cancel := make(chan bool, 1)
+	done := make(chan error, 1)
+	go func() {
+		done <- task.Run(cancel)
+	}()
+
+	time.Sleep(after)
+	start := time.Now()
+	cancel <- true
+	select {
+	case err := <-done:
+		return time.Since(start), err
+	case <-time.After(5 * time.Second):
+		t.Fatal("task did not stop")
+		return 0, nil
+	}
+}
+
+func TestCommandTaskStopsProcessGroup(t *testing.T) {
+	// the background sleep keeps the output open, thus the task only finishes once the whole group exited
+	task := NewBasicCommandTask("test", "sleep 30 & sleep 30", "", []string{})
+	capturer := NewCapturer()
+	task.Pipe(capturer)
+
+	took, err := runAndCancel(t, task, 200*time.Millisecond)
+	if err != nil {
+		t.Errorf("expected no error, got %v", err)
+	}
+	if took > time.Second {
+		t.Errorf("expected the process group to stop immediately, took %s", took)
+	}
+	if !strings.Contains(capturer.String(), "stopped process group with SIGTERM") {
+		t.Errorf("expected stop to be reported, got %q", capturer.String())
+	}
+}
+
+func TestCommandTaskKillsProcessGroupAfterTimeout(t *testing.T) {
+	task := NewBasicCommandTask("test", "trap '' TERM; sleep 30", "", []string{})
+	SetStopPolicy(task, StopPolicy{Signal: syscall.SIGTERM, Timeout: 200 * time.Millisecond})
+	capturer := NewCapturer()
+	task.Pipe(capturer)
+
+	took, err := runAndCancel(t, task, 200*time.Millisecond)
+	if err != nil {
+		t.Errorf("expected no error, got %v", err)
+	}
+	if took < 200*time.Millisecond {
+		t.Errorf("expected the process group to be killed after the timeout, took %s", took)
+	}
+	if !strings.Contains(capturer.String(), "killing it with SIGKILL") {
+		t.Errorf("expected kill to be reported, got %q", capturer.String())
+	}
+}
+
+func TestCommandTaskWriteInput(t *testing.T) {
+	task := NewBasicCommandTask("test", "read line; echo \"got $line\"", "", []string{})
+	capturer := NewCapturer()
+	task.Pipe(capturer)
+
+	done := make(chan error, 1)
+	go func() {
+		done <- task.Run(make(chan bool))
+	}()
+
+	if err := task.(InputTask).WriteInput([]byte("hello\n")); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Errorf("expected no error, got %v", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("task did not finish")
+	}
+	if !strings.Contains(capturer.String(), "got hello") {
+		t.Errorf("expected input to be read, got %q", capturer.String())
+	}
+}
+
+func TestCommandTaskTTY(t *testing.T) {
+	task := NewBasicCommandTask("test", "test -t 1 && stty size", "", []string{})
+	EnableTTY(task)
+	ResizeTerminal(task, 100, 30)
+	capturer := NewCapturer()
+	task.Pipe(capturer)
+
+	if err := task.Run(make(chan bool)); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if !strings.Contains(capturer.String(), "30 100") {
+		t.Errorf("expected output of a terminal with 30 rows and 100 cols, got %q", capturer.String())
+	}
+
+	// restarted tasks are attached to a new terminal
+	if err := task.Run(make(chan bool)); err != nil {
+		t.Fatalf("expected no error on restart, got %v", err)
+	}
+	if strings.Count(capturer.String(), "30 100") != 2 {
+		t.Errorf("expected output of both runs, got %q", capturer.String())
+	}
+}
@@ -0,0 +1,265 @@
+package tasks
+
+import (
+	"fmt"
+	"io"
+	"sync"
+)
+
+// A TaskFactory creates a fresh task for the given run mode.
+type TaskFactory func(mode string) (Task, error)
+
+type controlAction int
+
+const (
+	actionRestart controlAction = iota
+	actionStop
+)
+
+type controlCommand struct {
+	action controlAction
+	// task is the task that replaces the current task when restarting
+	task Task
+	mode string
+}
+
+// controlledTask is a task that can be restarted, stopped or switched into another run mode while running.
+// The wrapped task is re-created by its factory when restarting.
+type controlledTask struct {
+	mode      string
+	factory   TaskFactory
+	current   Task
+	stop      *StopPolicy
+	tty       bool
+	cols      int
+	rows      int
+	writer    *multiWriter
+	control   chan controlCommand
+	isRunning bool
+	isStopped bool
+	mu        sync.Mutex
+}
+
+// NewControlledTask creates a task for the mode that can be controlled while running.
+// The returned task implements model.ControlledTask.
+func NewControlledTask(mode string, factory TaskFactory) (Task, error) {
+	task, err := factory(mode)
+	if err != nil {
+		return nil, err
+	}
+
+	ct := &controlledTask{
+		mode:    mode,
+		factory: factory,
+		writer:  newMultiWriter(),
+		control: make(chan controlCommand, 1),
+	}
+	ct.replace(task, mode)
+	return ct, nil
+}
+
+func (ct *controlledTask) Name() string {
+	ct.mu.Lock()
+	defer ct.mu.Unlock()
+	return ct.current.Name()
+}
+
+func (ct *controlledTask) Pipe(destination io.Writer) {
+	ct.writer.Pipe(destination)
+}
+
+func (ct *controlledTask) output() io.Writer {
+	return ct.writer
+}
+
+// Mode returns the run mode of the current task.
+func (ct *controlledTask) Mode() string {
+	ct.mu.Lock()
+	defer ct.mu.Unlock()
+	return ct.mode
+}
+
+// IsRunning reports whether the task is currently running.
+func (ct *controlledTask) IsRunning() bool {
+	ct.mu.Lock()
+	defer ct.mu.Unlock()
+	return ct.isRunning
+}
+
+// IsStopped reports whether the running task was stopped.
+func (ct *controlledTask) IsStopped() bool {
+	ct.mu.Lock()
+	defer ct.mu.Unlock()
+	return ct.isStopped
+}
+
+func (ct *controlledTask) Run(cancel <-chan bool) error {
+	ct.mu.Lock()
+	ct.isRunning = true
+	ct.mu.Unlock()
+	defer func() {
+		ct.mu.Lock()
+		ct.isRunning = false
+		ct.isStopped = false
+		select {
+		case <-ct.control:
+			// drop commands that arrived while the task was finishing
+		default:
+		}
+		ct.mu.Unlock()
+	}()
+
+	for {
+		if ct.IsStopped() {
+			// wait until the task is started again
+			select {
+			case <-cancel:
+				return nil
+			case command := <-ct.control:
+				ct.apply(command)
+				continue
+			}
+		}
+
+		ct.mu.Lock()
+		task := ct.current
+		ct.mu.Unlock()
+
+		stop := make(chan bool, 1)
+		done := make(chan error, 1)
+		go func() {
+			done <- task.Run(stop)
+		}()
+
+		select {
+		case err := <-done:
+			return err
+		case <-cancel:
+			stop <- true
+			return <-done
+		case command := <-ct.control:
+			stop <- true
+			<-done
+			ct.apply(command)
+		}
+	}
+}
+
+func (ct *controlledTask) apply(command controlCommand) {
+	if command.action == actionStop {
+		ct.mu.Lock()
+		ct.isStopped = true
+		ct.mu.Unlock()
+		return
+	}
+
+	if command.task != nil {
+		ct.replace(command.task, command.mode)
+	}
+	ct.mu.Lock()
+	ct.isStopped = false
+	ct.mu.Unlock()
+}
+
+func (ct *controlledTask) commandLine() string {
+	ct.mu.Lock()
+	defer ct.mu.Unlock()
+	return CommandLine(ct.current)
+}
+
+// WriteInput writes the data to the stdin of the current task.
+func (ct *controlledTask) WriteInput(data []byte) error {
+	ct.mu.Lock()
+	task := ct.current
+	ct.mu.Unlock()
+	if input, ok := task.(InputTask); ok {
+		return input.WriteInput(data)
+	}
+	return ErrInputNotSupported
+}
+
+// Restart stops the running task and starts a fresh instance of it. A stopped task is started again.
+func (ct *controlledTask) Restart() {
+	if !ct.IsRunning() {
+		return
+	}
+	task, err := ct.factory(ct.Mode())
+	if err != nil {
+		ct.writer.Write([]byte(fmt.Sprintf("failed to restart: %s\n", err)))
+		return
+	}
+	ct.send(controlCommand{action: actionRestart, task: task, mode: ct.Mode()})
+}
+
+// Stop stops the running task without finishing it, the task can be started again via Restart.
+func (ct *controlledTask) Stop() {
+	ct.send(controlCommand{action: actionStop})
+}
+
+// SwitchMode replaces the task with the task of another run mode. A running task is restarted in the new mode.
+func (ct *controlledTask) SwitchMode(mode string) {
+	task, err := ct.factory(mode)
+	if err != nil {
+		ct.writer.Write([]byte(fmt.Sprintf("failed to switch to mode '%s': %s\n", mode, err)))
+		return
+	}
+
+	if !ct.IsRunning() {
+		ct.replace(task, mode)
+		return
+	}
+	ct.send(controlCommand{action: actionRestart, task: task, mode: mode})
+}
+
+// send passes the command to the running task, it is ignored if the task is not running.
+func (ct *controlledTask) send(command controlCommand) {
+	ct.mu.Lock()
+	defer ct.mu.Unlock()
+	if !ct.isRunning {
+		return
+	}
+	select {
+	case <-ct.control:
+		// the latest command replaces a pending command
+	default:
+	}
+	ct.control <- command
+}
+
+func (ct *controlledTask) setStopPolicy(policy StopPolicy) {
+	ct.mu.Lock()
+	defer ct.mu.Unlock()
+	ct.stop = &policy
+	SetStopPolicy(ct.current, policy)
+}
+
+func (ct *controlledTask) enableTTY() {
+	ct.mu.Lock()
+	defer ct.mu.Unlock()
+	ct.tty = true
+	EnableTTY(ct.current)
+}
+
+func (ct *controlledTask) resizeTerminal(cols, rows int) {
+	ct.mu.Lock()
+	defer ct.mu.Unlock()
+	ct.cols = cols
+	ct.rows = rows
+	ResizeTerminal(ct.current, cols, rows)
+}
+
+func (ct *controlledTask) replace(task Task, mode string) {
+	task.Pipe(ct.writer)
+	ct.mu.Lock()
+	// re-created tasks are terminated and attached to a terminal the same way
+	if ct.stop != nil {
+		SetStopPolicy(task, *ct.stop)
+	}
+	if ct.tty {
+		EnableTTY(task)
+		ResizeTerminal(task, ct.cols, ct.rows)
+	}
+	ct.current = task
+	ct.mode = mode
+	ct.mu.Unlock()
+}
@@ -0,0 +1,124 @@
+package tasks
+
+import (
+	"errors"
+	"io"
+	"slices"
+	"sync"
+	"testing"
+	"time"
+)
+
+type testTaskFactory struct {
+	mu      sync.Mutex
+	started []string
+	running chan bool
+}
+
+func newTestTaskFactory() *testTaskFactory {
+	return &testTaskFactory{running: make(chan bool, 8)}
+}
+
+func (f *testTaskFactory) create(mode string) (Task, error) {
+	if mode == "invalid" {
+		return nil, errors.New("invalid mode")
+	}
+	return NewTask("task", func(cancel <-chan bool, out io.Writer) error {
+		f.mu.Lock()
+		f.started = append(f.started, mode)
+		f.mu.Unlock()
+		f.running <- true
+		<-cancel
+		return nil
+	}), nil
+}
+
+func (f *testTaskFactory) waitStarted(t *testing.T) {
+	select {
+	case <-f.running:
+	case <-time.After(2 * time.Second):
+		t.Fatalf("Expected task to be started")
+	}
+}
+
+func TestControlledTask(t *testing.T) {
+	t.Run("implements a controlled task", func(t *testing.T) {
+		factory := newTestTaskFactory()
+		task, _ := NewControlledTask("run", factory.create)
+		if _, ok := task.(interface {
+			Restart()
+			Stop()
+			SwitchMode(mode string)
+		}); !ok {
+			t.Errorf("Expected task to be controllable")
+		}
+	})
+
+	t.Run("fails for invalid modes", func(t *testing.T) {
+		factory := newTestTaskFactory()
+		if _, err := NewControlledTask("invalid", factory.create); err == nil {
+			t.Errorf("Expected an error")
+		}
+	})
+
+	t.Run("restarts, switches mode and stops the task", func(t *testing.T) {
+		factory := newTestTaskFactory()
+		task, _ := NewControlledTask("run", factory.create)
+		controlled := task.(*controlledTask)
+
+		cancel := make(chan bool, 1)
+		errChan := make(chan error, 1)
+		go func() {
+			errChan <- task.Run(cancel)
+		}()
+
+		factory.waitStarted(t)
+		controlled.Restart()
+		factory.waitStarted(t)
+		controlled.SwitchMode("invalid")
+		controlled.SwitchMode("watch")
+		factory.waitStarted(t)
+		controlled.Stop()
+		for !controlled.IsStopped() {
+			time.Sleep(time.Millisecond)
+		}
+		controlled.Restart()
+		factory.waitStarted(t)
+		controlled.Stop()
+		for !controlled.IsStopped() {
+			time.Sleep(time.Millisecond)
+		}
+		cancel <- true
+
+		if err := <-errChan; err != nil {
+			t.Errorf("Expected no error, got %v", err)
+		}
+		expected := []string{"run", "run", "watch", "watch"}
+		if !slices.Equal(factory.started, expected) {
+			t.Errorf("Expected %v, got %v", expected, factory.started)
+		}
+		if controlled.Mode() != "watch" {
+			t.Errorf("Expected mode watch, got %s", controlled.Mode())
+		}
+	})
+
+	t.Run("ignores commands when not running", func(t *testing.T) {
+		factory := newTestTaskFactory()
+		task, _ := NewControlledTask("run", factory.create)
+		controlled := task.(*controlledTask)
+		controlled.Stop()
+		controlled.Restart()
+
+		cancel := make(chan bool, 1)
+		errChan := make(chan error, 1)
+		go func() {
+			errChan <- task.Run(cancel)
+		}()
+		factory.waitStarted(t)
+		cancel <- true
+
+		if err := <-errChan; err != nil {
+			t.Errorf("Expected no error, got %v", err)
+		}
+	})
+}
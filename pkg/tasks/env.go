@@ -0,0 +1,46 @@
+package tasks
+
+import "os"
+
+// SourceInlineEnv is the source of environment variables defined inline via the `env` option.
+const SourceInlineEnv = "env"
+
+// An EnvVariable is an environment variable a task is executed with in addition to the environment of zwooc.
+type EnvVariable struct {
+	Name   string
+	Value  string
+	Source string // the env file the variable is defined in or SourceInlineEnv
+}
+
+func (v EnvVariable) String() string {
+	return v.Name + "=" + v.Value
+}
+
+// EnvList converts the variables into the `NAME=value` form used by exec.Cmd.
+func EnvList(variables []EnvVariable) []string {
+	env := make([]string, len(variables))
+	for i, variable := range variables {
+		env[i] = variable.String()
+	}
+	return env
+}
+
+// envTask is implemented by tasks whose environment can be extended.
+type envTask interface {
+	setEnv(env []string)
+}
+
+// SetEnv adds the variables (of the form `NAME=value`) to the environment of the task, they take precedence
+// over the environment of zwooc. Tasks that are not backed by a process ignore the variables.
+func SetEnv(task Task, env []string) {
+	if t, ok := task.(envTask); ok && len(env) > 0 {
+		t.setEnv(env)
+	}
+}
+
+func (ct *commandTask) setEnv(env []string) {
+	if ct.cmd.Env == nil {
+		ct.cmd.Env = os.Environ()
+	}
+	ct.cmd.Env = append(ct.cmd.Env, env...)
+}
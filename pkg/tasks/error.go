@@ -3,15 +3,22 @@ package tasks
 import (
 	"errors"
 	"fmt"
+	"sort"
 	"strings"
 )
 
 var (
-	ErrCancelled = errors.New("task cancelled")
+	ErrCancelled         = errors.New("task cancelled")
+	ErrInputNotSupported = errors.New("task does not accept input")
 )
 
 type MultiTaskError struct {
+	// Errors contains the error of each failed node by its node id.
 	Errors map[string]error
+	// Skipped contains the node ids of nodes that were skipped because a dependency failed.
+	Skipped []string
+	// NotStarted contains the node ids of nodes that were never started.
+	NotStarted []string
 }
 
 func (mte *MultiTaskError) Error() string {
@@ -19,11 +26,21 @@ func (mte *MultiTaskError) Error() string {
 	for id := range mte.Errors {
 		nodeIds = append(nodeIds, id)
 	}
-	return fmt.Sprintf("tasks %s failed", strings.Join(nodeIds, ", "))
+	sort.Strings(nodeIds)
+	msg := fmt.Sprintf("tasks %s failed", strings.Join(nodeIds, ", "))
+	if len(mte.Skipped) > 0 {
+		msg += fmt.Sprintf(", skipped %s", strings.Join(mte.Skipped, ", "))
+	}
+	if len(mte.NotStarted) > 0 {
+		msg += fmt.Sprintf(", never started %s", strings.Join(mte.NotStarted, ", "))
+	}
+	return msg
 }
 
 func NewMultiTaskError(errors map[string]error) *MultiTaskError {
 	return &MultiTaskError{
-		Errors: errors,
+		Errors:     errors,
+		Skipped:    []string{},
+		NotStarted: []string{},
 	}
 }
@@ -26,6 +26,10 @@ func (ft functionTask) Pipe(destination io.Writer) {
 	ft.writer.Pipe(destination)
 }
 
+func (ft functionTask) output() io.Writer {
+	return ft.writer
+}
+
 func (ft functionTask) Run(cancel <-chan bool) error {
 	return ft.execute(cancel, ft.writer)
 }
@@ -0,0 +1,126 @@
+package tasks
+
+import (
+	"bytes"
+	"net"
+	"net/http"
+	"os"
+	"regexp"
+	"sync"
+	"time"
+)
+
+const probeInterval = 250 * time.Millisecond
+
+// A ReadinessProbe checks whether a (long running) task is ready, so that nodes depending on it
+// can be executed while the task keeps running. All configured conditions must be satisfied.
+type ReadinessProbe struct {
+	Output  *regexp.Regexp // a pattern that must match a line of the tasks output
+	Address string         // a tcp address that must accept connections
+	URL     string         // a url that must respond with a 2xx status code
+	File    string         // a file that must exist
+}
+
+// Watch starts probing the task and returns a channel that receives a value once the task is ready.
+// Watch must be called before the task is started. Probing stops when stop is closed.
+func (p ReadinessProbe) Watch(task Task, stop <-chan bool) <-chan bool {
+	ready := make(chan bool, 1)
+	var outputMatched <-chan bool
+	if p.Output != nil {
+		matcher := newOutputMatcher(p.Output)
+		task.Pipe(matcher)
+		outputMatched = matcher.matched
+	}
+
+	go func() {
+		if outputMatched != nil {
+			select {
+			case <-outputMatched:
+			case <-stop:
+				return
+			}
+		}
+
+		ticker := time.NewTicker(probeInterval)
+		defer ticker.Stop()
+		for !p.isReady() {
+			select {
+			case <-ticker.C:
+			case <-stop:
+				return
+			}
+		}
+		ready <- true
+	}()
+	return ready
+}
+
+func (p ReadinessProbe) isReady() bool {
+	if p.Address != "" {
+		conn, err := net.DialTimeout("tcp", p.Address, time.Second)
+		if err != nil {
+			return false
+		}
+		conn.Close()
+	}
+
+	if p.URL != "" {
+		client := http.Client{Timeout: time.Second}
+		res, err := client.Get(p.URL)
+		if err != nil {
+			return false
+		}
+		res.Body.Close()
+		if res.StatusCode < 200 || res.StatusCode > 299 {
+			return false
+		}
+	}
+
+	if p.File != "" {
+		if _, err := os.Stat(p.File); err != nil {
+			return false
+		}
+	}
+	return true
+}
+
+// outputMatcher is a writer that matches each line written to it against a pattern.
+type outputMatcher struct {
+	pattern *regexp.Regexp
+	line    []byte
+	matched chan bool
+	once    sync.Once
+	mu      sync.Mutex
+}
+
+func newOutputMatcher(pattern *regexp.Regexp) *outputMatcher {
+	return &outputMatcher{
+		pattern: pattern,
+		matched: make(chan bool),
+	}
+}
+
+func (m *outputMatcher) Write(p []byte) (n int, err error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.line = append(m.line, p...)
+	for {
+		index := bytes.IndexByte(m.line, '\n')
+		if index < 0 {
+			break
+		}
+		m.match(m.line[:index])
+		m.line = m.line[index+1:]
+	}
+	// match incomplete lines as well, since prompts are usually not terminated by a newline
+	m.match(m.line)
+	return len(p), nil
+}
+
+func (m *outputMatcher) match(line []byte) {
+	if m.pattern.Match(line) {
+		m.once.Do(func() {
+			close(m.matched)
+		})
+	}
+}
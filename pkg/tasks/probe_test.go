@@ -0,0 +1,75 @@
+package tasks
+
+import (
+	"io"
+	"net"
+	"os"
+	"path/filepath"
+	"regexp"
+	"testing"
+	"time"
+)
+
+func waitReady(ready <-chan bool) bool {
+	select {
+	case <-ready:
+		return true
+	case <-time.After(2 * time.Second):
+		return false
+	}
+}
+
+func TestReadinessProbe(t *testing.T) {
+	t.Run("matches output lines", func(t *testing.T) {
+		task := NewTask("task", func(cancel <-chan bool, out io.Writer) error {
+			out.Write([]byte("compiling...\nready in "))
+			out.Write([]byte("120ms\n"))
+			return nil
+		})
+		stop := make(chan bool)
+		defer close(stop)
+		ready := ReadinessProbe{Output: regexp.MustCompile(`^ready in \d+ms$`)}.Watch(task, stop)
+		task.Run(nil)
+
+		if !waitReady(ready) {
+			t.Errorf("Expected probe to pass")
+		}
+	})
+
+	t.Run("waits for all conditions", func(t *testing.T) {
+		listener, err := net.Listen("tcp", "127.0.0.1:0")
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer listener.Close()
+
+		file := filepath.Join(t.TempDir(), "ready")
+		stop := make(chan bool)
+		defer close(stop)
+		probe := ReadinessProbe{Address: listener.Addr().String(), File: file}
+		ready := probe.Watch(Empty(), stop)
+
+		select {
+		case <-ready:
+			t.Fatalf("Expected probe to wait for the file")
+		case <-time.After(2 * probeInterval):
+		}
+
+		os.WriteFile(file, []byte{}, 0644)
+		if !waitReady(ready) {
+			t.Errorf("Expected probe to pass")
+		}
+	})
+
+	t.Run("stops probing", func(t *testing.T) {
+		stop := make(chan bool)
+		ready := ReadinessProbe{File: filepath.Join(t.TempDir(), "missing")}.Watch(Empty(), stop)
+		close(stop)
+
+		select {
+		case <-ready:
+			t.Errorf("Expected probe not to pass")
+		case <-time.After(2 * probeInterval):
+		}
+	})
+}
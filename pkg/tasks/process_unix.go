@@ -0,0 +1,39 @@
+//go:build !windows
+
+package tasks
+
+import (
+	"os"
+	"os/exec"
+	"syscall"
+
+	"github.com/creack/pty"
+)
+
+const ttySupported = true
+
+func init() {
+	signals["SIGUSR1"] = syscall.SIGUSR1
+	signals["SIGUSR2"] = syscall.SIGUSR2
+}
+
+// setProcessGroup starts the command in its own process group, so that all of its children can be signaled.
+func setProcessGroup(cmd *exec.Cmd) {
+	cmd.SysProcAttr = &syscall.SysProcAttr{Setpgid: true}
+}
+
+// signalProcessGroup sends the signal to the process group of the started command.
+func signalProcessGroup(cmd *exec.Cmd, signal syscall.Signal) error {
+	return syscall.Kill(-cmd.Process.Pid, signal)
+}
+
+// startTerminal starts the command attached to a new pseudo-terminal and returns its controlling side.
+// The command becomes the leader of a new session, thus its process group can be signaled as well.
+func startTerminal(cmd *exec.Cmd, cols, rows int) (*os.File, error) {
+	size := &pty.Winsize{Cols: uint16(cols), Rows: uint16(rows)}
+	return pty.StartWithAttrs(cmd, size, &syscall.SysProcAttr{Setsid: true, Setctty: true})
+}
+
+func setTerminalSize(terminal *os.File, cols, rows int) error {
+	return pty.Setsize(terminal, &pty.Winsize{Cols: uint16(cols), Rows: uint16(rows)})
+}
@@ -0,0 +1,41 @@
+//go:build windows
+
+package tasks
+
+import (
+	"errors"
+	"os"
+	"os/exec"
+	"strconv"
+	"syscall"
+
+	"golang.org/x/sys/windows"
+)
+
+const ttySupported = false
+
+var errTTYNotSupported = errors.New("pseudo-terminals are not supported on windows")
+
+// setProcessGroup starts the command in its own process group, so that all of its children can be signaled.
+func setProcessGroup(cmd *exec.Cmd) {
+	cmd.SysProcAttr = &syscall.SysProcAttr{CreationFlags: syscall.CREATE_NEW_PROCESS_GROUP}
+}
+
+// signalProcessGroup stops the process tree of the started command. Windows does not support signals, thus
+// SIGKILL forcefully terminates the tree while all other signals send a CTRL_BREAK_EVENT to the process group,
+// which console processes like node or dotnet handle like an interrupt.
+func signalProcessGroup(cmd *exec.Cmd, signal syscall.Signal) error {
+	if signal == syscall.SIGKILL {
+		return exec.Command("taskkill", "/F", "/T", "/PID", strconv.Itoa(cmd.Process.Pid)).Run()
+	}
+	// the process group id equals the pid of the process created with CREATE_NEW_PROCESS_GROUP
+	return windows.GenerateConsoleCtrlEvent(windows.CTRL_BREAK_EVENT, uint32(cmd.Process.Pid))
+}
+
+func startTerminal(cmd *exec.Cmd, cols, rows int) (*os.File, error) {
+	return nil, errTTYNotSupported
+}
+
+func setTerminalSize(terminal *os.File, cols, rows int) error {
+	return errTTYNotSupported
+}
@@ -0,0 +1,61 @@
+package tasks
+
+import "time"
+
+// A RestartMode determines when the main task of a node is restarted after it exited.
+type RestartMode string
+
+const (
+	// RestartNever never restarts the task.
+	RestartNever RestartMode = "never"
+	// RestartOnFailure restarts the task whenever it failed.
+	RestartOnFailure RestartMode = "on-failure"
+	// RestartAlways restarts the task whenever it exited, unless it was canceled.
+	RestartAlways RestartMode = "always"
+)
+
+// RestartModes contains all restart modes.
+var RestartModes = []RestartMode{RestartNever, RestartOnFailure, RestartAlways}
+
+const (
+	// DefaultRestartBackoff is the delay before the first restart if no backoff is configured.
+	DefaultRestartBackoff = time.Second
+	// maxRestartBackoff is the upper limit of the exponentially growing delay between restarts.
+	maxRestartBackoff = 30 * time.Second
+)
+
+// A RestartPolicy describes whether and how often the main task of a node is restarted.
+type RestartPolicy struct {
+	Mode        RestartMode   // when the task is restarted
+	MaxAttempts int           // the maximum amount of restarts, 0 means unlimited
+	Backoff     time.Duration // the delay before the first restart, doubled with every further restart
+}
+
+// ShouldRestart reports whether the task should be restarted after it exited with err
+// and was already restarted the given amount of times.
+func (p RestartPolicy) ShouldRestart(err error, restarts int) bool {
+	if p.MaxAttempts > 0 && restarts >= p.MaxAttempts {
+		return false
+	}
+
+	switch p.Mode {
+	case RestartAlways:
+		return true
+	case RestartOnFailure:
+		return err != nil
+	default:
+		return false
+	}
+}
+
+// Delay returns the delay before the nth restart (starting at 1).
+func (p RestartPolicy) Delay(restart int) time.Duration {
+	delay := p.Backoff
+	if delay <= 0 {
+		delay = DefaultRestartBackoff
+	}
+	for i := 1; i < restart && delay < maxRestartBackoff; i++ {
+		delay *= 2
+	}
+	return min(delay, maxRestartBackoff)
+}
@@ -0,0 +1,56 @@
+package tasks
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestRestartPolicyShouldRestart(t *testing.T) {
+	err := errors.New("failed")
+	tests := []struct {
+		name     string
+		policy   RestartPolicy
+		err      error
+		restarts int
+		want     bool
+	}{
+		{"never should not restart", RestartPolicy{Mode: RestartNever}, err, 0, false},
+		{"on-failure should restart failed tasks", RestartPolicy{Mode: RestartOnFailure}, err, 0, true},
+		{"on-failure should not restart successful tasks", RestartPolicy{Mode: RestartOnFailure}, nil, 0, false},
+		{"always should restart successful tasks", RestartPolicy{Mode: RestartAlways}, nil, 0, true},
+		{"should restart below max attempts", RestartPolicy{Mode: RestartAlways, MaxAttempts: 2}, err, 1, true},
+		{"should not restart after max attempts", RestartPolicy{Mode: RestartAlways, MaxAttempts: 2}, err, 2, false},
+		{"should restart unlimited without max attempts", RestartPolicy{Mode: RestartAlways}, err, 100, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.policy.ShouldRestart(tt.err, tt.restarts); got != tt.want {
+				t.Errorf("ShouldRestart() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRestartPolicyDelay(t *testing.T) {
+	tests := []struct {
+		name    string
+		policy  RestartPolicy
+		restart int
+		want    time.Duration
+	}{
+		{"should use default backoff", RestartPolicy{}, 1, DefaultRestartBackoff},
+		{"should use backoff for first restart", RestartPolicy{Backoff: 100 * time.Millisecond}, 1, 100 * time.Millisecond},
+		{"should double backoff", RestartPolicy{Backoff: 100 * time.Millisecond}, 3, 400 * time.Millisecond},
+		{"should limit backoff", RestartPolicy{Backoff: time.Second}, 20, maxRestartBackoff},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.policy.Delay(tt.restart); got != tt.want {
+				t.Errorf("Delay() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
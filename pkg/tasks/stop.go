@@ -0,0 +1,66 @@
+package tasks
+
+import (
+	"fmt"
+	"strings"
+	"syscall"
+	"time"
+)
+
+// DefaultStopTimeout is the time a task is given to exit after the stop signal before it's killed.
+const DefaultStopTimeout = 5 * time.Second
+
+// A StopPolicy describes how a canceled task is terminated. The stop signal is sent to the
+// process group of the task, if it did not exit within the timeout, the group is killed.
+type StopPolicy struct {
+	Signal  syscall.Signal // the signal sent to the process group
+	Timeout time.Duration  // the time to wait for the process group to exit
+}
+
+// DefaultStopPolicy sends SIGTERM and kills the process group after DefaultStopTimeout.
+var DefaultStopPolicy = StopPolicy{
+	Signal:  syscall.SIGTERM,
+	Timeout: DefaultStopTimeout,
+}
+
+var signals = map[string]syscall.Signal{
+	"SIGHUP":  syscall.SIGHUP,
+	"SIGINT":  syscall.SIGINT,
+	"SIGQUIT": syscall.SIGQUIT,
+	"SIGKILL": syscall.SIGKILL,
+	"SIGTERM": syscall.SIGTERM,
+}
+
+// ParseSignal returns the signal for a name like SIGINT or INT.
+func ParseSignal(name string) (syscall.Signal, error) {
+	normalized := strings.ToUpper(strings.TrimSpace(name))
+	if !strings.HasPrefix(normalized, "SIG") {
+		normalized = "SIG" + normalized
+	}
+	if signal, ok := signals[normalized]; ok {
+		return signal, nil
+	}
+	return 0, fmt.Errorf("unknown signal '%s'", name)
+}
+
+func signalName(signal syscall.Signal) string {
+	for name, s := range signals {
+		if s == signal {
+			return name
+		}
+	}
+	return signal.String()
+}
+
+// stoppable is implemented by tasks that can be terminated according to a stop policy.
+type stoppable interface {
+	setStopPolicy(policy StopPolicy)
+}
+
+// SetStopPolicy sets the policy used to terminate the task when it's canceled.
+// Tasks that are not backed by a process ignore the policy.
+func SetStopPolicy(task Task, policy StopPolicy) {
+	if s, ok := task.(stoppable); ok {
+		s.setStopPolicy(policy)
+	}
+}
@@ -0,0 +1,33 @@
+package tasks
+
+import (
+	"syscall"
+	"testing"
+)
+
+func TestParseSignal(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		expected syscall.Signal
+		wantErr  bool
+	}{
+		{"Should parse full name", "SIGTERM", syscall.SIGTERM, false},
+		{"Should parse short name", "INT", syscall.SIGINT, false},
+		{"Should ignore case", "sigkill", syscall.SIGKILL, false},
+		{"Should fail for unknown signal", "SIGFOO", 0, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseSignal(tt.input)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("ParseSignal() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if got != tt.expected {
+				t.Errorf("ParseSignal() = %v, want %v", got, tt.expected)
+			}
+		})
+	}
+}
@@ -1,6 +1,7 @@
 package tasks
 
 import (
+	"fmt"
 	"io"
 	"strings"
 
@@ -13,6 +14,42 @@ type Task interface {
 	Pipe(destination io.Writer)
 }
 
+// describedTask is implemented by tasks that execute a command.
+type describedTask interface {
+	commandLine() string
+}
+
+// CommandLine returns the command executed by the task, it's empty for tasks that don't execute a command.
+func CommandLine(task Task) string {
+	if t, ok := task.(describedTask); ok {
+		return t.commandLine()
+	}
+	return ""
+}
+
+// outputTask is implemented by tasks that allow writing to their output from outside of the task.
+type outputTask interface {
+	output() io.Writer
+}
+
+// taskOutput returns the writer the task writes its output to, the output of other tasks is discarded.
+func taskOutput(task Task) io.Writer {
+	if t, ok := task.(outputTask); ok {
+		return t.output()
+	}
+	return io.Discard
+}
+
+// WriteWarning writes a warning to the output of the task, e.g. about a problem that doesn't fail the task.
+func WriteWarning(task Task, message string) {
+	fmt.Fprintf(taskOutput(task), "warning: %s\n", message)
+}
+
+// An InputTask is a task that accepts input on its stdin while running.
+type InputTask interface {
+	WriteInput(data []byte) error
+}
+
 type Collection []*TaskTreeNode
 
 func NewCollection(nodes ...*TaskTreeNode) Collection {
@@ -0,0 +1,33 @@
+package tasks
+
+import "time"
+
+const (
+	// DefaultTerminalCols is the width of a pseudo-terminal until it's resized.
+	DefaultTerminalCols = 80
+	// DefaultTerminalRows is the height of a pseudo-terminal until it's resized.
+	DefaultTerminalRows = 24
+	// terminalDrainTimeout is the time to wait for the remaining output of a terminal after the command exited.
+	terminalDrainTimeout = 100 * time.Millisecond
+)
+
+// terminalTask is implemented by tasks that can be attached to a pseudo-terminal.
+type terminalTask interface {
+	enableTTY()
+	resizeTerminal(cols, rows int)
+}
+
+// EnableTTY runs the task attached to a pseudo-terminal, so that tools keep their colors and tty behavior.
+// The option is ignored by tasks that are not backed by a process and on platforms without pseudo-terminals.
+func EnableTTY(task Task) {
+	if t, ok := task.(terminalTask); ok && ttySupported {
+		t.enableTTY()
+	}
+}
+
+// ResizeTerminal sets the size of the pseudo-terminal of the task, tasks without a pseudo-terminal ignore it.
+func ResizeTerminal(task Task, cols, rows int) {
+	if t, ok := task.(terminalTask); ok && cols > 0 && rows > 0 {
+		t.resizeTerminal(cols, rows)
+	}
+}
@@ -7,17 +7,36 @@ import (
 // A TaskTreeNode represents a node in a task tree.
 // A task tree is a tree structure that represents the order of tasks to be executed,
 // each node has a main task and a collection of nodes that should be executed before
-// and after the main task.
+// and after the main task. Shared dependencies are a single node with several parents,
+// which turns the tree into a directed acyclic graph.
 type TaskTreeNode struct {
-	Name   string          // the name of the node
-	Pre    []*TaskTreeNode // a collection of nodes that should be executed before the main task
-	Main   Task            // the main task
-	Post   []*TaskTreeNode // a collection of nodes that should be executed after the main task
-	Parent *TaskTreeNode   // the parent node
+	Name    string          // the name of the node
+	Pre     []*TaskTreeNode // a collection of nodes that should be executed before the main task
+	Main    Task            // the main task
+	Post    []*TaskTreeNode // a collection of nodes that should be executed after the main task
+	OnError []*TaskTreeNode // a collection of nodes that should be executed when the main task or its pre nodes failed
+	Finally []*TaskTreeNode // a collection of nodes that should always be executed after the main task
+	Parent  *TaskTreeNode   // the parent node, for nodes with several parents the one it was added to first
+	// Directory is the directory the main task is executed in, it's empty for nodes without a directory
+	Directory string
+	// Env contains the environment variables the main task is executed with in addition to the environment of zwooc
+	Env []EnvVariable
+	// Key identifies the entity (profile or fragment in a mode) the node was loaded from. Dependencies with
+	// the same key share a single execution of their main task.
+	Key string
 
 	// IsLongRunning indicates whether the main task is long running
 	// usually, only the main tasks of run or watch modes or such dependent fragments are long running
 	IsLongRunning bool
+	// AllowFailure indicates whether a failure of this node should not fail the nodes depending on it
+	AllowFailure bool
+	// ReadyWhen is an optional probe, once it passes the nodes depending on this node are executed
+	// even though the main task is still running
+	ReadyWhen *ReadinessProbe
+	// Restart is an optional policy that determines whether the main task is restarted after it exited
+	Restart *RestartPolicy
+	// UpToDate is an optional check that determines whether the main task can be skipped since nothing changed
+	UpToDate *UpToDateCheck
 }
 
 func NewTaskTree(name string, mainTask Task, isLongRunning bool) *TaskTreeNode {
@@ -26,38 +45,75 @@ func NewTaskTree(name string, mainTask Task, isLongRunning bool) *TaskTreeNode {
 		Pre:           []*TaskTreeNode{},
 		Main:          mainTask,
 		Post:          []*TaskTreeNode{},
+		OnError:       []*TaskTreeNode{},
+		Finally:       []*TaskTreeNode{},
 		IsLongRunning: isLongRunning,
 	}
 }
 
 func (t TaskTreeNode) IsLeaf() bool {
-	return len(t.Pre) == 0 && len(t.Post) == 0
+	return len(t.Pre) == 0 && len(t.Post) == 0 && len(t.OnError) == 0 && len(t.Finally) == 0
 }
 
 // AddPreChild adds a child node to the pre collection.
-// The child nodes parent is set to the current node.
+// The child nodes parent is set to the current node, unless it already has a parent.
 func (t *TaskTreeNode) AddPreChild(child ...*TaskTreeNode) {
-	for _, c := range child {
-		c.Parent = t
-	}
+	setParent(t, child)
 	t.Pre = append(t.Pre, child...)
 }
 
 // AddPostChild adds a child node to the post collection.
-// The child nodes parent is set to the current node.
+// The child nodes parent is set to the current node, unless it already has a parent.
 func (t *TaskTreeNode) AddPostChild(child ...*TaskTreeNode) {
-	for _, c := range child {
-		c.Parent = t
-	}
+	setParent(t, child)
 	t.Post = append(t.Post, child...)
 }
 
+// AddOnErrorChild adds a child node to the onError collection.
+// The child nodes parent is set to the current node, unless it already has a parent.
+func (t *TaskTreeNode) AddOnErrorChild(child ...*TaskTreeNode) {
+	setParent(t, child)
+	t.OnError = append(t.OnError, child...)
+}
+
+// AddFinallyChild adds a child node to the finally collection.
+// The child nodes parent is set to the current node, unless it already has a parent.
+func (t *TaskTreeNode) AddFinallyChild(child ...*TaskTreeNode) {
+	setParent(t, child)
+	t.Finally = append(t.Finally, child...)
+}
+
+// setParent sets the parent of all children without a parent, thus the first parent of a shared node
+// determines its id.
+func setParent(parent *TaskTreeNode, children []*TaskTreeNode) {
+	for _, child := range children {
+		if child.Parent == nil {
+			child.Parent = parent
+		}
+	}
+}
+
+// Children returns all direct child nodes.
+func (t *TaskTreeNode) Children() []*TaskTreeNode {
+	return helper.Concat(t.Pre, t.Post, t.OnError, t.Finally)
+}
+
+// DependsOn reports whether the node itself or any node that is executed before it satisfies the predicate.
+func (t *TaskTreeNode) DependsOn(predicate func(node *TaskTreeNode) bool) bool {
+	if predicate(t) {
+		return true
+	}
+	return helper.Some(t.Pre, func(child *TaskTreeNode) bool {
+		return child.DependsOn(predicate)
+	})
+}
+
 // FindNode returns a (child-)node with the given name.
 func (t *TaskTreeNode) FindNode(name string) *TaskTreeNode {
 	if t.Name == name {
 		return t
 	}
-	for _, child := range helper.Concat(t.Pre, t.Post) {
+	for _, child := range t.Children() {
 		if node := child.FindNode(name); node != nil {
 			return node
 		}
@@ -87,49 +143,53 @@ func (t *TaskTreeNode) NodeID() string {
 }
 
 // Iterate traverses the tree in depth-first order and calls the handler for each node.
+// Nodes with several parents are only visited once.
 func (t *TaskTreeNode) Iterate(handler func(node *TaskTreeNode)) {
+	t.iterate(handler, map[*TaskTreeNode]bool{})
+}
+
+func (t *TaskTreeNode) iterate(handler func(node *TaskTreeNode), visited map[*TaskTreeNode]bool) {
+	if visited[t] {
+		return
+	}
+	visited[t] = true
 	for _, pre := range t.Pre {
-		pre.Iterate(handler)
+		pre.iterate(handler, visited)
 	}
 	handler(t)
-	for _, post := range t.Post {
-		post.Iterate(handler)
+	for _, post := range helper.Concat(t.Post, t.OnError, t.Finally) {
+		post.iterate(handler, visited)
 	}
 }
 
 // RemoveEmptyNodes removes all nodes that have an empty main task.
 func (t *TaskTreeNode) RemoveEmptyNodes() {
-	for i := 0; i < len(t.Pre); i++ {
-		if IsEmptyTask(t.Pre[i].Main) {
-			t.Pre = append(t.Pre[:i], t.Pre[i+1:]...)
-			i--
-		} else {
-			t.Pre[i].RemoveEmptyNodes()
-		}
-	}
-	for i := 0; i < len(t.Post); i++ {
-		if IsEmptyTask(t.Post[i].Main) {
-			t.Post = append(t.Post[:i], t.Post[i+1:]...)
+	t.Pre = removeEmptyNodes(t.Pre)
+	t.Post = removeEmptyNodes(t.Post)
+	t.OnError = removeEmptyNodes(t.OnError)
+	t.Finally = removeEmptyNodes(t.Finally)
+}
+
+func removeEmptyNodes(nodes []*TaskTreeNode) []*TaskTreeNode {
+	for i := 0; i < len(nodes); i++ {
+		if IsEmptyTask(nodes[i].Main) {
+			nodes = append(nodes[:i], nodes[i+1:]...)
 			i--
 		} else {
-			t.Post[i].RemoveEmptyNodes()
+			nodes[i].RemoveEmptyNodes()
 		}
 	}
+	return nodes
 }
 
 // IsLinear returns true if the tree is linear, i.e. each node has at most one child node.
 func (t *TaskTreeNode) IsLinear() bool {
-	if len(t.Pre) > 1 || len(t.Post) > 1 {
+	if len(t.Pre) > 1 || len(t.Post) > 1 || len(t.OnError) > 1 || len(t.Finally) > 1 {
 		return false
 	}
 
-	for _, pre := range t.Pre {
-		if !pre.IsLinear() {
-			return false
-		}
-	}
-	for _, post := range t.Post {
-		if !post.IsLinear() {
+	for _, child := range t.Children() {
+		if !child.IsLinear() {
 			return false
 		}
 	}
@@ -158,9 +218,23 @@ func (t *TaskTreeNode) Flatten() TaskList {
 	}
 	list.InsertAfter(postList)
 
+	// task lists can't express conditional execution, $onError and $finally nodes are not included
 	return list
 }
 
+// HasConditionalNodes reports whether the tree contains $onError or $finally nodes, which can't be flattened.
+func (t *TaskTreeNode) HasConditionalNodes() bool {
+	if len(t.OnError) > 0 || len(t.Finally) > 0 {
+		return true
+	}
+	for _, child := range helper.Concat(t.Pre, t.Post) {
+		if child.HasConditionalNodes() {
+			return true
+		}
+	}
+	return false
+}
+
 // CountStages returns the number of stages in the tree if it would be executed flattened.
 func (t *TaskTreeNode) CountStages() int {
 	preCount := 0
@@ -177,5 +251,6 @@ func (t *TaskTreeNode) CountStages() int {
 			postCount = count
 		}
 	}
+	// like Flatten, $onError and $finally nodes are not counted
 	return 1 + preCount + postCount
 }
@@ -27,3 +27,46 @@ func TestTree(t *testing.T) {
 		t.Errorf("Expected 7 steps, got %d", len(list.Steps))
 	}
 }
+
+func TestTreeConditionalNodes(t *testing.T) {
+	tree := NewTaskTree("root", Empty(), false)
+	child := NewTaskTree("child", Empty(), false)
+	tree.AddPreChild(child)
+	if tree.HasConditionalNodes() {
+		t.Errorf("expected no conditional nodes")
+	}
+
+	child.AddFinallyChild(NewTaskTree("cleanup", Empty(), false))
+	if !tree.HasConditionalNodes() {
+		t.Errorf("expected the $finally node of a child to be detected")
+	}
+	if len(tree.Flatten().Steps) != 2 {
+		t.Errorf("expected $finally nodes to be excluded from the task list, got %d steps", len(tree.Flatten().Steps))
+	}
+	if tree.CountStages() != len(tree.Flatten().Steps) {
+		t.Errorf("expected %d stages like the task list, got %d", len(tree.Flatten().Steps), tree.CountStages())
+	}
+}
+
+func TestTreeSharedNodes(t *testing.T) {
+	tree := NewTaskTree("root", Empty(), false)
+	shared := NewTaskTree("shared", Empty(), false)
+	web := NewTaskTree("web", Empty(), false)
+	app := NewTaskTree("app", Empty(), false)
+	web.AddPreChild(shared)
+	app.AddPreChild(shared)
+	tree.AddPreChild(web, app)
+
+	if shared.NodeID() != "root/web/shared" {
+		t.Errorf("expected the first parent to determine the id, got %s", shared.NodeID())
+	}
+	visited := 0
+	tree.Iterate(func(node *TaskTreeNode) {
+		if node == shared {
+			visited++
+		}
+	})
+	if visited != 1 {
+		t.Errorf("expected the shared node to be visited once, got %d", visited)
+	}
+}
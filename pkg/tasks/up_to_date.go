@@ -0,0 +1,157 @@
+package tasks
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/zwoo-hq/zwooc/pkg/helper"
+)
+
+// An UpToDateCheck determines whether the main task of a node can be skipped, since neither its inputs
+// nor its command changed since its last successful run and all of its outputs still exist.
+// If an artifact cache is configured, the outputs of successful runs are stored in it and restored
+// instead of running the task again.
+type UpToDateCheck struct {
+	Name      string         // the name of the node, nodes with equal commands and inputs don't share their outputs
+	Directory string         // the directory inputs and outputs are relative to
+	Inputs    []string       // glob patterns of the files the task depends on
+	Outputs   []string       // glob patterns of the files the task produces
+	Env       []string       // the names of environment variables the task depends on
+	Record    string         // the file the fingerprint of the last successful run is stored in
+	Artifacts *ArtifactCache // an optional cache for the outputs of the task
+
+	log         *CommandCapturer
+	captureOnce sync.Once
+}
+
+type upToDateRecord struct {
+	Fingerprint string    `json:"fingerprint"`
+	UpdatedAt   time.Time `json:"updatedAt"`
+}
+
+// Fingerprint hashes the command of the task, the values of the environment variables and the content of all inputs.
+func (c *UpToDateCheck) Fingerprint(task Task) (string, error) {
+	hash := sha256.New()
+	fmt.Fprintf(hash, "name\x00%s\n", c.Name)
+	fmt.Fprintf(hash, "command\x00%s\n", CommandLine(task))
+
+	env := slices.Clone(c.Env)
+	slices.Sort(env)
+	for _, name := range env {
+		fmt.Fprintf(hash, "env\x00%s\x00%s\n", name, os.Getenv(name))
+	}
+
+	inputs, err := helper.Glob(c.Directory, c.Inputs)
+	if err != nil {
+		return "", fmt.Errorf("invalid inputs: %w", err)
+	}
+	for _, input := range inputs {
+		fileHash, err := hashFile(filepath.Join(c.Directory, filepath.FromSlash(input)))
+		if err != nil {
+			return "", err
+		}
+		fmt.Fprintf(hash, "input\x00%s\x00%s\n", input, fileHash)
+	}
+	return hex.EncodeToString(hash.Sum(nil)), nil
+}
+
+// IsUpToDate reports whether the fingerprint matches the last successful run and all outputs exist.
+func (c *UpToDateCheck) IsUpToDate(fingerprint string) bool {
+	content, err := os.ReadFile(c.Record)
+	if err != nil {
+		return false
+	}
+
+	record := upToDateRecord{}
+	if err := json.Unmarshal(content, &record); err != nil || record.Fingerprint != fingerprint {
+		return false
+	}
+
+	for _, output := range c.Outputs {
+		files, err := helper.Glob(c.Directory, []string{output})
+		if err != nil || len(files) == 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// Capture starts recording the output of the task, the recorded log is stored together with the outputs
+// by Save. Capture must be called before each run of the task, it does nothing without an artifact cache.
+func (c *UpToDateCheck) Capture(task Task) {
+	if c.Artifacts == nil || len(c.Outputs) == 0 {
+		return
+	}
+	c.captureOnce.Do(func() {
+		c.log = NewCapturer()
+		task.Pipe(c.log)
+	})
+	c.log.Reset()
+}
+
+// Restore restores the outputs of a previous run with the fingerprint from the artifact cache and replays
+// its log to the output of the task. It reports whether the cache contained the outputs.
+func (c *UpToDateCheck) Restore(task Task, fingerprint string) (bool, error) {
+	if c.Artifacts == nil || len(c.Outputs) == 0 {
+		return false, nil
+	}
+
+	restored, err := c.Artifacts.Restore(fingerprint, c.Directory, c.Outputs, taskOutput(task))
+	if err != nil || !restored {
+		return false, err
+	}
+	return true, c.saveRecord(fingerprint)
+}
+
+// Save stores the fingerprint of a successful run, as well as its outputs and log if an artifact cache is configured.
+func (c *UpToDateCheck) Save(fingerprint string) error {
+	if err := c.saveRecord(fingerprint); err != nil {
+		return err
+	}
+	if c.Artifacts == nil || c.log == nil {
+		return nil
+	}
+
+	outputs, err := helper.Glob(c.Directory, c.Outputs)
+	if err != nil {
+		return err
+	}
+	return c.Artifacts.Store(fingerprint, c.Directory, outputs, c.log.Bytes())
+}
+
+func (c *UpToDateCheck) saveRecord(fingerprint string) error {
+	content, err := json.Marshal(upToDateRecord{
+		Fingerprint: fingerprint,
+		UpdatedAt:   time.Now(),
+	})
+	if err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(c.Record), 0755); err != nil {
+		return err
+	}
+	return os.WriteFile(c.Record, content, 0644)
+}
+
+func hashFile(path string) (string, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return "", err
+	}
+	defer file.Close()
+
+	hash := sha256.New()
+	if _, err := io.Copy(hash, file); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(hash.Sum(nil)), nil
+}
@@ -0,0 +1,55 @@
+package tasks
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestUpToDateCheck(t *testing.T) {
+	dir := t.TempDir()
+	os.WriteFile(filepath.Join(dir, "input.txt"), []byte("v1"), 0644)
+	check := UpToDateCheck{
+		Directory: dir,
+		Inputs:    []string{"*.txt"},
+		Outputs:   []string{"dist/**"},
+		Env:       []string{"ZWOOC_TEST_INPUT"},
+		Record:    filepath.Join(dir, "record.json"),
+	}
+	task := NewBasicCommandTask("test", "echo", dir, []string{"hello"})
+
+	fingerprint, err := check.Fingerprint(task)
+	if err != nil {
+		t.Fatalf("Fingerprint() error = %v", err)
+	}
+	if check.IsUpToDate(fingerprint) {
+		t.Errorf("expected task without record to be outdated")
+	}
+
+	check.Save(fingerprint)
+	if check.IsUpToDate(fingerprint) {
+		t.Errorf("expected task with missing outputs to be outdated")
+	}
+	os.MkdirAll(filepath.Join(dir, "dist"), 0755)
+	os.WriteFile(filepath.Join(dir, "dist", "out.txt"), []byte("out"), 0644)
+	if !check.IsUpToDate(fingerprint) {
+		t.Errorf("expected task to be up to date")
+	}
+
+	changes := map[string]func(){
+		"input":   func() { os.WriteFile(filepath.Join(dir, "input.txt"), []byte("v2"), 0644) },
+		"env":     func() { t.Setenv("ZWOOC_TEST_INPUT", "changed") },
+		"command": func() { task = NewBasicCommandTask("test", "echo", dir, []string{"world"}) },
+	}
+	for _, name := range []string{"input", "env", "command"} {
+		changes[name]()
+		changed, err := check.Fingerprint(task)
+		if err != nil {
+			t.Fatalf("Fingerprint() error = %v", err)
+		}
+		if changed == fingerprint {
+			t.Errorf("expected fingerprint to change after changing the %s", name)
+		}
+		fingerprint = changed
+	}
+}
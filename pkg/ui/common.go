@@ -7,6 +7,7 @@ import (
 
 	"github.com/charmbracelet/bubbles/spinner"
 	"github.com/charmbracelet/lipgloss"
+	"github.com/zwoo-hq/zwooc/pkg/tasks"
 )
 
 var (
@@ -77,11 +78,16 @@ var (
 	successStyle                  = lipgloss.NewStyle().Foreground(lipgloss.Color("46"))
 	errorStyle                    = lipgloss.NewStyle().Foreground(lipgloss.Color("124"))
 	canceledStyle                 = lipgloss.NewStyle().Foreground(lipgloss.Color("246"))
+	skippedStyle                  = lipgloss.NewStyle().Foreground(lipgloss.Color("178"))
+	cachedStyle                   = lipgloss.NewStyle().Foreground(lipgloss.Color("35"))
 	stepStyle                     = lipgloss.NewStyle().Foreground(lipgloss.Color("93")).Bold(true)
 	graphHeaderStyle              = lipgloss.NewStyle().Foreground(lipgloss.Color("93")).Bold(true)
 	graphMainStyle                = lipgloss.NewStyle().Foreground(lipgloss.Color("93"))
 	graphPreStyle                 = lipgloss.NewStyle().Foreground(lipgloss.Color("117")).Italic(true)
 	graphPostStyle                = lipgloss.NewStyle().Foreground(lipgloss.Color("130")).Italic(true)
+	restartStyle                  = lipgloss.NewStyle().Foreground(lipgloss.Color("178"))
+	graphOnErrorStyle             = lipgloss.NewStyle().Foreground(lipgloss.Color("124")).Italic(true)
+	graphFinallyStyle             = lipgloss.NewStyle().Foreground(lipgloss.Color("178")).Italic(true)
 	graphInfoStyle                = lipgloss.NewStyle().Foreground(lipgloss.Color("249")).Faint(true)
 	interactiveKeyStyle           = lipgloss.NewStyle().Background(lipgloss.Color("239")).Foreground(lipgloss.Color("152")).Padding(0, 1).Bold(true)
 	interactiveTabStyle           = pendingStyle.Copy()
@@ -95,10 +101,13 @@ var (
 	treeSuccessStyle              = successStyle.Copy()
 	treeErrorStyle                = errorStyle.Copy()
 	treeCanceledStyle             = canceledStyle.Copy()
+	treeSkippedStyle              = skippedStyle.Copy()
+	treeCachedStyle               = cachedStyle.Copy()
 
 	successIcon = successStyle.Render("✓")
 	cancelIcon  = canceledStyle.Render("-")
 	errorIcon   = errorStyle.Render("✗")
+	skipIcon    = skippedStyle.Render("»")
 )
 
 func HandleError(err error) {
@@ -111,3 +120,13 @@ func HandleError(err error) {
 func PrintSuccess(msg string) {
 	fmt.Printf(" %s %s\n", successStyle.Render("✓"), msg)
 }
+
+// printUnfinishedNodes prints all nodes that were skipped or never started during a failed run.
+func printUnfinishedNodes(err *tasks.MultiTaskError) {
+	for _, nodeId := range err.Skipped {
+		fmt.Printf(" %s %s was skipped\n", skipIcon, nodeId)
+	}
+	for _, nodeId := range err.NotStarted {
+		fmt.Printf(" %s %s never started\n", cancelIcon, nodeId)
+	}
+}
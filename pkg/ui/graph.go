@@ -2,6 +2,7 @@ package ui
 
 import (
 	"fmt"
+	"slices"
 
 	"github.com/zwoo-hq/zwooc/pkg/model"
 	"github.com/zwoo-hq/zwooc/pkg/tasks"
@@ -11,6 +12,11 @@ type graphView struct {
 	forest tasks.Collection
 }
 
+type graphSection struct {
+	name  string
+	nodes []*tasks.TaskTreeNode
+}
+
 func GraphDependencies(collection tasks.Collection, name string) {
 	fmt.Printf("%s - graphing dependency tree for %s\n", zwoocBranding, name)
 	view := graphView{forest: collection}
@@ -29,7 +35,7 @@ func (g *graphView) View() (s string) {
 
 func (g *graphView) printGraphNode(node *tasks.TaskTreeNode, prefix string, isLast bool) (s string) {
 	connector := "┬"
-	if len(node.Pre) == 0 && len(node.Post) == 0 {
+	if node.IsLeaf() {
 		connector = "─"
 	}
 	if isLast {
@@ -38,34 +44,36 @@ func (g *graphView) printGraphNode(node *tasks.TaskTreeNode, prefix string, isLa
 		s += fmt.Sprintf("%s├─%s%s %s\n", prefix, connector, graphMainStyle.Render(node.Name), graphInfoStyle.Render(node.Main.Name()))
 	}
 
-	if len(node.Pre) > 0 {
-		newPrefix := "│ "
-		prePrefix := "│ "
-		if isLast {
-			prePrefix = "  "
-		}
-		name := graphPreStyle.Render(model.KeyPre)
-		info := graphInfoStyle.Render(fmt.Sprintf("(%d nodes)", len(node.Pre)))
-		if len(node.Post) == 0 {
-			newPrefix = "  "
-			s += fmt.Sprintf("%s%s└─┬%s %s\n", prefix, prePrefix, name, info)
-		} else {
-			s += fmt.Sprintf("%s%s├─┬%s %s\n", prefix, prePrefix, name, info)
-		}
-
-		for i, child := range node.Pre {
-			s += g.printGraphNode(child, prefix+prePrefix+newPrefix, i == len(node.Pre)-1)
-		}
+	sections := []graphSection{
+		{graphPreStyle.Render(model.KeyPre), node.Pre},
+		{graphPostStyle.Render(model.KeyPost), node.Post},
+		{graphOnErrorStyle.Render(model.KeyOnError), node.OnError},
+		{graphFinallyStyle.Render(model.KeyFinally), node.Finally},
 	}
+	sections = slices.DeleteFunc(sections, func(section graphSection) bool {
+		return len(section.nodes) == 0
+	})
 
-	if len(node.Post) > 0 {
-		postPrefix := "│ "
-		if isLast {
-			postPrefix = "  "
+	sectionPrefix := "│ "
+	if isLast {
+		sectionPrefix = "  "
+	}
+	// values are omitted, since env files usually contain secrets
+	for _, variable := range node.Env {
+		s += fmt.Sprintf("%s%s %s\n", prefix, sectionPrefix, graphInfoStyle.Render(fmt.Sprintf("env %s (%s)", variable.Name, variable.Source)))
+	}
+	for i, section := range sections {
+		connector := "├─┬"
+		childPrefix := "│ "
+		if i == len(sections)-1 {
+			connector = "└─┬"
+			childPrefix = "  "
 		}
-		s += fmt.Sprintf("%s%s└─┬%s %s\n", prefix, postPrefix, graphPostStyle.Render(model.KeyPost), graphInfoStyle.Render(fmt.Sprintf("(%d tasks)", len(node.Post))))
-		for i, child := range node.Post {
-			s += g.printGraphNode(child, prefix+postPrefix+"  ", i == len(node.Post)-1)
+
+		info := graphInfoStyle.Render(fmt.Sprintf("(%d nodes)", len(section.nodes)))
+		s += fmt.Sprintf("%s%s%s%s %s\n", prefix, sectionPrefix, connector, section.name, info)
+		for j, child := range section.nodes {
+			s += g.printGraphNode(child, prefix+sectionPrefix+childPrefix, j == len(section.nodes)-1)
 		}
 	}
 	return
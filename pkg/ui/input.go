@@ -0,0 +1,41 @@
+package ui
+
+import (
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+var inputSequences = map[tea.KeyType]string{
+	tea.KeyEnter:    "\n",
+	tea.KeySpace:    " ",
+	tea.KeyUp:       "\x1b[A",
+	tea.KeyDown:     "\x1b[B",
+	tea.KeyRight:    "\x1b[C",
+	tea.KeyLeft:     "\x1b[D",
+	tea.KeyHome:     "\x1b[H",
+	tea.KeyEnd:      "\x1b[F",
+	tea.KeyDelete:   "\x1b[3~",
+	tea.KeyPgUp:     "\x1b[5~",
+	tea.KeyPgDown:   "\x1b[6~",
+	tea.KeyShiftTab: "\x1b[Z",
+}
+
+// keyToInput converts a key press into the bytes a terminal would send to the stdin of a process.
+// It returns nil for keys without a representation.
+func keyToInput(msg tea.KeyMsg) []byte {
+	var input string
+	if msg.Type == tea.KeyRunes {
+		input = string(msg.Runes)
+	} else if sequence, ok := inputSequences[msg.Type]; ok {
+		input = sequence
+	} else if msg.Type >= 0 && msg.Type < 32 || msg.Type == tea.KeyBackspace {
+		// control characters like ctrl+r are represented by their key type
+		input = string(rune(msg.Type))
+	} else {
+		return nil
+	}
+
+	if msg.Alt {
+		input = "\x1b" + input
+	}
+	return []byte(input)
+}
@@ -4,6 +4,8 @@ import (
 	"errors"
 	"fmt"
 	"os"
+	"slices"
+	"strings"
 	"time"
 
 	"github.com/charmbracelet/bubbles/spinner"
@@ -36,6 +38,8 @@ type interactiveTab struct {
 	writer   *tasks.NotifyWriter
 	showLogs bool
 	task     *tasks.TaskTreeNode
+	// selected is the node controlled by the keybindings, it defaults to the root of the tab
+	selected *tasks.TaskTreeNode
 }
 
 type interactiveView struct {
@@ -43,9 +47,12 @@ type interactiveView struct {
 	opts     ViewOptions
 	provider *SchedulerStatusProvider
 
-	outputs          map[string]*tasks.CommandCapturer
+	outputs map[string]*tasks.CommandCapturer
+	// captured contains all nodes whose output is captured, shared nodes are part of several trees
+	captured         map[*tasks.TaskTreeNode]bool
 	status           map[string]TaskStatus
 	aggregatedStatus map[string]TaskStatus
+	restarts         map[string]int
 
 	viewportReady bool
 	activeIndex   int
@@ -63,6 +70,12 @@ type interactiveView struct {
 	wasCancelCanceled bool
 	err               error
 	clear             bool
+	// message is a short feedback of the last action that is shown in the footer
+	message string
+	// inputMode forwards all key presses to the stdin of the active tabs main task
+	inputMode bool
+	// inputLine echoes the current line of input, since the stdin of tasks is not echoed
+	inputLine string
 }
 
 func newInteractiveView(forest tasks.Collection, provider *SchedulerStatusProvider, opts ViewOptions) error {
@@ -73,7 +86,9 @@ func newInteractiveView(forest tasks.Collection, provider *SchedulerStatusProvid
 
 		status:           map[string]TaskStatus{},
 		aggregatedStatus: map[string]TaskStatus{},
+		restarts:         map[string]int{},
 		outputs:          map[string]*tasks.CommandCapturer{},
+		captured:         map[*tasks.TaskTreeNode]bool{},
 		treeView: &treeProgressView{
 			opts:    opts,
 			spinner: map[TaskStatus]spinner.Model{},
@@ -88,12 +103,13 @@ func newInteractiveView(forest tasks.Collection, provider *SchedulerStatusProvid
 
 	m.treeView.status = m.status
 	m.treeView.aggregatedStatus = m.aggregatedStatus
+	m.treeView.restarts = m.restarts
 
 	m.input.Placeholder = "Enter a task key"
 	m.input.Cursor.Style = interactiveActiveTabStyle
 	m.input.Width = 30
 	m.input.ShowSuggestions = true
-	m.input.SetSuggestions([]string{"test", "test2", "test3"})
+	provider.OnTasksAdded(m.addTasks)
 
 	execStart := time.Now()
 	p := tea.NewProgram(&m, tea.WithAltScreen(), tea.WithMouseCellMotion())
@@ -108,6 +124,7 @@ func newInteractiveView(forest tasks.Collection, provider *SchedulerStatusProvid
 		for nodeId, err := range failedError.Errors {
 			fmt.Printf("%s %s failed: %s\n", errorIcon, nodeId, err)
 		}
+		printUnfinishedNodes(failedError)
 		fmt.Printf("%s %s %s failed after %s\n", zwoocBranding, errorIcon, forest.GetName(), execEnd.Sub(execStart))
 		os.Exit(1)
 	} else if m.wasCancelCanceled || errors.Is(m.err, tasks.ErrCancelled) {
@@ -128,24 +145,7 @@ func (m *interactiveView) Init() tea.Cmd {
 
 func (m *interactiveView) setupDefaultStatus() {
 	for _, tree := range m.tasks {
-		tree.Iterate(func(node *tasks.TaskTreeNode) {
-			// set default status
-			m.status[node.NodeID()] = StatusPending
-			m.aggregatedStatus[node.NodeID()] = StatusPending
-			// capture the output of each task
-			cap := tasks.NewCapturer()
-			m.outputs[node.NodeID()] = cap
-			node.Main.Pipe(cap)
-		})
-
-		writer := tasks.NewNotifyWriter()
-		tree.Main.Pipe(writer)
-		m.tabs = append(m.tabs, interactiveTab{
-			name:     tree.Name,
-			writer:   writer,
-			showLogs: false,
-			task:     tree,
-		})
+		m.setupTab(tree)
 	}
 
 	if len(m.tabs) > 0 {
@@ -153,7 +153,91 @@ func (m *interactiveView) setupDefaultStatus() {
 	}
 }
 
+// setupTab sets up the status and output capturing of a task tree and returns the index of its tab.
+// The tab of a previous run of the same tree is replaced.
+func (m *interactiveView) setupTab(tree *tasks.TaskTreeNode) int {
+	tree.Iterate(func(node *tasks.TaskTreeNode) {
+		if m.captured[node] {
+			// a shared node of another tree
+			return
+		}
+		m.captured[node] = true
+		// set default status
+		m.status[node.NodeID()] = StatusPending
+		m.aggregatedStatus[node.NodeID()] = StatusPending
+		// capture the output of each task
+		cap := tasks.NewCapturer()
+		m.outputs[node.NodeID()] = cap
+		node.Main.Pipe(cap)
+	})
+
+	writer := tasks.NewNotifyWriter()
+	tree.Main.Pipe(writer)
+	tab := interactiveTab{
+		name:     tree.Name,
+		writer:   writer,
+		showLogs: false,
+		task:     tree,
+	}
+
+	for i := range m.tabs {
+		if m.tabs[i].task.NodeID() == tree.NodeID() {
+			m.tabs[i] = tab
+			return i
+		}
+	}
+	m.tabs = append(m.tabs, tab)
+	return len(m.tabs) - 1
+}
+
+// addTasks adds a tab for each scheduled task tree and activates the first one.
+func (m *interactiveView) addTasks(forest tasks.Collection) {
+	for i, tree := range forest {
+		idx := m.setupTab(tree)
+		if i == 0 {
+			m.activeIndex = idx
+		}
+	}
+	m.resizeTerminals()
+}
+
+// openAddTask opens the add task view with the tasks that can be scheduled as suggestions.
+func (m *interactiveView) openAddTask() tea.Cmd {
+	m.activeView = viewAddTask
+	m.message = ""
+	m.input.Reset()
+	m.input.SetSuggestions(m.provider.Suggestions())
+	return m.input.Focus()
+}
+
+func (m *interactiveView) closeAddTask() {
+	m.message = ""
+	m.input.Blur()
+	m.activeView = viewDefault
+	m.setLogsViewDefaultPosition()
+}
+
+// scheduleInput schedules the task entered in the add task view.
+func (m *interactiveView) scheduleInput() tea.Cmd {
+	command := strings.TrimSpace(m.input.Value())
+	if command == "" {
+		return nil
+	}
+
+	if err := m.provider.Schedule(command); err != nil {
+		m.message = err.Error()
+		return nil
+	}
+
+	m.message = ""
+	m.closeAddTask()
+	m.logsView.GotoBottom()
+	return tea.Batch(m.listenToWriterUpdates, m.updateCurrentLogsView)
+}
+
 func (m *interactiveView) start() tea.Msg {
+	// failed tasks can be rerun until the user quits
+	m.provider.KeepOpen()
 	m.provider.Start()
 	return runnerDoneMsg{<-m.provider.done}
 }
@@ -171,10 +255,114 @@ func (m *interactiveView) handleCancel() {
 	}
 }
 
+// selectedNode returns the selected node of the active tab or nil if there is no active tab.
+func (m *interactiveView) selectedNode() *tasks.TaskTreeNode {
+	if m.activeIndex < 0 || m.activeIndex >= len(m.tabs) {
+		return nil
+	}
+	if m.tabs[m.activeIndex].selected != nil {
+		return m.tabs[m.activeIndex].selected
+	}
+	return m.tabs[m.activeIndex].task
+}
+
+// selectNode moves the selection of the active tab by the offset through the nodes of its tree.
+func (m *interactiveView) selectNode(offset int) {
+	selected := m.selectedNode()
+	if selected == nil {
+		return
+	}
+
+	nodes := selectableNodes(m.tabs[m.activeIndex].task)
+	idx := slices.Index(nodes, selected)
+	m.tabs[m.activeIndex].selected = nodes[(idx+offset+len(nodes))%len(nodes)]
+	m.message = ""
+}
+
+// selectableNodes returns all nodes of the tree in the order they are displayed.
+func selectableNodes(tree *tasks.TaskTreeNode) []*tasks.TaskTreeNode {
+	nodes := []*tasks.TaskTreeNode{}
+	var collect func(node *tasks.TaskTreeNode)
+	collect = func(node *tasks.TaskTreeNode) {
+		if slices.Contains(nodes, node) {
+			// shared nodes are displayed at each of their parents, but selected once
+			return
+		}
+		nodes = append(nodes, node)
+		for _, child := range helper.Concat(node.Pre, node.Post, node.OnError, node.Finally) {
+			collect(child)
+		}
+	}
+	collect(tree)
+	return nodes
+}
+
+// controlActiveTask applies the action to the main task of the selected node of the active tab.
+func (m *interactiveView) controlActiveTask(action func(id string) error) {
+	selected := m.selectedNode()
+	if selected == nil {
+		return
+	}
+
+	id := selected.NodeID()
+	if err := action(id); err != nil {
+		m.message = fmt.Sprintf("%s: %s", id, err)
+	} else {
+		m.message = ""
+	}
+}
+
+// toggleActiveTask stops the main task of the selected node if it's running or starts it again otherwise.
+func (m *interactiveView) toggleActiveTask() {
+	selected := m.selectedNode()
+	if selected == nil {
+		return
+	}
+
+	if m.status[selected.NodeID()] == StatusRunning {
+		m.controlActiveTask(m.provider.StopTask)
+	} else {
+		m.controlActiveTask(m.provider.StartTask)
+	}
+}
+
+// setInputMode enables or disables forwarding key presses to the active task.
+func (m *interactiveView) setInputMode(enabled bool) {
+	m.inputMode = enabled && m.activeIndex >= 0 && m.activeIndex < len(m.tabs)
+	m.inputLine = ""
+	m.message = ""
+}
+
+// forwardInput writes the key press to the stdin of the main task of the active tab.
+func (m *interactiveView) forwardInput(msg tea.KeyMsg) {
+	data := keyToInput(msg)
+	if data == nil || m.activeIndex < 0 || m.activeIndex >= len(m.tabs) {
+		return
+	}
+
+	id := m.tabs[m.activeIndex].task.NodeID()
+	if err := m.provider.WriteInput(id, data); err != nil {
+		m.message = fmt.Sprintf("%s: %s", id, err)
+		return
+	}
+
+	m.message = ""
+	switch msg.Type {
+	case tea.KeyEnter:
+		m.inputLine = ""
+	case tea.KeyBackspace:
+		if runes := []rune(m.inputLine); len(runes) > 0 {
+			m.inputLine = string(runes[:len(runes)-1])
+		}
+	case tea.KeyRunes, tea.KeySpace:
+		m.inputLine += string(data)
+	}
+}
+
 func (m *interactiveView) determineClickedTab(x int) int {
 	var current = 0
 	for i, task := range m.tabs {
-		tabWidth := len(task.name) + 2
+		tabWidth := lipgloss.Width(m.tabName(task)) + 2
 		if x > current && x < current+tabWidth+1 {
 			return i
 		}
@@ -192,6 +380,37 @@ func (m *interactiveView) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 
 	switch msg := msg.(type) {
 	case tea.KeyMsg:
+		if m.activeView == viewAddTask {
+			// all other keys are handled by the input
+			switch msg.String() {
+			case "ctrl+c":
+				m.handleCancel()
+				return m, m.updateCurrentLogsView
+			case "esc":
+				m.closeAddTask()
+				return m, nil
+			case "enter":
+				return m, m.scheduleInput()
+			}
+			m.input, cmd = m.input.Update(msg)
+			return m, cmd
+		}
+
+		if m.inputMode {
+			// all other keys are forwarded to the active task
+			switch msg.String() {
+			case "ctrl+c":
+				m.setInputMode(false)
+				m.handleCancel()
+				return m, m.updateCurrentLogsView
+			case "esc":
+				m.setInputMode(false)
+				return m, nil
+			}
+			m.forwardInput(msg)
+			return m, nil
+		}
+
 		switch msg.String() {
 		case "ctrl+c", "q":
 			m.handleCancel()
@@ -212,13 +431,24 @@ func (m *interactiveView) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 				m.setLogsViewFullScreenPosition()
 			}
 		case "a":
-			if m.activeView == viewAddTask {
-				m.activeView = viewDefault
-				m.setLogsViewDefaultPosition()
-			} else {
-				m.activeView = viewAddTask
-				m.input.Focus()
-				cmds = append(cmds, textinput.Blink)
+			if !m.wasCanceled {
+				cmds = append(cmds, m.openAddTask(), textinput.Blink)
+			}
+		case "r":
+			m.controlActiveTask(m.provider.RestartTask)
+		case "s":
+			m.toggleActiveTask()
+		case "R":
+			m.controlActiveTask(m.provider.RerunTask)
+		case "n":
+			m.selectNode(1)
+			cmds = append(cmds, m.updateCurrentLogsView)
+		case "p":
+			m.selectNode(-1)
+			cmds = append(cmds, m.updateCurrentLogsView)
+		case "i":
+			if !m.wasCanceled {
+				m.setInputMode(true)
 			}
 		case "esc":
 			m.activeView = viewDefault
@@ -249,7 +479,7 @@ func (m *interactiveView) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 				return m.aggregatedStatus[node.NodeID()]
 			})
 			m.tabs[i].showLogs = helper.All(preNodes, func(status TaskStatus) bool {
-				return status == StatusDone
+				return status == StatusDone || status == StatusCached
 			}) && !m.wasCanceled
 		}
 
@@ -271,7 +501,8 @@ func (m *interactiveView) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	case tea.MouseMsg:
 		if msg.Action == tea.MouseActionPress && msg.Button == tea.MouseButtonLeft && msg.Y > 0 && msg.Y < 4 && m.activeView == viewDefault {
 			clickedIdx := m.determineClickedTab(msg.X)
-			if clickedIdx >= 0 {
+			if clickedIdx >= 0 && clickedIdx != m.activeIndex {
+				m.setInputMode(false)
 				m.activeIndex = clickedIdx
 				m.logsView.GotoBottom()
 				cmds = append(cmds, m.listenToWriterUpdates, m.updateCurrentLogsView)
@@ -309,8 +540,10 @@ func (m *interactiveView) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	// Handle keyboard and mouse events in the viewport
 	m.logsView, cmd = m.logsView.Update(msg)
 	cmds = append(cmds, cmd)
-	m.input, cmd = m.input.Update(msg)
-	cmds = append(cmds, cmd)
+	if m.activeView == viewAddTask {
+		m.input, cmd = m.input.Update(msg)
+		cmds = append(cmds, cmd)
+	}
 
 	return m, tea.Batch(cmds...)
 }
@@ -322,6 +555,7 @@ func (m *interactiveView) listenToUpdates() tea.Msg {
 func (m *interactiveView) updateProgress(update taskUpdateMsg) {
 	m.status[update.NodeID] = update.Status
 	m.aggregatedStatus[update.NodeID] = update.AggregatedStatus
+	m.restarts[update.NodeID] = update.Restarts
 	if update.Parent != nil {
 		m.updateProgress(taskUpdateMsg(*update.Parent))
 	}
@@ -351,6 +585,7 @@ func (m *interactiveView) updateCurrentLogsView() tea.Msg {
 		}
 	}
 
+	m.treeView.selected = m.selectedNode()
 	return contentUpdateMsg{
 		tabId:   m.activeIndex,
 		content: m.treeView.printNode(m.tabs[m.activeIndex].task, "", true),
@@ -360,11 +595,22 @@ func (m *interactiveView) updateCurrentLogsView() tea.Msg {
 func (m *interactiveView) setLogsViewDefaultPosition() {
 	m.logsView.Width = m.windowWidth
 	m.logsView.Height = m.windowHeight - 5
+	m.resizeTerminals()
 }
 
 func (m *interactiveView) setLogsViewFullScreenPosition() {
 	m.logsView.Width = m.windowWidth
 	m.logsView.Height = m.windowHeight - 1
+	m.resizeTerminals()
+}
+
+// resizeTerminals sets the size of the pseudo-terminals of all tasks to the size of the logs view.
+func (m *interactiveView) resizeTerminals() {
+	for _, tab := range m.tabs {
+		tab.task.Iterate(func(node *tasks.TaskTreeNode) {
+			tasks.ResizeTerminal(node.Main, m.logsView.Width, m.logsView.Height)
+		})
+	}
 }
 
 func (m *interactiveView) View() (s string) {
@@ -389,6 +635,8 @@ func (m *interactiveView) View() (s string) {
 		header += cancelIcon + " shutting down..."
 	} else if m.wasCancelCanceled {
 		header += cancelIcon + " canceling..."
+	} else if m.hasFailed() {
+		header += errorIcon + " failed - press R to rerun or q to quit"
 	} else {
 		header += successIcon + " running..."
 	}
@@ -402,7 +650,18 @@ func (m *interactiveView) View() (s string) {
 		s += m.logsView.View() + "\n"
 	}
 	help := interactiveKeyStyle.Render("h") + interactiveHelpStyle.Render(" • show help")
-	s += fmt.Sprintf("╾%s┤ %s", helper.Repeat("─", m.logsView.Width-lipgloss.Width(help)-3), help)
+	if m.inputMode {
+		help = interactiveKeyStyle.Render("esc") + interactiveHelpStyle.Render(" • leave input mode")
+	}
+	message := ""
+	if m.message != "" {
+		message = "─ " + errorStyle.Render(m.message) + " "
+	} else if m.inputMode {
+		message = "─ " + interactiveKeyStyle.Render("input ▸") + " " + m.inputLine + " "
+	} else if selected := m.selectedNode(); selected != nil && selected != m.tabs[m.activeIndex].task {
+		message = "─ " + interactiveKeyStyle.Render("selected ▸") + " " + selected.Name + " "
+	}
+	s += fmt.Sprintf("╾%s%s┤ %s", message, helper.Repeat("─", m.logsView.Width-lipgloss.Width(help)-lipgloss.Width(message)-3), help)
 	return
 }
 
@@ -416,6 +675,12 @@ func (m *interactiveView) ViewHelp() (s string) {
 	s += align.Render(interactiveKeyStyle.Render("esc")) + interactiveHelpStyle.Render(" close the alt (help) screen") + "\n\n"
 	s += align.Render(interactiveKeyStyle.Render("tab")) + interactiveHelpStyle.Render(" switch to next tab") + "\n\n"
 	s += align.Render(interactiveKeyStyle.Render("shift+tab")) + interactiveHelpStyle.Render(" switch to previous tab") + "\n\n"
+	s += align.Render(interactiveKeyStyle.Render("n/p")) + interactiveHelpStyle.Render(" select the next/previous task of the active tab") + "\n\n"
+	s += align.Render(interactiveKeyStyle.Render("r")) + interactiveHelpStyle.Render(" restart the selected task") + "\n\n"
+	s += align.Render(interactiveKeyStyle.Render("s")) + interactiveHelpStyle.Render(" stop or start the selected task") + "\n\n"
+	s += align.Render(interactiveKeyStyle.Render("R")) + interactiveHelpStyle.Render(" rerun the failed tasks of the tree of the selected task") + "\n\n"
+	s += align.Render(interactiveKeyStyle.Render("i")) + interactiveHelpStyle.Render(" forward key presses to the task of the active tab (esc to leave)") + "\n\n"
+	s += align.Render(interactiveKeyStyle.Render("a")) + interactiveHelpStyle.Render(" add a task (run/watch/build <profile>, exec <fragment>, launch <compound>)") + "\n\n"
 	return
 }
 
@@ -442,6 +707,9 @@ func (m *interactiveView) ViewAddTask() (s string) {
 	truncatedContent := lipgloss.NewStyle().MaxWidth(m.windowWidth - 2).Render(m.input.View())
 	s += border.Render(truncatedContent)
 	s += "\n"
+	if m.message != "" {
+		s += "  " + errorStyle.Render(m.message) + "\n"
+	}
 
 	suggestions := m.input.MatchedSuggestions()
 	if len(suggestions) == 0 {
@@ -465,9 +733,9 @@ func (m *interactiveView) RenderTabs() string {
 	for i, task := range m.tabs {
 		var currentName string
 		if i == m.activeIndex {
-			currentName = interactiveActiveTabStyle.Render(task.name)
+			currentName = interactiveActiveTabStyle.Render(m.tabName(task))
 		} else {
-			currentName = interactiveTabStyle.Render(task.name)
+			currentName = interactiveTabStyle.Render(m.tabName(task))
 		}
 		tabs += currentName + " │ "
 		tabsBorder += helper.Repeat("─", lipgloss.Width(currentName)) + "─┴─"
@@ -490,3 +758,30 @@ func (m *interactiveView) RenderTabs() string {
 
 	return tabsTop + "\n" + tabs + "\n" + tabsBorder + "\n"
 }
+
+// hasFailed reports whether all tabs finished and at least one of them failed.
+func (m *interactiveView) hasFailed() bool {
+	hasFailed := false
+	for _, tab := range m.tabs {
+		switch m.aggregatedStatus[tab.task.NodeID()] {
+		case StatusPending, StatusScheduled, StatusRunning:
+			return false
+		case StatusError:
+			hasFailed = true
+		}
+	}
+	return hasFailed
+}
+
+// tabName returns the name of the tab including the restart count of its main task
+// and an indicator whether key presses are forwarded to it.
+func (m *interactiveView) tabName(tab interactiveTab) string {
+	name := tab.name
+	if restarts := m.restarts[tab.task.NodeID()]; restarts > 0 {
+		name = fmt.Sprintf("%s ↻ %d", name, restarts)
+	}
+	if m.inputMode && m.activeIndex >= 0 && m.activeIndex < len(m.tabs) && m.tabs[m.activeIndex].task == tab.task {
+		name += " ⌨"
+	}
+	return name
+}
@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"os"
 	"os/signal"
+	"slices"
 	"strings"
 	"sync"
 	"time"
@@ -21,12 +22,15 @@ type treeProgressView struct {
 	outputs          map[string]*tasks.CommandCapturer
 	status           map[string]TaskStatus
 	aggregatedStatus map[string]TaskStatus
+	restarts         map[string]int
 	provider         *SimpleStatusProvider
 	mu               sync.RWMutex
 	wasCanceled      bool
 	err              error
 	clear            bool
 	spinner          map[TaskStatus]spinner.Model
+	// selected is the node highlighted by the interactive view
+	selected *tasks.TaskTreeNode
 }
 
 type treeProgressUpdateMsg StatusUpdate
@@ -39,6 +43,7 @@ func newTreeProgressView(forest tasks.Collection, status *SimpleStatusProvider,
 		provider:         status,
 		status:           map[string]TaskStatus{},
 		aggregatedStatus: map[string]TaskStatus{},
+		restarts:         map[string]int{},
 		outputs:          map[string]*tasks.CommandCapturer{},
 		spinner:          map[TaskStatus]spinner.Model{},
 	}
@@ -65,6 +70,7 @@ func newTreeProgressView(forest tasks.Collection, status *SimpleStatusProvider,
 			fmt.Println(strings.TrimSpace(model.outputs[nodeId].String()))
 			fmt.Printf(parts[1])
 		}
+		printUnfinishedNodes(failedError)
 		fmt.Printf("%s %s %s failed after %s\n", zwoocBranding, errorIcon, forest.GetName(), execEnd.Sub(execStart))
 		os.Exit(1)
 	} else if model.wasCanceled || errors.Is(model.err, tasks.ErrCancelled) {
@@ -117,6 +123,7 @@ func (m *treeProgressView) Update(message tea.Msg) (tea.Model, tea.Cmd) {
 func (m *treeProgressView) updateProgress(update treeProgressUpdateMsg) {
 	m.status[update.NodeID] = update.Status
 	m.aggregatedStatus[update.NodeID] = update.AggregatedStatus
+	m.restarts[update.NodeID] = update.Restarts
 	if update.Parent != nil {
 		m.updateProgress(treeProgressUpdateMsg(*update.Parent))
 	}
@@ -152,6 +159,10 @@ func (m *treeProgressView) View() (s string) {
 func (m *treeProgressView) setupDefaultStatus() {
 	for _, tree := range m.tasks {
 		tree.Iterate(func(node *tasks.TaskTreeNode) {
+			if _, ok := m.outputs[node.NodeID()]; ok {
+				// shared nodes are part of several trees, but their output is captured once
+				return
+			}
 			// set default status
 			m.status[node.NodeID()] = StatusPending
 			m.aggregatedStatus[node.NodeID()] = StatusPending
@@ -203,6 +214,14 @@ func (m *treeProgressView) setupSpinners() tea.Cmd {
 		Frames: []string{"- "},
 		FPS:    1,
 	}), spinner.WithStyle(treeCanceledStyle))
+	m.spinner[StatusSkipped] = spinner.New(spinner.WithSpinner(spinner.Spinner{
+		Frames: []string{"» "},
+		FPS:    1,
+	}), spinner.WithStyle(treeSkippedStyle))
+	m.spinner[StatusCached] = spinner.New(spinner.WithSpinner(spinner.Spinner{
+		Frames: []string{"≡ "},
+		FPS:    1,
+	}), spinner.WithStyle(treeCachedStyle))
 
 	return tea.Batch(scheduledSpinner.Tick, runningSpinner.Tick, pendingSpinner.Tick)
 }
@@ -216,10 +235,17 @@ func (m *treeProgressView) printNode(node *tasks.TaskTreeNode, prefix string, is
 	}
 
 	nodeStatus := m.spinner[status].View()
+	if node.IsLeaf() {
+		nodeStatus += m.printRestarts(node)
+	}
+	name := node.Name
+	if node == m.selected {
+		name = interactiveActiveTabStyle.Render("▸ " + name)
+	}
 	if isLast {
-		s += fmt.Sprintf("%s└%s%s %s\n", prefix, connector, node.Name, nodeStatus)
+		s += fmt.Sprintf("%s└%s%s %s\n", prefix, connector, name, nodeStatus)
 	} else {
-		s += fmt.Sprintf("%s├%s%s %s\n", prefix, connector, node.Name, nodeStatus)
+		s += fmt.Sprintf("%s├%s%s %s\n", prefix, connector, name, nodeStatus)
 	}
 
 	if node.IsLeaf() {
@@ -238,19 +264,41 @@ func (m *treeProgressView) printNode(node *tasks.TaskTreeNode, prefix string, is
 		}
 	}
 
-	mainStatus := m.spinner[m.status[node.NodeID()]].View()
-	if len(node.Post) > 0 {
+	sections := []graphSection{
+		{model.KeyPost, node.Post},
+		{model.KeyOnError, node.OnError},
+		{model.KeyFinally, node.Finally},
+	}
+	sections = slices.DeleteFunc(sections, func(section graphSection) bool {
+		return len(section.nodes) == 0
+	})
+
+	mainStatus := m.spinner[m.status[node.NodeID()]].View() + m.printRestarts(node)
+	if len(sections) > 0 {
 		s += fmt.Sprintf("%s%s├─%s %s\n", prefix, descendantPrefix, node.Main.Name(), mainStatus)
 	} else {
 		s += fmt.Sprintf("%s%s└─%s %s\n", prefix, descendantPrefix, node.Main.Name(), mainStatus)
 	}
 
-	if len(node.Post) > 0 {
-		s += fmt.Sprintf("%s%s└┬%s\n", prefix, descendantPrefix, model.KeyPost)
-		for i, child := range node.Post {
-			s += m.printNode(child, prefix+descendantPrefix+" ", i == len(node.Post)-1)
+	for i, section := range sections {
+		connector := "├┬"
+		childPrefix := "│"
+		if i == len(sections)-1 {
+			connector = "└┬"
+			childPrefix = " "
+		}
+		s += fmt.Sprintf("%s%s%s%s\n", prefix, descendantPrefix, connector, section.name)
+		for j, child := range section.nodes {
+			s += m.printNode(child, prefix+descendantPrefix+childPrefix, j == len(section.nodes)-1)
 		}
 	}
 
 	return
 }
+
+func (m *treeProgressView) printRestarts(node *tasks.TaskTreeNode) string {
+	if restarts := m.restarts[node.NodeID()]; restarts > 0 {
+		return " " + restartStyle.Render(fmt.Sprintf("↻ %d", restarts))
+	}
+	return ""
+}
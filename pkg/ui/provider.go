@@ -5,6 +5,7 @@ type StatusUpdate struct {
 	Status           TaskStatus
 	AggregatedStatus TaskStatus
 	Error            error
+	Restarts         int
 	Parent           *StatusUpdate
 }
 
@@ -34,6 +34,7 @@ func newQuiteTreeView(forest tasks.Collection, provider *SimpleStatusProvider) {
 		for nodeId, err := range failedError.Errors {
 			fmt.Printf(" %s %s failed: %s\n", errorIcon, nodeId, err)
 		}
+		printUnfinishedNodes(failedError)
 		fmt.Printf("%s %s %s failed after %s\n", zwoocBranding, errorIcon, forest.GetName(), execEnd.Sub(execStart))
 		os.Exit(1)
 	} else if errors.Is(err, tasks.ErrCancelled) {
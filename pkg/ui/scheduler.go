@@ -1,19 +1,42 @@
 package ui
 
+import "github.com/zwoo-hq/zwooc/pkg/tasks"
+
 type Scheduler interface {
-	Schedule(command string, id string)
-	OnSchedule(handler func(command string, id string))
+	Schedule(command string) error
+	OnSchedule(handler func(command string) error)
+	Suggestions() []string
+	OnSuggestions(handler func() []string)
+	TasksAdded(nodes tasks.Collection)
+	OnTasksAdded(handler func(nodes tasks.Collection))
 	Shutdown()
 	OnShutdown(handler func())
-	// TODO: implement stop/restart etc of individual tasks
-	// TODO: needs notifier for:
-	// - new task started (when scheduled)
+	KeepOpen()
+	OnKeepOpen(handler func())
+	RestartTask(id string) error
+	OnRestartTask(handler func(id string) error)
+	StopTask(id string) error
+	OnStopTask(handler func(id string) error)
+	StartTask(id string) error
+	OnStartTask(handler func(id string) error)
+	RerunTask(id string) error
+	OnRerunTask(handler func(id string) error)
+	WriteInput(id string, data []byte) error
+	OnWriteInput(handler func(id string, data []byte) error)
 }
 
 type SchedulerStatusProvider struct {
 	*SimpleStatusProvider
-	schedule func(command string, id string)
-	shutdown func()
+	schedule    func(command string) error
+	suggestions func() []string
+	tasksAdded  func(nodes tasks.Collection)
+	shutdown    func()
+	keepOpen    func()
+	restartTask func(id string) error
+	stopTask    func(id string) error
+	startTask   func(id string) error
+	rerunTask   func(id string) error
+	writeInput  func(id string, data []byte) error
 }
 
 var _ StatusProvider = &SchedulerStatusProvider{}
@@ -26,14 +49,35 @@ func NewSchedulerStatusProvider() *SchedulerStatusProvider {
 	}
 }
 
-func (g SchedulerStatusProvider) Schedule(command string, id string) {
-	g.schedule(command, id)
+func (g SchedulerStatusProvider) Schedule(command string) error {
+	return g.schedule(command)
 }
 
-func (g *SchedulerStatusProvider) OnSchedule(handler func(command string, id string)) {
+func (g *SchedulerStatusProvider) OnSchedule(handler func(command string) error) {
 	g.schedule = handler
 }
 
+func (g SchedulerStatusProvider) Suggestions() []string {
+	if g.suggestions == nil {
+		return []string{}
+	}
+	return g.suggestions()
+}
+
+func (g *SchedulerStatusProvider) OnSuggestions(handler func() []string) {
+	g.suggestions = handler
+}
+
+func (g SchedulerStatusProvider) TasksAdded(nodes tasks.Collection) {
+	if g.tasksAdded != nil {
+		g.tasksAdded(nodes)
+	}
+}
+
+func (g *SchedulerStatusProvider) OnTasksAdded(handler func(nodes tasks.Collection)) {
+	g.tasksAdded = handler
+}
+
 func (g SchedulerStatusProvider) Shutdown() {
 	g.shutdown()
 }
@@ -41,3 +85,54 @@ func (g SchedulerStatusProvider) Shutdown() {
 func (g *SchedulerStatusProvider) OnShutdown(handler func()) {
 	g.shutdown = handler
 }
+
+// KeepOpen keeps the session open after tasks failed, so that they can be rerun, until it's shut down or canceled.
+func (g SchedulerStatusProvider) KeepOpen() {
+	if g.keepOpen != nil {
+		g.keepOpen()
+	}
+}
+
+func (g *SchedulerStatusProvider) OnKeepOpen(handler func()) {
+	g.keepOpen = handler
+}
+
+func (g SchedulerStatusProvider) RestartTask(id string) error {
+	return g.restartTask(id)
+}
+
+func (g *SchedulerStatusProvider) OnRestartTask(handler func(id string) error) {
+	g.restartTask = handler
+}
+
+func (g SchedulerStatusProvider) StopTask(id string) error {
+	return g.stopTask(id)
+}
+
+func (g *SchedulerStatusProvider) OnStopTask(handler func(id string) error) {
+	g.stopTask = handler
+}
+
+func (g SchedulerStatusProvider) StartTask(id string) error {
+	return g.startTask(id)
+}
+
+func (g *SchedulerStatusProvider) OnStartTask(handler func(id string) error) {
+	g.startTask = handler
+}
+
+func (g SchedulerStatusProvider) RerunTask(id string) error {
+	return g.rerunTask(id)
+}
+
+func (g *SchedulerStatusProvider) OnRerunTask(handler func(id string) error) {
+	g.rerunTask = handler
+}
+
+func (g SchedulerStatusProvider) WriteInput(id string, data []byte) error {
+	return g.writeInput(id, data)
+}
+
+func (g *SchedulerStatusProvider) OnWriteInput(handler func(id string, data []byte) error) {
+	g.writeInput = handler
+}
@@ -35,6 +35,10 @@ func newStaticTreeView(forest tasks.Collection, provider *SimpleStatusProvider,
 	// setup task pipes
 	for _, tree := range forest {
 		tree.Iterate(func(t *tasks.TaskTreeNode) {
+			if _, ok := outputs[t.NodeID()]; ok {
+				// shared nodes are part of several trees, but their output is captured once
+				return
+			}
 			cap := tasks.NewCapturer()
 			outputs[t.NodeID()] = cap
 			t.Main.Pipe(cap)
@@ -71,6 +75,7 @@ func newStaticTreeView(forest tasks.Collection, provider *SimpleStatusProvider,
 			fmt.Println(strings.TrimSpace(outputs[nodeId].String()))
 			fmt.Printf(parts[1])
 		}
+		printUnfinishedNodes(failedError)
 		fmt.Printf("%s %s %s failed after %s\n", zwoocBranding, errorIcon, forest.GetName(), execEnd.Sub(execStart))
 		os.Exit(1)
 	} else if model.wasCanceled || errors.Is(model.err, tasks.ErrCancelled) {
@@ -83,16 +88,24 @@ func newStaticTreeView(forest tasks.Collection, provider *SimpleStatusProvider,
 func (m *staticTreeView) ReceiveUpdates(c <-chan StatusUpdate, prefix string) {
 	for node := range c {
 		switch node.Status {
-		case StatusPending:
+		case StatusPending, StatusScheduled:
 			fmt.Printf("%s %s %s\n", prefix, node.NodeID, pendingStyle.Render("was scheduled"))
 		case StatusRunning:
-			fmt.Printf("%s %s %s\n", prefix, node.NodeID, runningStyle.Render("started running"))
+			if node.Restarts > 0 {
+				fmt.Printf("%s %s %s\n", prefix, node.NodeID, restartStyle.Render(fmt.Sprintf("restarted (%d)", node.Restarts)))
+			} else {
+				fmt.Printf("%s %s %s\n", prefix, node.NodeID, runningStyle.Render("started running"))
+			}
 		case StatusDone:
 			fmt.Printf("%s %s %s\n", prefix, node.NodeID, successStyle.Render("finished"))
 		case StatusError:
 			fmt.Printf("%s %s %s\n", prefix, node.NodeID, errorStyle.Render("failed"))
 		case StatusCanceled:
 			fmt.Printf("%s %s %s\n", prefix, node.NodeID, canceledStyle.Render("was canceled"))
+		case StatusSkipped:
+			fmt.Printf("%s %s %s\n", prefix, node.NodeID, skippedStyle.Render("was skipped"))
+		case StatusCached:
+			fmt.Printf("%s %s %s\n", prefix, node.NodeID, cachedStyle.Render("is up to date"))
 		}
 	}
 	m.wg.Done()
@@ -15,4 +15,8 @@ const (
 	StatusError
 	// StatusCanceled indicates that the task has been canceled.
 	StatusCanceled
+	// StatusSkipped indicates that the task was skipped because one of its dependencies failed.
+	StatusSkipped
+	// StatusCached indicates that the task was not executed since it's up to date.
+	StatusCached
 )
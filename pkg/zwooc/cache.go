@@ -0,0 +1,100 @@
+package zwooc
+
+import (
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/urfave/cli/v2"
+	"github.com/zwoo-hq/zwooc/pkg/config"
+	"github.com/zwoo-hq/zwooc/pkg/helper"
+	"github.com/zwoo-hq/zwooc/pkg/model"
+	"github.com/zwoo-hq/zwooc/pkg/tasks"
+	"github.com/zwoo-hq/zwooc/pkg/ui"
+)
+
+func CreateCacheCommand() *cli.Command {
+	return &cli.Command{
+		Name:  "cache",
+		Usage: "manage the local artifact cache",
+		Subcommands: []*cli.Command{
+			{
+				Name:  "stats",
+				Usage: "show the location, size and amount of entries of the cache",
+				Action: func(c *cli.Context) error {
+					conf := loadConfig(c)
+					cache := getArtifactCache(conf)
+					stats, err := cache.Stats()
+					if err != nil {
+						ui.HandleError(err)
+					}
+
+					fmt.Printf("directory: %s\n", cache.Dir)
+					fmt.Printf("entries:   %d\n", stats.Entries)
+					fmt.Printf("size:      %s\n", helper.FormatSize(stats.Size))
+					if cache.MaxSize > 0 {
+						fmt.Printf("limit:     %s\n", helper.FormatSize(cache.MaxSize))
+					} else {
+						fmt.Println("limit:     none")
+					}
+					if stats.Entries > 0 {
+						fmt.Printf("oldest:    %s\n", stats.Oldest.Format(time.DateTime))
+						fmt.Printf("newest:    %s\n", stats.Newest.Format(time.DateTime))
+					}
+					return nil
+				},
+			},
+			{
+				Name:  "prune",
+				Usage: "remove the least recently used entries until the cache fits into its size limit",
+				Flags: []cli.Flag{
+					&cli.StringFlag{
+						Name:  "max-size",
+						Usage: "prune to the given size (e.g. 500MB) instead of the configured limit",
+					},
+				},
+				Action: func(c *cli.Context) error {
+					conf := loadConfig(c)
+					cache := getArtifactCache(conf)
+					if c.IsSet("max-size") {
+						size, err := helper.ParseSize(c.String("max-size"))
+						if err != nil {
+							ui.HandleError(err)
+						}
+						cache.MaxSize = size
+					}
+
+					removed, freed, err := cache.Prune()
+					if err != nil {
+						ui.HandleError(err)
+					}
+					ui.PrintSuccess(fmt.Sprintf("removed %d entries (%s)", removed, helper.FormatSize(freed)))
+					return nil
+				},
+			},
+			{
+				Name:  "clear",
+				Usage: "remove all cached artifacts and up to date records",
+				Action: func(c *cli.Context) error {
+					conf := loadConfig(c)
+					if err := getArtifactCache(conf).Clear(); err != nil {
+						ui.HandleError(err)
+					}
+					if err := os.RemoveAll(conf.RecordsDir()); err != nil {
+						ui.HandleError(err)
+					}
+					ui.PrintSuccess("cleared the cache")
+					return nil
+				},
+			},
+		},
+	}
+}
+
+func getArtifactCache(conf config.Config) tasks.ArtifactCache {
+	cache := conf.ArtifactCache()
+	if cache == nil {
+		ui.HandleError(fmt.Errorf("the artifact cache is disabled (%s.disabled)", model.KeyCache))
+	}
+	return *cache
+}
@@ -9,6 +9,7 @@ import (
 	"github.com/zwoo-hq/zwooc/pkg/config"
 	"github.com/zwoo-hq/zwooc/pkg/helper"
 	"github.com/zwoo-hq/zwooc/pkg/model"
+	"github.com/zwoo-hq/zwooc/pkg/tasks"
 	"github.com/zwoo-hq/zwooc/pkg/ui"
 	legacyui "github.com/zwoo-hq/zwooc/pkg/ui/legacy"
 )
@@ -24,16 +25,29 @@ var (
 	CategoryMisc        = "Miscellaneous:"
 )
 
-func loadConfig() config.Config {
-	path, err := helper.FindFile("zwooc.config.json")
+func findConfig() string {
+	path, err := helper.FindFile(config.FileNames(model.ConfigFile)...)
 	if err != nil {
 		ui.HandleError(err)
 	}
+	return path
+}
 
-	conf, err := config.Load(path)
+func loadConfig(c *cli.Context) config.Config {
+	conf, err := config.Load(findConfig())
 	if err != nil {
 		ui.HandleError(err)
 	}
+
+	environment := c.String("env")
+	if environment == "" {
+		environment = os.Getenv(model.EnvEnvironment)
+	}
+	if environment != "" {
+		if err := conf.UseEnvironment(environment); err != nil {
+			ui.HandleError(err)
+		}
+	}
 	return conf
 }
 
@@ -76,20 +90,47 @@ func completeCompounds(c config.Config) {
 // 	}
 // }
 
-func getLoadOptions(c *cli.Context, extraArgs []string) config.LoadOptions {
+// filterAffected removes all nodes of projects not affected by the changes selected via --affected.
+// zwooc exits if no node is affected.
+func filterAffected(conf config.Config, c *cli.Context, collection tasks.Collection) tasks.Collection {
+	affected, ok := c.Generic("affected").(*affectedFlag)
+	if !ok || !affected.enabled {
+		return collection
+	}
+
+	files, err := conf.ChangedFiles(affected.base)
+	if err != nil {
+		ui.HandleError(err)
+	}
+	filtered := conf.FilterAffected(collection, files)
+	if len(filtered) == 0 {
+		ui.PrintSuccess("no tasks are affected by the changes")
+		os.Exit(0)
+	}
+	return filtered
+}
+
+func getLoadOptions(conf config.Config, c *cli.Context, extraArgs []string) config.LoadOptions {
 	return config.LoadOptions{
 		SkipHooks: c.Bool("skip-hooks"),
-		Exclude:   c.StringSlice("exclude"),
+		Exclude:   append(conf.GetOptions().Exclude, c.StringSlice("exclude")...),
 		ExtraArgs: extraArgs,
+		Tty:       c.Bool("pty"),
+		Isolated:  c.Bool("legacy-runner"),
 	}
 }
 
-func getRunnerOptions(c *cli.Context) config.RunnerOptions {
+func getRunnerOptions(conf config.Config, c *cli.Context) config.RunnerOptions {
 	runnerOptions := config.RunnerOptions{
-		MaxConcurrency:  c.Int("max-concurrency"),
+		MaxConcurrency:  conf.GetOptions().MaxConcurrency,
 		UseLegacyRunner: c.Bool("legacy-runner"),
+		Loose:           c.Bool("loose"),
+		Force:           c.Bool("force"),
 	}
 
+	if c.IsSet("max-concurrency") {
+		runnerOptions.MaxConcurrency = c.Int("max-concurrency")
+	}
 	if c.Bool("serial") {
 		runnerOptions.MaxConcurrency = 1
 	}
@@ -118,16 +159,28 @@ func getViewOptions(c *cli.Context) ui.ViewOptions {
 	return viewOptions
 }
 
-func getLegacyViewOptions(c *cli.Context) legacyui.ViewOptions {
+// ensureLegacySupport exits zwooc if a tree contains nodes that can't be executed by the legacy runner.
+func ensureLegacySupport(forest tasks.Collection) {
+	for _, tree := range forest {
+		if tree.HasConditionalNodes() {
+			ui.HandleError(fmt.Errorf("'%s' contains %s or %s hooks, which are not supported by the legacy runner", tree.Name, model.KeyOnError, model.KeyFinally))
+		}
+	}
+}
+
+func getLegacyViewOptions(conf config.Config, c *cli.Context) legacyui.ViewOptions {
 	viewOptions := legacyui.ViewOptions{
 		DisableTUI:     c.Bool("no-tty"),
 		QuiteMode:      c.Bool("quite"),
 		InlineOutput:   c.Bool("inline-output"),
 		CombineOutput:  c.Bool("combine-output"),
 		DisablePrefix:  c.Bool("no-prefix"),
-		MaxConcurrency: c.Int("max-concurrency"),
+		MaxConcurrency: conf.GetOptions().MaxConcurrency,
 	}
 
+	if c.IsSet("max-concurrency") {
+		viewOptions.MaxConcurrency = c.Int("max-concurrency")
+	}
 	if c.Bool("serial") {
 		viewOptions.MaxConcurrency = 1
 	}
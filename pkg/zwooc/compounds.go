@@ -14,14 +14,14 @@ func CreateCompoundCommand() *cli.Command {
 		ArgsUsage: "[compounds]",
 		Flags:     CreateGlobalFlags(),
 		Action: func(c *cli.Context) error {
-			conf := loadConfig()
+			conf := loadConfig(c)
 			return execCompound(conf, c)
 		},
 		BashComplete: func(c *cli.Context) {
 			if c.NArg() > 0 {
 				return
 			}
-			conf := loadConfig()
+			conf := loadConfig(c)
 			completeCompounds(conf)
 		},
 	}
@@ -32,21 +32,24 @@ func execCompound(conf config.Config, c *cli.Context) error {
 		return graphTaskTree(conf, c, "launch")
 	}
 
-	runnerOptions := getRunnerOptions(c)
-	ctx := config.NewContext(getLoadOptions(c, []string{}))
+	runnerOptions := getRunnerOptions(conf, c)
+	ctx := config.NewContext(getLoadOptions(conf, c, []string{}))
 	compoundKey := c.Args().First()
 	compoundTasks, err := conf.LoadCompound(compoundKey, ctx)
 	if err != nil {
 		ui.HandleError(err)
 	}
+	compoundTasks = filterAffected(conf, c, compoundTasks)
 
 	if runnerOptions.UseLegacyRunner {
-		viewOptions := getLegacyViewOptions(c)
+		ensureLegacySupport(compoundTasks)
+		viewOptions := getLegacyViewOptions(conf, c)
 		legacyui.NewInteractiveRunner(compoundTasks, viewOptions, conf)
 	}
 
 	viewOptions := getViewOptions(c)
 	adapter := newStatusAdapter(compoundTasks, runnerOptions)
+	adapter.enableScheduling(conf, getLoadOptions(conf, c, []string{}))
 	ui.NewInteractiveView(compoundTasks, adapter.scheduler, viewOptions)
 	return nil
 }
@@ -0,0 +1,60 @@
+package zwooc
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/urfave/cli/v2"
+	"github.com/zwoo-hq/zwooc/pkg/helper"
+	"github.com/zwoo-hq/zwooc/pkg/ui"
+)
+
+func CreateConfigCommand() *cli.Command {
+	return &cli.Command{
+		Name:  "config",
+		Usage: "inspect the config",
+		Subcommands: []*cli.Command{
+			{
+				Name:  "show",
+				Usage: "show the config merged from all config files",
+				Flags: []cli.Flag{
+					&cli.BoolFlag{
+						Name:  "origin",
+						Usage: "show the file each value is defined in",
+					},
+				},
+				Action: func(c *cli.Context) error {
+					conf := loadConfig(c)
+					if !c.Bool("origin") {
+						content, err := conf.Encode()
+						if err != nil {
+							ui.HandleError(err)
+						}
+						os.Stdout.Write(content)
+						return nil
+					}
+
+					values := conf.Values()
+					lines := make([][2]string, len(values))
+					pointerWidth, valueWidth := 0, 0
+					for i, value := range values {
+						encoded := bytes.Buffer{}
+						if err := helper.EncodeJsonValue(&encoded, value.Value); err != nil {
+							ui.HandleError(err)
+						}
+						lines[i] = [2]string{value.Pointer, encoded.String()}
+						pointerWidth = max(pointerWidth, len(value.Pointer))
+						valueWidth = max(valueWidth, len(encoded.String()))
+					}
+					for i, value := range values {
+						line := fmt.Sprintf("%-*s  %-*s  %s", pointerWidth, lines[i][0], valueWidth, lines[i][1], value.Origin)
+						fmt.Println(strings.TrimRight(line, " "))
+					}
+					return nil
+				},
+			},
+		},
+	}
+}
@@ -2,6 +2,30 @@ package zwooc
 
 import "github.com/urfave/cli/v2"
 
+// affectedFlag is the value of --affected[=<base-ref>]. It behaves like a bool flag, so that it
+// doesn't consume the following argument, while still accepting an optional base ref.
+type affectedFlag struct {
+	enabled bool
+	base    string
+}
+
+func (f *affectedFlag) Set(value string) error {
+	f.enabled = true
+	if value != "true" {
+		f.base = value
+	}
+	return nil
+}
+
+func (f *affectedFlag) String() string {
+	return f.base
+}
+
+// IsBoolFlag allows passing the flag without a value.
+func (f *affectedFlag) IsBoolFlag() bool {
+	return true
+}
+
 func CreateGlobalFlags() []cli.Flag {
 	return []cli.Flag{
 		// global
@@ -38,10 +62,16 @@ func CreateGlobalFlags() []cli.Flag {
 			Category: CategoryGeneral,
 		},
 		&cli.BoolFlag{
-			// TODO: implement
 			Name:     "loose",
 			Aliases:  []string{"l"},
-			Usage:    "ignores errors in tasks and continues",
+			Usage:    "continue running independent tasks after a task failed",
+			Value:    false,
+			Category: CategoryGeneral,
+		},
+		&cli.BoolFlag{
+			Name:     "force",
+			Aliases:  []string{"f"},
+			Usage:    "run all tasks even if their inputs did not change",
 			Value:    false,
 			Category: CategoryGeneral,
 		},
@@ -52,6 +82,23 @@ func CreateGlobalFlags() []cli.Flag {
 			Value:    false,
 			Category: CategoryGeneral,
 		},
+		&cli.BoolFlag{
+			Name:     "pty",
+			Usage:    "run all tasks attached to a pseudo-terminal (same as tty: true for each task)",
+			Value:    false,
+			Category: CategoryGeneral,
+		},
+		&cli.GenericFlag{
+			Name:     "affected",
+			Usage:    "only run tasks of projects affected by uncommitted changes or by changes since --affected=<base-ref>",
+			Value:    &affectedFlag{},
+			Category: CategoryGeneral,
+		},
+		&cli.StringFlag{
+			Name:     "env",
+			Usage:    "apply the options of an environment defined in $environments (default: $ZWOOC_ENV)",
+			Category: CategoryGeneral,
+		},
 		&cli.StringSliceFlag{
 			Name:     "exclude",
 			Aliases:  []string{"e"},
@@ -15,14 +15,14 @@ func CreateFragmentCommand() *cli.Command {
 		ArgsUsage: "[fragment] [extra arguments...]",
 		Flags:     CreateGlobalFlags(),
 		Action: func(c *cli.Context) error {
-			conf := loadConfig()
+			conf := loadConfig(c)
 			return execFragment(conf, c)
 		},
 		BashComplete: func(c *cli.Context) {
 			if c.NArg() > 0 {
 				return
 			}
-			conf := loadConfig()
+			conf := loadConfig(c)
 			completeFragments(conf)
 		},
 	}
@@ -33,17 +33,20 @@ func execFragment(conf config.Config, c *cli.Context) error {
 		return graphTaskTree(conf, c, "exec")
 	}
 
-	runnerOptions := getRunnerOptions(c)
-	ctx := config.NewContext(getLoadOptions(c, c.Args().Tail()))
+	runnerOptions := getRunnerOptions(conf, c)
+	ctx := config.NewContext(getLoadOptions(conf, c, c.Args().Tail()))
 	fragmentKey := c.Args().First()
 	task, err := conf.LoadFragment(fragmentKey, ctx)
 	if err != nil {
 		ui.HandleError(err)
 	}
+	// a single fragment is either affected or zwooc exits
+	filterAffected(conf, c, tasks.NewCollection(task))
 	task.RemoveEmptyNodes()
 
 	if runnerOptions.UseLegacyRunner {
-		viewOptions := getLegacyViewOptions(c)
+		ensureLegacySupport(tasks.NewCollection(task))
+		viewOptions := getLegacyViewOptions(conf, c)
 		legacyui.NewRunner(task.Flatten(), viewOptions)
 	} else {
 		viewOptions := getViewOptions(c)
@@ -17,7 +17,7 @@ func CreateGraphCommand() *cli.Command {
 		ArgsUsage: "[run|watch|build|exec|launch] [profile or fragment]",
 		Flags:     CreateGlobalFlags(),
 		Action: func(c *cli.Context) error {
-			conf := loadConfig()
+			conf := loadConfig(c)
 			return graphTaskTree(conf, c, "")
 		},
 		BashComplete: func(c *cli.Context) {
@@ -32,7 +32,7 @@ func CreateGraphCommand() *cli.Command {
 				return
 			}
 
-			conf := loadConfig()
+			conf := loadConfig(c)
 			if c.Args().First() == "exec" {
 				completeFragments(conf)
 				return
@@ -51,7 +51,7 @@ func graphTaskTree(conf config.Config, c *cli.Context, defaultMode string) error
 		target = c.Args().First()
 	}
 
-	ctx := config.NewContext(getLoadOptions(c, []string{}))
+	ctx := config.NewContext(getLoadOptions(conf, c, []string{}))
 	var forest tasks.Collection
 	var err error
 
@@ -73,6 +73,7 @@ func graphTaskTree(conf config.Config, c *cli.Context, defaultMode string) error
 	if err != nil {
 		ui.HandleError(err)
 	}
+	forest = filterAffected(conf, c, forest)
 	for _, tree := range forest {
 		tree.RemoveEmptyNodes()
 	}
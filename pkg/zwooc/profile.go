@@ -15,14 +15,14 @@ func CreateProfileCommand(mode, usage string) *cli.Command {
 		ArgsUsage: "[profile] [extra arguments...]",
 		Flags:     CreateGlobalFlags(),
 		Action: func(c *cli.Context) error {
-			conf := loadConfig()
+			conf := loadConfig(c)
 			return execProfile(conf, mode, c)
 		},
 		BashComplete: func(c *cli.Context) {
 			if c.NArg() > 0 {
 				return
 			}
-			conf := loadConfig()
+			conf := loadConfig(c)
 			completeProfiles(conf)
 		},
 	}
@@ -33,20 +33,22 @@ func execProfile(conf config.Config, runMode string, c *cli.Context) error {
 		return graphTaskTree(conf, c, runMode)
 	}
 
-	runnerOptions := getRunnerOptions(c)
-	ctx := config.NewContext(getLoadOptions(c, c.Args().Tail()))
+	runnerOptions := getRunnerOptions(conf, c)
+	ctx := config.NewContext(getLoadOptions(conf, c, c.Args().Tail()))
 	profileKey := c.Args().First()
 	allTasks, err := conf.LoadProfile(profileKey, runMode, ctx)
 	if err != nil {
 		ui.HandleError(err)
 	}
+	allTasks = filterAffected(conf, c, allTasks)
 
 	for _, task := range allTasks {
 		task.RemoveEmptyNodes()
 	}
 
 	if runnerOptions.UseLegacyRunner {
-		viewOptions := getLegacyViewOptions(c)
+		ensureLegacySupport(allTasks)
+		viewOptions := getLegacyViewOptions(conf, c)
 		if runMode == model.ModeWatch || runMode == model.ModeRun || len(allTasks) > 1 {
 			legacyui.NewInteractiveRunner(allTasks, viewOptions, conf)
 		} else {
@@ -58,6 +60,7 @@ func execProfile(conf config.Config, runMode string, c *cli.Context) error {
 	viewOptions := getViewOptions(c)
 	if runMode == model.ModeWatch || runMode == model.ModeRun || len(allTasks) > 1 {
 		adapter := newStatusAdapter(allTasks, runnerOptions)
+		adapter.enableScheduling(conf, getLoadOptions(conf, c, []string{}))
 		ui.NewInteractiveView(allTasks, adapter.scheduler, viewOptions)
 	} else {
 		adapter := newStatusAdapter(allTasks, runnerOptions)
@@ -0,0 +1,41 @@
+package zwooc
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/urfave/cli/v2"
+	"github.com/zwoo-hq/zwooc/pkg/config"
+	"github.com/zwoo-hq/zwooc/pkg/ui"
+)
+
+func CreateSchemaCommand() *cli.Command {
+	return &cli.Command{
+		Name:  "schema",
+		Usage: "generate the json schema of the config for editor integration",
+		Flags: []cli.Flag{
+			&cli.StringFlag{
+				Name:    "output",
+				Aliases: []string{"o"},
+				Usage:   "write the schema to a file instead of stdout",
+			},
+		},
+		Action: func(c *cli.Context) error {
+			schema, err := config.Schema()
+			if err != nil {
+				ui.HandleError(err)
+			}
+
+			output := c.String("output")
+			if output == "" {
+				os.Stdout.Write(schema)
+				return nil
+			}
+			if err := os.WriteFile(output, schema, 0644); err != nil {
+				ui.HandleError(err)
+			}
+			ui.PrintSuccess(fmt.Sprintf("wrote the schema to %s", output))
+			return nil
+		},
+	}
+}
@@ -1,13 +1,18 @@
 package zwooc
 
 import (
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
 	"sync"
 
 	"github.com/zwoo-hq/zwooc/pkg/config"
+	"github.com/zwoo-hq/zwooc/pkg/model"
 	"github.com/zwoo-hq/zwooc/pkg/runner"
 	"github.com/zwoo-hq/zwooc/pkg/tasks"
 	"github.com/zwoo-hq/zwooc/pkg/ui"
-	"golang.org/x/sync/errgroup"
+	"golang.org/x/exp/maps"
 )
 
 type statusAdapter struct {
@@ -18,10 +23,25 @@ type statusAdapter struct {
 	tasks               tasks.Collection
 	runners             []*runner.TaskTreeRunner
 	concurrencyProvider runner.ConcurrencyProvider
+	// executions deduplicates shared dependencies of the runners of tasks that were added together
+	executions *runner.SharedExecutions
+
+	// conf is the config new tasks are loaded from, scheduling is disabled without a config
+	conf        *config.Config
+	loadOptions config.LoadOptions
 
 	isStarted bool
+	// isKeptOpen keeps the session open after runners failed, so that they can be rerun
+	isKeptOpen bool
+	// isClosing indicates that the session ends once all runners finished
+	isClosing bool
+	isDone    bool
 	updates   sync.WaitGroup
-	errs      errgroup.Group
+	// running is the amount of started runners that did not finish yet
+	running int
+	// errs contains the result of the latest run of each runner by the id of its root node
+	errs map[string]error
+	mu   sync.Mutex
 }
 
 func newStatusAdapter(forest tasks.Collection, options config.RunnerOptions) *statusAdapter {
@@ -33,85 +53,353 @@ func newStatusAdapter(forest tasks.Collection, options config.RunnerOptions) *st
 		concurrencyProvider: concurrencyProvider,
 		tasks:               tasks.NewCollection(),
 		runners:             []*runner.TaskTreeRunner{},
+		executions:          runner.NewSharedExecutions(),
+		errs:                map[string]error{},
 	}
 
 	// map scheduler events to adapter
 	scheduler.OnStart(adapter.start)
 	scheduler.OnCancel(adapter.cancel)
 	scheduler.OnShutdown(adapter.shutdownGracefully)
+	scheduler.OnKeepOpen(adapter.keepOpen)
 	scheduler.OnSchedule(adapter.schedule)
+	scheduler.OnSuggestions(adapter.suggestions)
+	scheduler.OnRestartTask(adapter.restartTask)
+	scheduler.OnStopTask(adapter.stopTask)
+	scheduler.OnStartTask(adapter.startTask)
+	scheduler.OnRerunTask(adapter.rerunTask)
+	scheduler.OnWriteInput(adapter.writeInput)
 
 	// schedule initial tasks
+	adapter.mu.Lock()
 	for _, node := range forest {
-		adapter.addTask(node)
+		adapter.addTaskLocked(node)
 	}
-
-	go func() {
-		// collect ends of updates
-		adapter.updates.Wait()
-		scheduler.CloseUpdates()
-	}()
-
+	adapter.mu.Unlock()
 	return adapter
 }
 
-func (a *statusAdapter) addTask(node *tasks.TaskTreeNode) {
+// enableScheduling allows scheduling new tasks from the config while running.
+func (a *statusAdapter) enableScheduling(conf config.Config, options config.LoadOptions) {
+	a.conf = &conf
+	a.loadOptions = options
+}
+
+// addTaskLocked creates a runner for the task tree, the caller must hold the mutex.
+func (a *statusAdapter) addTaskLocked(node *tasks.TaskTreeNode) {
 	// create a new runner
-	runner := runner.NewTreeRunner(node, a.concurrencyProvider)
-	a.runners = append(a.runners, runner)
-	a.tasks = append(a.tasks, node)
+	runner := runner.NewTreeRunner(node, a.concurrencyProvider, runner.RunnerConfig{
+		MaxConcurrency: a.options.MaxConcurrency,
+		Loose:          a.options.Loose,
+		Force:          a.options.Force,
+		Executions:     a.executions,
+	})
+
+	idx := slices.IndexFunc(a.tasks, func(t *tasks.TaskTreeNode) bool {
+		return t.NodeID() == node.NodeID()
+	})
+	if idx >= 0 {
+		// replace the finished runner of the same task
+		a.runners[idx] = runner
+		a.tasks[idx] = node
+	} else {
+		a.runners = append(a.runners, runner)
+		a.tasks = append(a.tasks, node)
+	}
 
 	if a.isStarted {
 		// manually start the runner if the scheduler already started
-		a.errs.Go(func() error {
-			return runner.Start()
-			// TODO: remove from runners
-		})
+		a.runLocked(runner)
 	}
+	a.collectUpdates(runner)
+}
 
-	// collect runner updates
+// collectUpdates forwards the updates of the runner to the scheduler, the caller must hold the mutex.
+// Updates are collected until the session is done, thus the updates of the scheduler are closed afterwards.
+func (a *statusAdapter) collectUpdates(r *runner.TaskTreeRunner) {
 	a.updates.Add(1)
 	go func() {
-		for update := range runner.Updates() {
+		for update := range r.Updates() {
 			a.scheduler.UpdateStatus(runnerToStatusProvider(update))
 		}
 		a.updates.Done()
 	}()
+}
 
+// runLocked starts the runner, once all runners finished the scheduler is notified.
+// The caller must hold the mutex.
+func (a *statusAdapter) runLocked(r *runner.TaskTreeRunner) {
+	a.running++
+	go func() {
+		err := r.Start()
+		a.mu.Lock()
+		a.errs[r.Status().ID] = err
+		a.running--
+		a.mu.Unlock()
+		a.finish()
+	}()
+}
+
+// finish notifies the scheduler once all runners finished. If the session is kept open,
+// failed runners don't end the session until it's shut down or canceled.
+func (a *statusAdapter) finish() {
+	a.mu.Lock()
+	combinedErr := combineErrors(maps.Values(a.errs))
+	isFailed := combinedErr != nil && !errors.Is(combinedErr, tasks.ErrCancelled)
+	isDone := a.isStarted && !a.isDone && a.running == 0 && (a.isClosing || !a.isKeptOpen || !isFailed)
+	if isDone {
+		a.isDone = true
+	}
+	a.mu.Unlock()
+
+	if isDone {
+		go func() {
+			// no runners are added anymore, thus the updates can be closed once all were forwarded
+			a.updates.Wait()
+			a.scheduler.CloseUpdates()
+		}()
+		a.scheduler.Done(combinedErr)
+	}
+}
+
+func (a *statusAdapter) keepOpen() {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.isKeptOpen = true
 }
 
 func (a *statusAdapter) start() {
+	a.mu.Lock()
+	defer a.mu.Unlock()
 	a.isStarted = true
 	// start all known runners
 	for _, r := range a.runners {
-		currentRunner := r
-		a.errs.Go(func() error {
-			return currentRunner.Start()
-		})
+		a.runLocked(r)
 	}
+}
 
-	// collect done
-	go func() {
-		err := a.errs.Wait()
-		a.scheduler.Done(err)
-	}()
+// closeRunners marks the session as closing and returns the current runners.
+// The runners are controlled without holding the mutex, since finishing runners acquire it.
+func (a *statusAdapter) closeRunners() []*runner.TaskTreeRunner {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.isClosing = true
+	return slices.Clone(a.runners)
 }
 
 func (a *statusAdapter) cancel() {
-	for _, r := range a.runners {
+	for _, r := range a.closeRunners() {
 		r.Cancel()
 	}
+	// the session may be idle after runners failed
+	a.finish()
 }
 
 func (a *statusAdapter) shutdownGracefully() {
-	for _, r := range a.runners {
+	for _, r := range a.closeRunners() {
 		r.ShutdownGracefully()
 	}
+	// the session may be idle after runners failed
+	a.finish()
+}
+
+// schedule loads the tasks of a command like 'run <profile>', 'exec <fragment>' or 'launch <compound>'
+// and runs them alongside the current tasks.
+func (a *statusAdapter) schedule(command string) error {
+	if a.conf == nil {
+		return errors.New("scheduling tasks is not supported")
+	}
+
+	nodes, err := a.loadTasks(command)
+	if err != nil {
+		return err
+	}
+
+	// the runners are added while holding the mutex, so that the session can't finish in between
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if err := a.checkOpenLocked(); err != nil {
+		return err
+	}
+
+	for _, node := range nodes {
+		node.RemoveEmptyNodes()
+		for _, r := range a.runners {
+			if r.Status().ID == node.NodeID() && !r.IsDone() {
+				return fmt.Errorf("'%s' is already running", node.NodeID())
+			}
+		}
+	}
+
+	// let the view set up the new tasks before they are started
+	a.scheduler.TasksAdded(nodes)
+	// scheduled tasks are executed again instead of reusing the results of earlier executions
+	a.executions = runner.NewSharedExecutions()
+	for _, node := range nodes {
+		a.addTaskLocked(node)
+	}
+	return nil
+}
+
+// checkOpenLocked returns an error if no runners can be started anymore, the caller must hold the mutex.
+func (a *statusAdapter) checkOpenLocked() error {
+	if a.isDone {
+		return errors.New("all tasks already finished")
+	}
+	if a.isClosing {
+		return errors.New("the runner is shutting down")
+	}
+	return nil
 }
 
-func (a *statusAdapter) schedule(command string, id string) {
-	//TODO: implement scheduling
-	// - check whether task is already scheduled (dont schedule twice)
+func (a *statusAdapter) loadTasks(command string) (tasks.Collection, error) {
+	kind, key, _ := strings.Cut(strings.TrimSpace(command), " ")
+	key = strings.TrimSpace(key)
+	ctx := config.NewContext(a.loadOptions)
+
+	switch kind {
+	case model.ModeRun, model.ModeWatch, model.ModeBuild:
+		return a.conf.LoadProfile(key, kind, ctx)
+	case "exec":
+		node, err := a.conf.LoadFragment(key, ctx)
+		if err != nil {
+			return nil, err
+		}
+		return tasks.NewCollection(node), nil
+	case "launch":
+		return a.conf.LoadCompound(key, ctx)
+	}
+	return nil, fmt.Errorf("unknown command '%s': expected one of run, watch, build, exec or launch", kind)
+}
+
+// suggestions returns all commands that can be scheduled.
+func (a *statusAdapter) suggestions() []string {
+	if a.conf == nil {
+		return []string{}
+	}
+
+	suggestions := []string{}
+	for _, profile := range a.conf.GetProfiles() {
+		for _, mode := range profile.Modes() {
+			suggestions = append(suggestions, fmt.Sprintf("%s %s", mode, profile.Name()))
+		}
+	}
+	for _, fragment := range a.conf.GetFragments() {
+		suggestions = append(suggestions, "exec "+fragment.Name())
+	}
+	for _, compound := range a.conf.GetCompounds() {
+		suggestions = append(suggestions, "launch "+compound.Name())
+	}
+	slices.Sort(suggestions)
+	return slices.Compact(suggestions)
+}
+
+func (a *statusAdapter) restartTask(id string) error {
+	return a.forRunner(id, func(r *runner.TaskTreeRunner) error {
+		return r.RestartNode(id)
+	})
+}
+
+func (a *statusAdapter) stopTask(id string) error {
+	return a.forRunner(id, func(r *runner.TaskTreeRunner) error {
+		return r.StopNode(id)
+	})
+}
+
+func (a *statusAdapter) startTask(id string) error {
+	return a.forRunner(id, func(r *runner.TaskTreeRunner) error {
+		return r.StartNode(id)
+	})
+}
+
+func (a *statusAdapter) writeInput(id string, data []byte) error {
+	return a.forRunner(id, func(r *runner.TaskTreeRunner) error {
+		return r.WriteInput(id, data)
+	})
+}
+
+// rerunTask replaces the finished runner of the tree containing the node with the id by a new runner
+// that executes all unsuccessful subtrees again.
+func (a *statusAdapter) rerunTask(id string) error {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if err := a.checkOpenLocked(); err != nil {
+		return err
+	}
+
+	result := runner.ErrNodeNotFound
+	for i, r := range a.runners {
+		if !containsNode(a.tasks[i], id) {
+			continue
+		}
+		if !r.IsDone() {
+			// shared nodes are part of several trees, one of them may have finished already
+			result = runner.ErrRunnerNotDone
+			continue
+		}
+		rerun, err := r.Rerun()
+		if err != nil {
+			return err
+		}
+		a.runners[i] = rerun
+		a.collectUpdates(rerun)
+		a.runLocked(rerun)
+		return nil
+	}
+	return result
+}
+
+// containsNode reports whether the tree contains the node with the id.
+func containsNode(tree *tasks.TaskTreeNode, id string) bool {
+	found := false
+	tree.Iterate(func(node *tasks.TaskTreeNode) {
+		found = found || node.NodeID() == id
+	})
+	return found
+}
+
+// forRunner calls the handler with the runners until one of them controls the node with the id.
+// Shared nodes are part of several runners, but only the runner executing them can control them.
+func (a *statusAdapter) forRunner(id string, handler func(r *runner.TaskTreeRunner) error) error {
+	a.mu.Lock()
+	// rerunning a task replaces its runner
+	runners := slices.Clone(a.runners)
+	a.mu.Unlock()
+
+	result := runner.ErrNodeNotFound
+	for _, r := range runners {
+		err := handler(r)
+		if errors.Is(err, runner.ErrNodeNotFound) {
+			continue
+		}
+		if errors.Is(err, runner.ErrNodeNotRunning) || errors.Is(err, runner.ErrNodeNotStopped) {
+			result = err
+			continue
+		}
+		return err
+	}
+	return result
+}
+
+// combineErrors combines the results of multiple runners into a single error.
+func combineErrors(errs []error) error {
+	combined := tasks.NewMultiTaskError(map[string]error{})
+	var other error
+	for _, err := range errs {
+		var multiErr *tasks.MultiTaskError
+		if errors.As(err, &multiErr) {
+			maps.Copy(combined.Errors, multiErr.Errors)
+			combined.Skipped = append(combined.Skipped, multiErr.Skipped...)
+			combined.NotStarted = append(combined.NotStarted, multiErr.NotStarted...)
+		} else if err != nil && other == nil {
+			other = err
+		}
+	}
+
+	if len(combined.Errors) > 0 {
+		return combined
+	}
+	return other
 }
 
 func runnerToStatusProvider(updatedNode *runner.TreeStatusNode) ui.StatusUpdate {
@@ -120,6 +408,7 @@ func runnerToStatusProvider(updatedNode *runner.TreeStatusNode) ui.StatusUpdate
 		Status:           runnerStatusToUi(updatedNode.Status),
 		AggregatedStatus: runnerStatusToUi(updatedNode.AggregatedStatus),
 		Error:            updatedNode.Error,
+		Restarts:         updatedNode.Restarts,
 	}
 
 	if updatedNode.Parent != nil {
@@ -143,6 +432,10 @@ func runnerStatusToUi(status runner.TaskStatus) ui.TaskStatus {
 		return ui.StatusError
 	case runner.StatusCanceled:
 		return ui.StatusCanceled
+	case runner.StatusSkipped:
+		return ui.StatusSkipped
+	case runner.StatusCached:
+		return ui.StatusCached
 	default:
 		return ui.StatusPending
 	}
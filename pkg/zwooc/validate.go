@@ -0,0 +1,42 @@
+package zwooc
+
+import (
+	"fmt"
+
+	"github.com/urfave/cli/v2"
+	"github.com/zwoo-hq/zwooc/pkg/config"
+	"github.com/zwoo-hq/zwooc/pkg/ui"
+)
+
+func CreateValidateCommand() *cli.Command {
+	return &cli.Command{
+		Name:  "validate",
+		Usage: "check the config for errors (done before every run too)",
+		Action: func(c *cli.Context) error {
+			diagnostics, err := config.Validate(findConfig())
+			if err != nil {
+				ui.HandleError(err)
+			}
+
+			errors, warnings := 0, 0
+			for _, diagnostic := range diagnostics {
+				fmt.Println(diagnostic)
+				if diagnostic.Severity == config.SeverityError {
+					errors++
+				} else {
+					warnings++
+				}
+			}
+
+			if errors > 0 {
+				ui.HandleError(fmt.Errorf("the config contains %d error(s) and %d warning(s)", errors, warnings))
+			}
+			if warnings > 0 {
+				ui.PrintSuccess(fmt.Sprintf("the config is valid, but contains %d warning(s)", warnings))
+				return nil
+			}
+			ui.PrintSuccess("the config is valid")
+			return nil
+		},
+	}
+}